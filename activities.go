@@ -11,27 +11,28 @@ import (
 
 type ActivityID string
 type Activity struct {
-	ID             ActivityID   `bson:"_id" json:"_id,omitempty"`
-	UserID         UserID       `bson:"userId,omitempty" json:"userId,omitempty"`
-	Username       Username     `bson:"username,omitempty" json:"username,omitempty"`
-	Type           string       `bson:"type,omitempty" json:"type,omitempty"`
-	AssigneeID     UserID       `bson:"assigneeId,omitempty" json:"assigneeId,omitempty"`
-	MemberID       UserID       `bson:"memberId,omitempty" json:"memberId,omitempty"`
-	ActivityType   string       `bson:"activityType,omitempty" json:"activityType,omitempty"`
-	ActivityTypeID string       `bson:"activityTypeId,omitempty" json:"activityTypeId,omitempty"`
-	BoardID        BoardID      `bson:"boardId,omitempty" json:"boardId,omitempty"`
-	BoardLabelID   BoardLabelID `bson:"labelId,omitEmpty" json:"labelId,omitempty"`
-	CardTitle      string       `bson:"cardTitle,omitempty" json:"cardTitle,omitempty"`
-	ListID         ListID       `bson:"listId,omitempty" json:"listId,omitempty"`
-	OldListID      ListID       `bson:"oldListId,omitempty" json:"oldListId,omitempty"`
-	ListName       string       `bson:"listName,omitempty" json:"listName,omitempty"`
-	CardID         CardID       `bson:"cardId,omitempty" json:"cardId,omitempty"`
-	CommentID      CommentID    `bson:"commentId, omitempty"`
-	SwimlaneID     SwimlaneID   `bson:"swimlaneId,omitempty" json:"swimlaneId,omitempty"`
-	OldSwimlaneID  SwimlaneID   `bson:"oldSwimlaneId,omitempty" json:"oldSwimlaneId,omitempty"`
-	SwimlaneName   string       `bson:"swimlaneName,omitempty" json:"swimlaneName,omitempty"`
-	CreatedAt      time.Time    `bson:"createdAt" json:"createdAt,omitempty"`
-	ModifiedAt     time.Time    `bson:"modifiedAt" json:"modifiedAt,omitempty"`
+	ID             ActivityID        `bson:"_id" json:"_id,omitempty"`
+	UserID         UserID            `bson:"userId,omitempty" json:"userId,omitempty"`
+	Username       Username          `bson:"username,omitempty" json:"username,omitempty"`
+	Type           string            `bson:"type,omitempty" json:"type,omitempty"`
+	AssigneeID     UserID            `bson:"assigneeId,omitempty" json:"assigneeId,omitempty"`
+	MemberID       UserID            `bson:"memberId,omitempty" json:"memberId,omitempty"`
+	ActivityType   string            `bson:"activityType,omitempty" json:"activityType,omitempty"`
+	ActivityTypeID string            `bson:"activityTypeId,omitempty" json:"activityTypeId,omitempty"`
+	BoardID        BoardID           `bson:"boardId,omitempty" json:"boardId,omitempty"`
+	BoardLabelID   BoardLabelID      `bson:"labelId,omitEmpty" json:"labelId,omitempty"`
+	CardTitle      string            `bson:"cardTitle,omitempty" json:"cardTitle,omitempty"`
+	ListID         ListID            `bson:"listId,omitempty" json:"listId,omitempty"`
+	OldListID      ListID            `bson:"oldListId,omitempty" json:"oldListId,omitempty"`
+	ListName       string            `bson:"listName,omitempty" json:"listName,omitempty"`
+	CardID         CardID            `bson:"cardId,omitempty" json:"cardId,omitempty"`
+	CommentID      CommentID         `bson:"commentId, omitempty"`
+	SwimlaneID     SwimlaneID        `bson:"swimlaneId,omitempty" json:"swimlaneId,omitempty"`
+	OldSwimlaneID  SwimlaneID        `bson:"oldSwimlaneId,omitempty" json:"oldSwimlaneId,omitempty"`
+	SwimlaneName   string            `bson:"swimlaneName,omitempty" json:"swimlaneName,omitempty"`
+	CustomFieldID  CardCustomFieldID `bson:"customFieldId,omitempty" json:"customFieldId,omitempty"`
+	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt,omitempty"`
+	ModifiedAt     time.Time         `bson:"modifiedAt" json:"modifiedAt,omitempty"`
 }
 
 func (activityID ActivityID) Check(ctx context.Context, wekan *Wekan) error {
@@ -63,6 +64,17 @@ func newActivityCreateBoard(userID UserID, boardID BoardID) Activity {
 	}
 }
 
+// newActivityUpdateBoard trace l'archivage (archivedBoard) ou la restauration (restoredBoard) d'une board
+func newActivityUpdateBoard(userID UserID, boardID BoardID, activityType string) Activity {
+	return Activity{
+		BoardID:        boardID,
+		ActivityTypeID: string(boardID),
+		UserID:         userID,
+		ActivityType:   activityType,
+		Type:           "board",
+	}
+}
+
 func newActivityCreateSwimlane(userID UserID, boardID BoardID, swimlaneID SwimlaneID) Activity {
 	return Activity{
 		SwimlaneID:   swimlaneID,
@@ -73,6 +85,25 @@ func newActivityCreateSwimlane(userID UserID, boardID BoardID, swimlaneID Swimla
 	}
 }
 
+func newActivityCreateList(userID UserID, boardID BoardID, listID ListID) Activity {
+	return Activity{
+		ListID:       listID,
+		UserID:       userID,
+		BoardID:      boardID,
+		ActivityType: "createList",
+		Type:         "list",
+	}
+}
+
+func newActivityCreateCustomField(userID UserID, boardID BoardID, customFieldID CardCustomFieldID) Activity {
+	return Activity{
+		CustomFieldID: customFieldID,
+		UserID:        userID,
+		BoardID:       boardID,
+		ActivityType:  "createCustomField",
+	}
+}
+
 func newActivityAddBoardMember(userID UserID, memberID UserID, boardID BoardID) Activity {
 	return Activity{
 		UserID:       userID,
@@ -93,6 +124,17 @@ func newActivityRemoveBoardMember(userID UserID, memberID UserID, boardID BoardI
 	}
 }
 
+// newActivityChangeBoardMemberRole trace la modification du rôle d'un membre de la board
+func newActivityChangeBoardMemberRole(userID UserID, memberID UserID, boardID BoardID) Activity {
+	return Activity{
+		UserID:       userID,
+		MemberID:     memberID,
+		BoardID:      boardID,
+		ActivityType: "changedBoardMemberRole",
+		Type:         "member",
+	}
+}
+
 func newActivityCardJoinMember(userID UserID, username Username, memberID UserID, boardID BoardID, listID ListID, cardID CardID, swimlaneID SwimlaneID) Activity {
 	return Activity{
 		UserID:       userID,
@@ -167,6 +209,18 @@ func newActivityAddedLabel(userID UserID, boardLabelID BoardLabelID, boardID Boa
 	}
 }
 
+func newActivityRemovedLabel(userID UserID, boardLabelID BoardLabelID, card Card) Activity {
+	return Activity{
+		UserID:       userID,
+		BoardLabelID: boardLabelID,
+		ActivityType: "removedLabel",
+		BoardID:      card.BoardID,
+		CardID:       card.ID,
+		ListID:       card.ListID,
+		SwimlaneID:   card.SwimlaneID,
+	}
+}
+
 func (wekan *Wekan) newActivityCreateCardFromCard(ctx context.Context, card Card) (Activity, error) {
 	list, err := wekan.GetListFromID(ctx, card.ListID)
 	if err != nil {
@@ -237,7 +291,9 @@ func (wekan *Wekan) insertActivity(ctx context.Context, activity Activity) (Acti
 	return insertable, nil
 }
 
-func (wekan *Wekan) SelectActivitiesFromCardID(ctx context.Context, cardID CardID) ([]Activity, error) {
+func (wekan *Wekan) SelectActivitiesFromCardID(ctx context.Context, cardID CardID) (_ []Activity, err error) {
+	ctx, end := wekan.observe(ctx, "SelectActivitiesFromCardID", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	var activities []Activity
 	filter := bson.M{"cardId": cardID}
 	sort := options.Find().SetSort(bson.M{"createdAt": 1})
@@ -252,9 +308,11 @@ func (wekan *Wekan) SelectActivitiesFromCardID(ctx context.Context, cardID CardI
 	return activities, nil
 }
 
-func (wekan *Wekan) GetActivityFromID(ctx context.Context, activityID ActivityID) (Activity, error) {
+func (wekan *Wekan) GetActivityFromID(ctx context.Context, activityID ActivityID) (_ Activity, err error) {
+	ctx, end := wekan.observe(ctx, "GetActivityFromID", Targets{"activityID": string(activityID)})
+	defer end(&err)
 	var activity Activity
-	err := wekan.db.Collection("activities").FindOne(ctx, bson.M{"_id": activityID}).Decode(&activity)
+	err = wekan.db.Collection("activities").FindOne(ctx, bson.M{"_id": activityID}).Decode(&activity)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return Activity{}, ActivityNotFoundError{string(activityID)}
@@ -264,7 +322,9 @@ func (wekan *Wekan) GetActivityFromID(ctx context.Context, activityID ActivityID
 	return activity, nil
 }
 
-func (wekan *Wekan) SelectActivitiesFromQuery(ctx context.Context, query bson.M) ([]Activity, error) {
+func (wekan *Wekan) SelectActivitiesFromQuery(ctx context.Context, query bson.M) (_ []Activity, err error) {
+	ctx, end := wekan.observe(ctx, "SelectActivitiesFromQuery", nil)
+	defer end(&err)
 	var activities []Activity
 	cur, err := wekan.db.Collection("activities").Find(ctx, query)
 	if err != nil {
@@ -276,6 +336,8 @@ func (wekan *Wekan) SelectActivitiesFromQuery(ctx context.Context, query bson.M)
 	return activities, nil
 }
 
-func (wekan *Wekan) SelectActivitiesFromBoardID(ctx context.Context, boardID BoardID) ([]Activity, error) {
+func (wekan *Wekan) SelectActivitiesFromBoardID(ctx context.Context, boardID BoardID) (_ []Activity, err error) {
+	ctx, end := wekan.observe(ctx, "SelectActivitiesFromBoardID", Targets{"boardID": string(boardID)})
+	defer end(&err)
 	return wekan.SelectActivitiesFromQuery(ctx, bson.M{"boardId": boardID})
 }
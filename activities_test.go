@@ -127,6 +127,22 @@ func TestActivities_newActivityAddedLabel(t *testing.T) {
 	ass.Equal(expected, activity)
 }
 
+func TestActivities_newActivityRemovedLabel(t *testing.T) {
+	ass := assert.New(t)
+	expected := Activity{
+		UserID:       "userID",
+		BoardLabelID: "boardLabelID",
+		ActivityType: "removedLabel",
+		BoardID:      "card.BoardID",
+		CardID:       "card.ID",
+		ListID:       "card.ListID",
+		SwimlaneID:   "card.SwimlaneID",
+	}
+	card := Card{ID: "card.ID", BoardID: "card.BoardID", ListID: "card.ListID", SwimlaneID: "card.SwimlaneID"}
+	activity := newActivityRemovedLabel("userID", "boardLabelID", card)
+	ass.Equal(expected, activity)
+}
+
 func TestActivities_newActivityCreateCard(t *testing.T) {
 	expected := Activity{
 		UserID:       "userID",
@@ -0,0 +1,296 @@
+package libwekan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CloneBoardOptions précise les éléments facultatifs copiés par CloneBoard
+type CloneBoardOptions struct {
+	// Members copie les membres de la board source avec leurs rôles, à défaut seul l'acteur courant est administrateur de la copie
+	Members bool
+	// Cards copie les cartes non archivées, sans leurs commentaires ; leurs membres ne sont conservés qu'avec Members
+	Cards bool
+}
+
+// BoardClone est le résultat de CloneBoard : la nouvelle board et la correspondance entre identifiants source et copiés.
+// SkippedRules liste les règles source non copiées car leur déclencheur désigne une étiquette absente de la board source.
+type BoardClone struct {
+	Board        Board
+	Swimlanes    map[SwimlaneID]SwimlaneID
+	Lists        map[ListID]ListID
+	Labels       map[BoardLabelID]BoardLabelID
+	Rules        map[RuleID]RuleID
+	SkippedRules []RuleID
+	Cards        map[CardID]CardID
+}
+
+// CloneBoard copie la structure de la board sourceID (swimlanes, listes, étiquettes, champs personnalisés, règles)
+// dans une nouvelle board, en générant de nouveaux identifiants reportés dans toutes les références.
+// Les champs personnalisés ne sont pas dupliqués mais rattachés à la nouvelle board.
+// Le slug de la copie doit appartenir au domaine.
+func (wekan *Wekan) CloneBoard(ctx context.Context, sourceID BoardID, title BoardTitle, slug BoardSlug, options CloneBoardOptions) (_ BoardClone, err error) {
+	ctx, end := wekan.observe(ctx, "CloneBoard", Targets{"boardID": string(sourceID), "slug": string(slug)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return BoardClone{}, err
+	}
+	if title == "" {
+		return BoardClone{}, InvalidValueError{"title", string(title)}
+	}
+	if slug == "" {
+		return BoardClone{}, InvalidValueError{"slug", string(slug)}
+	}
+	if !wekan.inDomain(slug) {
+		return BoardClone{}, ForbiddenOperationError{fmt.Errorf("le slug %s n'appartient pas au domaine", slug)}
+	}
+	source, err := wekan.GetBoardFromID(ctx, sourceID)
+	if err != nil {
+		return BoardClone{}, err
+	}
+
+	var clone BoardClone
+	err = wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		// vérifié dans la transaction pour qu'une copie concurrente vers le même slug entre en conflit
+		if _, err := wekan.GetBoardFromSlug(ctx, slug); err == nil {
+			return BoardAlreadyExistsError{slug}
+		} else if !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		var err error
+		clone, err = wekan.cloneBoard(ctx, source, title, slug, options)
+		return err
+	})
+	if err != nil {
+		return BoardClone{}, err
+	}
+	return clone, nil
+}
+
+func (wekan *Wekan) cloneBoard(ctx context.Context, source Board, title BoardTitle, slug BoardSlug, options CloneBoardOptions) (BoardClone, error) {
+	clone := BoardClone{
+		Swimlanes: make(map[SwimlaneID]SwimlaneID),
+		Lists:     make(map[ListID]ListID),
+		Labels:    make(map[BoardLabelID]BoardLabelID),
+		Rules:     make(map[RuleID]RuleID),
+		Cards:     make(map[CardID]CardID),
+	}
+
+	board := source
+	board.ID = BoardID(newId())
+	board.Title = title
+	board.Slug = slug
+	board.Archived = false
+	board.Stars = 0
+	board.Watchers = nil
+	board.CreatedAt = toMongoTime(time.Now())
+	board.ModifiedAt = toMongoTime(time.Now())
+	// ces réglages désignent des objets de la board source
+	board.SubtasksDefaultBoardId = nil
+	board.SubtasksDefaultListId = nil
+	board.DateSettingsDefaultBoardId = nil
+	board.DateSettingsDefaultListId = nil
+	board.Labels = make([]BoardLabel, 0, len(source.Labels))
+	for _, label := range source.Labels {
+		clonedLabel := label
+		clonedLabel.ID = BoardLabelID(newId6())
+		clone.Labels[label.ID] = clonedLabel.ID
+		board.Labels = append(board.Labels, clonedLabel)
+	}
+	// les membres sont ajoutés après l'insertion pour produire les activités correspondantes
+	board.Members = []BoardMember{}
+	if err := wekan.InsertBoard(ctx, board); err != nil {
+		return BoardClone{}, err
+	}
+	members := []BoardMember{{UserID: wekan.ActorID(), IsAdmin: true, IsActive: true}}
+	if options.Members {
+		members = source.Members
+	}
+	for _, member := range members {
+		if err := wekan.AddMemberToBoard(ctx, board.ID, member); err != nil {
+			return BoardClone{}, err
+		}
+	}
+
+	if err := wekan.cloneSwimlanesAndLists(ctx, source.ID, board.ID, &clone); err != nil {
+		return BoardClone{}, err
+	}
+	if err := wekan.attachCustomFields(ctx, source.ID, board.ID); err != nil {
+		return BoardClone{}, err
+	}
+	if err := wekan.cloneRules(ctx, source.ID, board.ID, &clone); err != nil {
+		return BoardClone{}, err
+	}
+	if options.Cards {
+		if err := wekan.cloneCards(ctx, source.ID, board.ID, options.Members, &clone); err != nil {
+			return BoardClone{}, err
+		}
+	}
+
+	board, err := wekan.GetBoardFromID(ctx, board.ID)
+	if err != nil {
+		return BoardClone{}, err
+	}
+	clone.Board = board
+	return clone, nil
+}
+
+func (wekan *Wekan) cloneSwimlanesAndLists(ctx context.Context, sourceID BoardID, boardID BoardID, clone *BoardClone) error {
+	swimlanes, err := wekan.GetSwimlanesFromBoardID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, swimlane := range swimlanes {
+		if swimlane.Archived {
+			continue
+		}
+		clonedSwimlane := swimlane
+		clonedSwimlane.ID = SwimlaneID(newId())
+		clonedSwimlane.BoardID = boardID
+		clonedSwimlane.CreatedAt = toMongoTime(time.Now())
+		clonedSwimlane.ModifiedAt = toMongoTime(time.Now())
+		if err := wekan.InsertSwimlane(ctx, clonedSwimlane); err != nil {
+			return err
+		}
+		clone.Swimlanes[swimlane.ID] = clonedSwimlane.ID
+	}
+
+	lists, err := wekan.SelectListsFromBoardID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, list := range lists {
+		if list.Archived {
+			continue
+		}
+		clonedList := list
+		clonedList.ID = ListID(newId())
+		clonedList.BoardID = boardID
+		clonedList.CreatedAt = toMongoTime(time.Now())
+		clonedList.ModifiedAt = toMongoTime(time.Now())
+		if list.SwimlaneID != "" {
+			swimlaneID, ok := clone.Swimlanes[SwimlaneID(list.SwimlaneID)]
+			// les listes propres à une swimlane archivée ne sont pas copiées avec elle
+			if !ok {
+				continue
+			}
+			clonedList.SwimlaneID = string(swimlaneID)
+		}
+		if err := wekan.InsertList(ctx, clonedList); err != nil {
+			return err
+		}
+		clone.Lists[list.ID] = clonedList.ID
+	}
+	return nil
+}
+
+// attachCustomFields ajoute boardID aux boards des champs personnalisés de sourceID
+func (wekan *Wekan) attachCustomFields(ctx context.Context, sourceID BoardID, boardID BoardID) error {
+	var customFields []CustomField
+	cur, err := wekan.db.Collection("customFields").Find(ctx, bson.M{"boardIds": sourceID})
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	if err := cur.All(ctx, &customFields); err != nil {
+		return UnexpectedMongoDecodeError{err}
+	}
+	for _, customField := range customFields {
+		_, err := wekan.db.Collection("customFields").UpdateOne(ctx,
+			bson.M{"_id": customField.ID},
+			bson.M{
+				"$addToSet": bson.M{"boardIds": boardID},
+				"$set":      bson.M{"modifiedAt": toMongoTime(time.Now())},
+			})
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+	}
+	return nil
+}
+
+func (wekan *Wekan) cloneRules(ctx context.Context, sourceID BoardID, boardID BoardID, clone *BoardClone) error {
+	rules, err := wekan.SelectRulesFromBoardID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		trigger := rule.Trigger
+		trigger.ID = TriggerID(newId())
+		trigger.BoardID = boardID
+		if trigger.LabelID != "" {
+			labelID, ok := clone.Labels[trigger.LabelID]
+			// l'étiquette n'existe plus dans la board source, la règle copiée désignerait une étiquette d'une autre board
+			if !ok {
+				clone.SkippedRules = append(clone.SkippedRules, rule.ID)
+				continue
+			}
+			trigger.LabelID = labelID
+		}
+		action := rule.Action
+		action.ID = ActionID(newId())
+		action.BoardID = boardID
+
+		clonedRule := rule
+		clonedRule.ID = RuleID(newId())
+		clonedRule.BoardID = boardID
+		clonedRule.Trigger = trigger
+		clonedRule.TriggerID = &trigger.ID
+		clonedRule.Action = action
+		clonedRule.ActionID = &action.ID
+		clonedRule.CreatedAt = toMongoTime(time.Now())
+		clonedRule.ModifiedAt = toMongoTime(time.Now())
+		if err := wekan.InsertRule(ctx, clonedRule); err != nil {
+			return err
+		}
+		clone.Rules[rule.ID] = clonedRule.ID
+	}
+	return nil
+}
+
+func (wekan *Wekan) cloneCards(ctx context.Context, sourceID BoardID, boardID BoardID, keepMembers bool, clone *BoardClone) error {
+	cards, err := wekan.SelectCardsFromBoardID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	var clonedCards []Card
+	for _, card := range cards {
+		listID, listOk := clone.Lists[card.ListID]
+		swimlaneID, swimlaneOk := clone.Swimlanes[card.SwimlaneID]
+		// les cartes des listes ou swimlanes archivées ne sont pas copiées avec elles
+		if card.Archived || !listOk || !swimlaneOk {
+			continue
+		}
+		clonedCard := card
+		clonedCard.ID = CardID(newId())
+		clonedCard.BoardID = boardID
+		clonedCard.ListID = listID
+		clonedCard.SwimlaneID = swimlaneID
+		clonedCard.LabelIDs = make([]BoardLabelID, 0, len(card.LabelIDs))
+		for _, labelID := range card.LabelIDs {
+			if clonedLabelID, ok := clone.Labels[labelID]; ok {
+				clonedCard.LabelIDs = append(clonedCard.LabelIDs, clonedLabelID)
+			}
+		}
+		if !keepMembers {
+			clonedCard.Members = []UserID{}
+			clonedCard.Assignees = []UserID{}
+		}
+		clonedCard.CreatedAt = toMongoTime(time.Now())
+		clonedCard.ModifiedAt = toMongoTime(time.Now())
+		clone.Cards[card.ID] = clonedCard.ID
+		clonedCards = append(clonedCards, clonedCard)
+	}
+	for _, card := range clonedCards {
+		// les liens vers des cartes non copiées sont supprimés
+		card.ParentID = clone.Cards[card.ParentID]
+		card.LinkedID = clone.Cards[card.LinkedID]
+		if err := wekan.InsertCard(ctx, card); err != nil {
+			return err
+		}
+	}
+	return nil
+}
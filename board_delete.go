@@ -0,0 +1,171 @@
+package libwekan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeleteBoardOptions précise le comportement de DeleteBoard
+type DeleteBoardOptions struct {
+	// ArchiveOnly place la board dans la corbeille de Wekan sans rien supprimer
+	ArchiveOnly bool
+	// Force autorise la suppression d'une board dont le slug n'appartient pas au domaine
+	Force bool
+}
+
+// BoardDeletion rend compte de DeleteBoard, Deleted donne le nombre de documents supprimés par collection
+type BoardDeletion struct {
+	Board    Board
+	Archived bool
+	Deleted  map[string]int
+}
+
+// DeleteBoard supprime la board et tout ce qui en dépend (swimlanes, listes, cartes, commentaires, checklists,
+// métadonnées des pièces jointes, règles, activités) dans une transaction.
+// Les champs personnalisés sont détachés de la board et supprimés s'ils ne sont rattachés à aucune autre.
+// Une board hors du domaine n'est supprimée qu'avec l'option Force.
+func (wekan *Wekan) DeleteBoard(ctx context.Context, boardID BoardID, opts DeleteBoardOptions) (_ BoardDeletion, err error) {
+	ctx, end := wekan.observe(ctx, "DeleteBoard", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return BoardDeletion{}, err
+	}
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return BoardDeletion{}, err
+	}
+	if !opts.Force && !wekan.inDomain(board.Slug) {
+		return BoardDeletion{}, ForbiddenOperationError{fmt.Errorf("le slug %s n'appartient pas au domaine", board.Slug)}
+	}
+	if opts.ArchiveOnly {
+		// une board déjà archivée est laissée en l'état
+		if err := wekan.ArchiveBoard(ctx, boardID); err != nil && !errors.Is(err, ErrNothingDone) {
+			return BoardDeletion{}, err
+		}
+		return BoardDeletion{Board: board, Archived: true, Deleted: map[string]int{}}, nil
+	}
+
+	deletion := BoardDeletion{Board: board, Deleted: make(map[string]int)}
+	err = wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		// la transaction peut être rejouée, le décompte repart de zéro
+		deletion.Deleted = make(map[string]int)
+		return wekan.deleteBoard(ctx, board, deletion.Deleted)
+	})
+	if err != nil {
+		return BoardDeletion{}, err
+	}
+	return deletion, nil
+}
+
+func (wekan *Wekan) deleteBoard(ctx context.Context, board Board, deleted map[string]int) error {
+	cardIDs, err := wekan.selectIDs(ctx, "cards", bson.M{"boardId": board.ID})
+	if err != nil {
+		return err
+	}
+	mapping := wekan.schemaMapping()
+	filters := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"activities", bson.M{"boardId": board.ID}},
+		{mapping.CommentsCollection, bson.M{"boardId": board.ID}},
+		{"checklistItems", bson.M{"cardId": bson.M{"$in": cardIDs}}},
+		{"checklists", bson.M{"cardId": bson.M{"$in": cardIDs}}},
+		{mapping.AttachmentsCollection, bson.M{mapping.AttachmentsBoardField: board.ID}},
+		{"cards", bson.M{"boardId": board.ID}},
+		{"lists", bson.M{"boardId": board.ID}},
+		{"swimlanes", bson.M{"boardId": board.ID}},
+		{"rules", bson.M{"boardId": board.ID}},
+		{"triggers", bson.M{"boardId": board.ID}},
+		{"actions", bson.M{"boardId": board.ID}},
+		{"customFields", bson.M{"boardIds": []BoardID{board.ID}}},
+	}
+	for _, f := range filters {
+		count, err := wekan.deleteAll(ctx, f.collection, f.filter)
+		if err != nil {
+			return err
+		}
+		deleted[f.collection] += count
+	}
+
+	if err := wekan.pullFromAll(ctx, "customFields", bson.M{"boardIds": board.ID}, bson.M{"boardIds": board.ID}); err != nil {
+		return err
+	}
+	if err := wekan.pullFromAll(ctx, "users", bson.M{"profile.starredBoards": board.ID}, bson.M{"profile.starredBoards": board.ID}); err != nil {
+		return err
+	}
+	if err := wekan.pullFromAll(ctx, "users", bson.M{"profile.invitedBoards": board.ID}, bson.M{"profile.invitedBoards": board.ID}); err != nil {
+		return err
+	}
+	if err := wekan.unsetTemplates(ctx, board.ID); err != nil {
+		return err
+	}
+	count, err := wekan.deleteAll(ctx, "boards", bson.M{"_id": board.ID})
+	if err != nil {
+		return err
+	}
+	deleted["boards"] += count
+	return nil
+}
+
+// selectIDs retourne les identifiants des documents de la collection qui correspondent au filtre
+func (wekan *Wekan) selectIDs(ctx context.Context, collection string, filter bson.M) ([]string, error) {
+	cur, err := wekan.db.Collection(collection).Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, UnexpectedMongoError{err}
+	}
+	var documents []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cur.All(ctx, &documents); err != nil {
+		return nil, UnexpectedMongoDecodeError{err}
+	}
+	ids := make([]string, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document.ID)
+	}
+	return ids, nil
+}
+
+// deleteAll supprime les documents qui correspondent au filtre et retourne leur nombre
+func (wekan *Wekan) deleteAll(ctx context.Context, collection string, filter bson.M) (int, error) {
+	result, err := wekan.db.Collection(collection).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, UnexpectedMongoError{err}
+	}
+	return int(result.DeletedCount), nil
+}
+
+// unsetTemplates retire la board et ses swimlanes de templates du profil des utilisateurs dont elle est la board de templates
+func (wekan *Wekan) unsetTemplates(ctx context.Context, boardID BoardID) error {
+	_, err := wekan.db.Collection("users").UpdateMany(ctx, bson.M{"profile.templatesBoardId": boardID}, bson.M{
+		"$unset": bson.M{
+			"profile.templatesBoardId":         "",
+			"profile.cardTemplatesSwimlaneId":  "",
+			"profile.listTemplatesSwimlaneId":  "",
+			"profile.boardTemplatesSwimlaneId": "",
+		},
+		"$set": bson.M{"modifiedAt": toMongoTime(time.Now())},
+	})
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	return nil
+}
+
+// pullFromAll retire pull des tableaux des documents qui correspondent au filtre
+func (wekan *Wekan) pullFromAll(ctx context.Context, collection string, filter bson.M, pull bson.M) error {
+	_, err := wekan.db.Collection(collection).UpdateMany(ctx, filter, bson.M{
+		"$pull": pull,
+		"$set":  bson.M{"modifiedAt": toMongoTime(time.Now())},
+	})
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	return nil
+}
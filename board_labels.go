@@ -0,0 +1,177 @@
+package libwekan
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LabelColors liste les couleurs d'étiquette proposées par Wekan
+var LabelColors = []string{
+	"white", "green", "yellow", "orange", "red", "purple", "blue", "sky", "lime", "pink", "black",
+	"silver", "peachpuff", "crimson", "plum", "darkgreen", "slateblue", "magenta", "gold", "navy",
+	"gray", "saddlebrown", "paleturquoise", "mistyrose", "indigo",
+}
+
+// BoardLabelDeletion rend compte de la suppression d'une étiquette : cartes dont elle a été retirée et règles supprimées
+type BoardLabelDeletion struct {
+	Label BoardLabel
+	Cards []CardID
+	Rules []RuleID
+}
+
+// RenameBoardLabel renomme une étiquette, le nom ne doit pas être utilisé par une autre étiquette de la board
+func (wekan *Wekan) RenameBoardLabel(ctx context.Context, boardID BoardID, labelID BoardLabelID, name BoardLabelName) (err error) {
+	ctx, end := wekan.observe(ctx, "RenameBoardLabel", Targets{"boardID": string(boardID), "boardLabelID": string(labelID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	board, label, err := wekan.getBoardLabel(ctx, boardID, labelID)
+	if err != nil {
+		return err
+	}
+	if label.Name == name {
+		return NothingDoneError{}
+	}
+	if name != "" && board.GetLabelByName(name) != (BoardLabel{}) {
+		return BoardLabelAlreadyExistsError{BoardLabel{ID: labelID, Name: name, Color: label.Color}, board}
+	}
+	return wekan.updateBoardLabel(ctx, boardID, labelID, "name", name)
+}
+
+// SetBoardLabelColor modifie la couleur d'une étiquette, qui doit figurer dans LabelColors
+func (wekan *Wekan) SetBoardLabelColor(ctx context.Context, boardID BoardID, labelID BoardLabelID, color string) (err error) {
+	ctx, end := wekan.observe(ctx, "SetBoardLabelColor", Targets{"boardID": string(boardID), "boardLabelID": string(labelID)})
+	defer end(&err)
+	if !contains(LabelColors, color) {
+		return InvalidValueError{"color", color}
+	}
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	_, label, err := wekan.getBoardLabel(ctx, boardID, labelID)
+	if err != nil {
+		return err
+	}
+	if label.Color == color {
+		return NothingDoneError{}
+	}
+	return wekan.updateBoardLabel(ctx, boardID, labelID, "color", color)
+}
+
+// DeleteBoardLabel supprime une étiquette de la board dans une transaction.
+// L'étiquette est retirée des cartes de la board, avec l'activité removedLabel de Wekan pour chaque carte,
+// et les règles déclenchées par l'étiquette sont supprimées.
+func (wekan *Wekan) DeleteBoardLabel(ctx context.Context, boardID BoardID, labelID BoardLabelID) (_ BoardLabelDeletion, err error) {
+	ctx, end := wekan.observe(ctx, "DeleteBoardLabel", Targets{"boardID": string(boardID), "boardLabelID": string(labelID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return BoardLabelDeletion{}, err
+	}
+	var deletion BoardLabelDeletion
+	err = wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		deletion, err = wekan.deleteBoardLabel(ctx, boardID, labelID)
+		return err
+	})
+	if err != nil {
+		return BoardLabelDeletion{}, err
+	}
+	return deletion, nil
+}
+
+func (wekan *Wekan) deleteBoardLabel(ctx context.Context, boardID BoardID, labelID BoardLabelID) (BoardLabelDeletion, error) {
+	_, label, err := wekan.getBoardLabel(ctx, boardID, labelID)
+	if err != nil {
+		return BoardLabelDeletion{}, err
+	}
+	deletion := BoardLabelDeletion{Label: label, Cards: []CardID{}, Rules: []RuleID{}}
+
+	cards, err := wekan.SelectCardsFromQuery(ctx, bson.M{"boardId": boardID, "labelIds": labelID})
+	if err != nil {
+		return BoardLabelDeletion{}, err
+	}
+	for _, card := range cards {
+		stats, err := wekan.db.Collection("cards").UpdateOne(ctx,
+			bson.M{"_id": card.ID},
+			bson.M{
+				"$pull": bson.M{"labelIds": labelID},
+				"$set":  bson.M{"modifiedAt": toMongoTime(time.Now())},
+			})
+		if err != nil {
+			return BoardLabelDeletion{}, UnexpectedMongoError{err}
+		}
+		if stats.ModifiedCount == 0 {
+			continue
+		}
+		if _, err := wekan.insertActivity(ctx, newActivityRemovedLabel(wekan.ActorID(), labelID, card)); err != nil {
+			return BoardLabelDeletion{}, err
+		}
+		deletion.Cards = append(deletion.Cards, card.ID)
+	}
+
+	rules, err := wekan.SelectRulesFromBoardID(ctx, boardID)
+	if err != nil {
+		return BoardLabelDeletion{}, err
+	}
+	for _, rule := range rules.SelectBoardLabelName(labelID) {
+		if err := wekan.RemoveRuleWithID(ctx, rule.ID); err != nil {
+			return BoardLabelDeletion{}, err
+		}
+		deletion.Rules = append(deletion.Rules, rule.ID)
+	}
+
+	stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+		bson.M{"_id": boardID},
+		bson.M{
+			"$pull": bson.M{"labels": bson.M{"_id": labelID}},
+			"$set":  bson.M{"modifiedAt": toMongoTime(time.Now())},
+		})
+	if err != nil {
+		return BoardLabelDeletion{}, UnexpectedMongoError{err}
+	}
+	if stats.ModifiedCount == 0 {
+		return BoardLabelDeletion{}, NothingDoneError{}
+	}
+	return deletion, nil
+}
+
+func (wekan *Wekan) getBoardLabel(ctx context.Context, boardID BoardID, labelID BoardLabelID) (Board, BoardLabel, error) {
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return Board{}, BoardLabel{}, err
+	}
+	label := board.GetLabelByID(labelID)
+	if label == (BoardLabel{}) {
+		return Board{}, BoardLabel{}, BoardLabelNotFoundError{labelID, board}
+	}
+	return board, label, nil
+}
+
+// updateBoardLabel modifie le champ field de l'étiquette labelID en une seule écriture,
+// Wekan n'a pas d'activité pour le renommage ou le changement de couleur d'une étiquette
+func (wekan *Wekan) updateBoardLabel(ctx context.Context, boardID BoardID, labelID BoardLabelID, field string, value interface{}) error {
+	stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+		bson.M{"_id": boardID},
+		bson.M{
+			"$set": bson.M{
+				"labels.$[label]." + field: value,
+				"modifiedAt":               toMongoTime(time.Now()),
+			},
+		},
+		&options.UpdateOptions{
+			ArrayFilters: &options.ArrayFilters{
+				Filters: bson.A{bson.M{"label._id": labelID}}},
+		},
+	)
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	if stats.ModifiedCount == 0 {
+		return NothingDoneError{}
+	}
+	return nil
+}
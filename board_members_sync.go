@@ -0,0 +1,199 @@
+package libwekan
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BoardMemberRoleChange décrit le changement de rôle d'un membre
+type BoardMemberRoleChange struct {
+	UserID UserID
+	Old    BoardRole
+	New    BoardRole
+}
+
+// BoardMembersSync rend compte de SyncBoardMembers, chaque liste est triée par identifiant.
+// Protected liste les membres maintenus actifs et administrateurs alors qu'ils n'étaient pas souhaités ainsi
+// (utilisateur administrateur de libwekan).
+type BoardMembersSync struct {
+	BoardID     BoardID
+	Added       []UserID
+	Activated   []UserID
+	Deactivated []UserID
+	RoleChanges []BoardMemberRoleChange
+	Protected   []UserID
+}
+
+// Empty indique que les membres de la board correspondaient déjà à l'état souhaité
+func (sync BoardMembersSync) Empty() bool {
+	return len(sync.Added)+len(sync.Activated)+len(sync.Deactivated)+len(sync.RoleChanges) == 0
+}
+
+// SyncBoardMembers fait correspondre les membres actifs de la board et leurs rôles à desired :
+// les utilisateurs absents sont ajoutés, les membres inactifs sont réactivés, les membres non souhaités sont désactivés.
+// La board est lue une fois puis ses membres sont remplacés en une seule mise à jour, avec les activités correspondantes, dans une transaction.
+// Les autres propriétés des membres sont conservées ; une BoardModifiedError est retournée si la board a été modifiée
+// par ailleurs depuis sa lecture.
+func (wekan *Wekan) SyncBoardMembers(ctx context.Context, boardID BoardID, desired map[UserID]BoardRole) (_ BoardMembersSync, err error) {
+	ctx, end := wekan.observe(ctx, "SyncBoardMembers", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	for _, role := range desired {
+		if !contains(BoardRoles, role) {
+			return BoardMembersSync{}, InvalidValueError{"role", string(role)}
+		}
+	}
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return BoardMembersSync{}, err
+	}
+	var sync BoardMembersSync
+	err = wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		sync, err = wekan.syncBoardMembers(ctx, boardID, desired)
+		return err
+	})
+	if err != nil {
+		return BoardMembersSync{}, err
+	}
+	return sync, nil
+}
+
+func (wekan *Wekan) syncBoardMembers(ctx context.Context, boardID BoardID, desired map[UserID]BoardRole) (BoardMembersSync, error) {
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return BoardMembersSync{}, err
+	}
+	sync := BoardMembersSync{BoardID: boardID}
+	members := make([]BoardMember, 0, len(board.Members)+len(desired))
+	for _, member := range board.Members {
+		role, wanted := desired[member.UserID]
+		updated := member
+		if wanted {
+			updated = member.WithRole(role)
+			updated.IsActive = true
+		} else {
+			updated.IsActive = false
+		}
+		if member.UserID == wekan.adminUserID && member.IsAdmin && member.IsActive && !(updated.IsAdmin && updated.IsActive) {
+			updated = member
+			sync.Protected = append(sync.Protected, member.UserID)
+		}
+		if updated.IsActive && !member.IsActive {
+			sync.Activated = append(sync.Activated, member.UserID)
+		}
+		if !updated.IsActive && member.IsActive {
+			sync.Deactivated = append(sync.Deactivated, member.UserID)
+		}
+		if updated.Role() != member.Role() {
+			sync.RoleChanges = append(sync.RoleChanges, BoardMemberRoleChange{member.UserID, member.Role(), updated.Role()})
+		}
+		members = append(members, updated)
+	}
+
+	for userID := range desired {
+		if board.GetMember(userID) == (BoardMember{}) {
+			sync.Added = append(sync.Added, userID)
+		}
+	}
+	sortUserIDs(sync.Added)
+	if _, err := wekan.GetUsersFromIDs(ctx, sync.Added); err != nil {
+		return BoardMembersSync{}, err
+	}
+	for _, userID := range sync.Added {
+		members = append(members, BoardMember{UserID: userID, IsActive: true}.WithRole(desired[userID]))
+	}
+
+	sortUserIDs(sync.Activated)
+	sortUserIDs(sync.Deactivated)
+	sortUserIDs(sync.Protected)
+	sort.Slice(sync.RoleChanges, func(i, j int) bool { return sync.RoleChanges[i].UserID < sync.RoleChanges[j].UserID })
+	if sync.Empty() {
+		return sync, nil
+	}
+	if len(board.ActiveAdmins()) > 0 && len(Board{Members: members}.ActiveAdmins()) == 0 {
+		return BoardMembersSync{}, LastBoardAdminError{boardID, board.ActiveAdmins()[0]}
+	}
+
+	if err := wekan.updateSyncedMembers(ctx, board, members); err != nil {
+		return BoardMembersSync{}, err
+	}
+	return sync, wekan.insertSyncActivities(ctx, sync)
+}
+
+// updateSyncedMembers remplace les membres de la board par members en une seule mise à jour,
+// qui n'aboutit que si modifiedAt n'a pas changé depuis la lecture de board.
+// Les propriétés des membres inconnues de libwekan sont reprises du document.
+func (wekan *Wekan) updateSyncedMembers(ctx context.Context, board Board, members []BoardMember) error {
+	filter := bson.M{"_id": board.ID, "modifiedAt": bson.M{"$exists": false}}
+	if !board.ModifiedAt.IsZero() {
+		filter["modifiedAt"] = board.ModifiedAt
+	}
+	var document struct {
+		Members []bson.M `bson:"members"`
+	}
+	err := wekan.db.Collection("boards").FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"members": 1})).Decode(&document)
+	if err == mongo.ErrNoDocuments {
+		return BoardModifiedError{board.ID}
+	}
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	known := make(map[UserID]bson.M, len(document.Members))
+	for _, raw := range document.Members {
+		if userID, ok := raw["userId"].(string); ok {
+			known[UserID(userID)] = raw
+		}
+	}
+	rawMembers := make(bson.A, 0, len(members))
+	for _, member := range members {
+		raw, ok := known[member.UserID]
+		if !ok {
+			rawMembers = append(rawMembers, member)
+			continue
+		}
+		raw["isActive"] = member.IsActive
+		raw["isAdmin"] = member.IsAdmin
+		raw["isCommentOnly"] = member.IsCommentOnly
+		raw["isNoComments"] = member.IsNoComments
+		raw["isWorker"] = member.IsWorker
+		rawMembers = append(rawMembers, raw)
+	}
+
+	stats, err := wekan.db.Collection("boards").UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"members": rawMembers, "modifiedAt": toMongoTime(time.Now())},
+	})
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	if stats.MatchedCount == 0 {
+		return BoardModifiedError{board.ID}
+	}
+	return nil
+}
+
+func (wekan *Wekan) insertSyncActivities(ctx context.Context, sync BoardMembersSync) error {
+	var activities []Activity
+	for _, userID := range append(append([]UserID{}, sync.Added...), sync.Activated...) {
+		activities = append(activities, newActivityAddBoardMember(wekan.ActorID(), userID, sync.BoardID))
+	}
+	for _, userID := range sync.Deactivated {
+		activities = append(activities, newActivityRemoveBoardMember(wekan.ActorID(), userID, sync.BoardID))
+	}
+	for _, change := range sync.RoleChanges {
+		activities = append(activities, newActivityChangeBoardMemberRole(wekan.ActorID(), change.UserID, sync.BoardID))
+	}
+	for _, activity := range activities {
+		if _, err := wekan.insertActivity(ctx, activity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortUserIDs(userIDs []UserID) {
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+}
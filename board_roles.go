@@ -0,0 +1,137 @@
+package libwekan
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BoardRole est le rôle d'un membre sur une board, tel que proposé par le menu des permissions de Wekan
+type BoardRole string
+
+const (
+	RoleNormal      BoardRole = "normal"
+	RoleAdmin       BoardRole = "admin"
+	RoleCommentOnly BoardRole = "commentOnly"
+	RoleNoComments  BoardRole = "noComments"
+	RoleWorker      BoardRole = "worker"
+)
+
+// BoardRoles liste les rôles acceptés par SetBoardMemberRole
+var BoardRoles = []BoardRole{RoleNormal, RoleAdmin, RoleCommentOnly, RoleNoComments, RoleWorker}
+
+// Role déduit le rôle du membre de ses indicateurs, un administrateur est toujours RoleAdmin
+func (member BoardMember) Role() BoardRole {
+	switch {
+	case member.IsAdmin:
+		return RoleAdmin
+	case member.IsCommentOnly:
+		return RoleCommentOnly
+	case member.IsNoComments:
+		return RoleNoComments
+	case member.IsWorker:
+		return RoleWorker
+	}
+	return RoleNormal
+}
+
+// WithRole retourne le membre avec les indicateurs correspondant au rôle, les autres indicateurs sont désactivés
+func (member BoardMember) WithRole(role BoardRole) BoardMember {
+	member.IsAdmin = role == RoleAdmin
+	member.IsCommentOnly = role == RoleCommentOnly
+	member.IsNoComments = role == RoleNoComments
+	member.IsWorker = role == RoleWorker
+	return member
+}
+
+// ActiveAdmins retourne les identifiants des administrateurs actifs de la board
+func (board Board) ActiveAdmins() []UserID {
+	var admins []UserID
+	for _, member := range board.Members {
+		if member.IsAdmin && member.IsActive {
+			admins = append(admins, member.UserID)
+		}
+	}
+	return admins
+}
+
+// SetBoardMemberRole attribue le rôle à un membre de la board et trace l'activité dans une transaction.
+// L'utilisateur administrateur de libwekan et le dernier administrateur actif d'une board ne peuvent pas perdre leur rôle d'administrateur,
+// la présence d'un autre administrateur actif est vérifiée par le filtre de la mise à jour pour résister aux rétrogradations concurrentes.
+func (wekan *Wekan) SetBoardMemberRole(ctx context.Context, boardID BoardID, userID UserID, role BoardRole) (err error) {
+	ctx, end := wekan.observe(ctx, "SetBoardMemberRole", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	if !contains(BoardRoles, role) {
+		return InvalidValueError{"role", string(role)}
+	}
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	member := board.GetMember(userID)
+	if member == (BoardMember{}) {
+		return UserIsNotMemberError{userID}
+	}
+	if member.Role() == role {
+		return NothingDoneError{}
+	}
+	if err := wekan.assertCanDemote(board, member, role); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": boardID}
+	demotesActiveAdmin := member.IsAdmin && member.IsActive && role != RoleAdmin
+	if demotesActiveAdmin {
+		filter["members"] = bson.M{"$elemMatch": bson.M{"isAdmin": true, "isActive": true, "userId": bson.M{"$ne": userID}}}
+	}
+	updated := member.WithRole(role)
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+			filter,
+			bson.M{
+				"$set": bson.M{
+					"members.$[member].isAdmin":       updated.IsAdmin,
+					"members.$[member].isCommentOnly": updated.IsCommentOnly,
+					"members.$[member].isNoComments":  updated.IsNoComments,
+					"members.$[member].isWorker":      updated.IsWorker,
+					"modifiedAt":                      toMongoTime(time.Now()),
+				},
+			},
+			&options.UpdateOptions{
+				ArrayFilters: &options.ArrayFilters{
+					Filters: bson.A{bson.M{"member.userId": userID}}},
+			},
+		)
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		// les autres administrateurs actifs ont été rétrogradés ou désactivés depuis la lecture de la board
+		if stats.MatchedCount == 0 && demotesActiveAdmin {
+			return LastBoardAdminError{boardID, userID}
+		}
+		if stats.ModifiedCount == 0 {
+			return NothingDoneError{}
+		}
+		_, err = wekan.insertActivity(ctx, newActivityChangeBoardMemberRole(wekan.ActorID(), userID, boardID))
+		return err
+	})
+}
+
+// assertCanDemote vérifie qu'attribuer role au membre ne retire pas un administrateur protégé
+func (wekan *Wekan) assertCanDemote(board Board, member BoardMember, role BoardRole) error {
+	if !member.IsAdmin || role == RoleAdmin {
+		return nil
+	}
+	if member.UserID == wekan.adminUserID {
+		return ForbiddenOperationError{ProtectedUserError{member.UserID}}
+	}
+	if member.IsActive && len(board.ActiveAdmins()) <= 1 {
+		return LastBoardAdminError{board.ID, member.UserID}
+	}
+	return nil
+}
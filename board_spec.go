@@ -0,0 +1,379 @@
+package libwekan
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BoardSpec décrit l'état attendu d'une board : les objets absents sont créés par Apply,
+// les objets présents dans la board mais absents de la spécification sont conservés
+type BoardSpec struct {
+	Slug         BoardSlug         `json:"slug" yaml:"slug"`
+	Title        BoardTitle        `json:"title" yaml:"title"`
+	Swimlanes    []string          `json:"swimlanes,omitempty" yaml:"swimlanes,omitempty"`
+	Lists        []string          `json:"lists,omitempty" yaml:"lists,omitempty"`
+	Labels       []LabelSpec       `json:"labels,omitempty" yaml:"labels,omitempty"`
+	CustomFields []CustomFieldSpec `json:"customFields,omitempty" yaml:"customFields,omitempty"`
+	Rules        []RuleSpec        `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+type LabelSpec struct {
+	Name  BoardLabelName `json:"name" yaml:"name"`
+	Color string         `json:"color" yaml:"color"`
+}
+
+// CustomFieldSpec décrit un champ personnalisé, de type `text` par défaut
+type CustomFieldSpec struct {
+	Name                string `json:"name" yaml:"name"`
+	Type                string `json:"type,omitempty" yaml:"type,omitempty"`
+	ShowOnCard          bool   `json:"showOnCard,omitempty" yaml:"showOnCard,omitempty"`
+	ShowLabelOnMiniCard bool   `json:"showLabelOnMiniCard,omitempty" yaml:"showLabelOnMiniCard,omitempty"`
+	AutomaticallyOnCard bool   `json:"automaticallyOnCard,omitempty" yaml:"automaticallyOnCard,omitempty"`
+}
+
+// RuleSpec décrit une règle d'ajout (`addMember`) ou de retrait (`removeMember`) de l'utilisateur Username
+// sur les cartes lors de l'ajout ou du retrait de l'étiquette Label
+type RuleSpec struct {
+	Action   string         `json:"action" yaml:"action"`
+	Label    BoardLabelName `json:"label" yaml:"label"`
+	Username Username       `json:"username" yaml:"username"`
+}
+
+type BoardPlanStepKind string
+
+const (
+	StepCreateBoard       BoardPlanStepKind = "createBoard"
+	StepRenameBoard       BoardPlanStepKind = "renameBoard"
+	StepCreateSwimlane    BoardPlanStepKind = "createSwimlane"
+	StepCreateList        BoardPlanStepKind = "createList"
+	StepCreateLabel       BoardPlanStepKind = "createLabel"
+	StepSetLabelColor     BoardPlanStepKind = "setLabelColor"
+	StepCreateCustomField BoardPlanStepKind = "createCustomField"
+	StepAddBoardMember    BoardPlanStepKind = "addBoardMember"
+	StepCreateRule        BoardPlanStepKind = "createRule"
+)
+
+// BoardPlanStep est une opération du plan, Index est la position de l'objet concerné dans la spécification
+// (la première règle citant l'utilisateur pour StepAddBoardMember)
+type BoardPlanStep struct {
+	Kind  BoardPlanStepKind
+	Name  string
+	Index int
+}
+
+// BoardPlan liste les opérations nécessaires pour que la board corresponde à Spec, BoardID est vide si la board doit être créée
+type BoardPlan struct {
+	Spec    BoardSpec
+	BoardID BoardID
+	Steps   []BoardPlanStep
+}
+
+// ParseBoardSpec lit une spécification au format YAML ou JSON
+func ParseBoardSpec(data []byte) (BoardSpec, error) {
+	var spec BoardSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return BoardSpec{}, InvalidBoardSpecError{err.Error()}
+	}
+	return spec, spec.Validate()
+}
+
+// Validate contrôle la cohérence interne de la spécification, sans accès à la base
+func (spec BoardSpec) Validate() error {
+	if spec.Slug == "" || spec.Title == "" {
+		return InvalidBoardSpecError{"le slug et le titre sont obligatoires"}
+	}
+	if name, ok := firstDuplicate(spec.Swimlanes, func(title string) string { return title }); ok {
+		return InvalidBoardSpecError{fmt.Sprintf("swimlane en double : %s", name)}
+	}
+	if name, ok := firstDuplicate(spec.Lists, func(title string) string { return title }); ok {
+		return InvalidBoardSpecError{fmt.Sprintf("liste en double : %s", name)}
+	}
+	if name, ok := firstDuplicate(spec.Labels, func(label LabelSpec) string { return string(label.Name) }); ok {
+		return InvalidBoardSpecError{fmt.Sprintf("étiquette en double : %s", name)}
+	}
+	if name, ok := firstDuplicate(spec.CustomFields, func(field CustomFieldSpec) string { return field.Name }); ok {
+		return InvalidBoardSpecError{fmt.Sprintf("champ personnalisé en double : %s", name)}
+	}
+	for _, label := range spec.Labels {
+		if !contains(LabelColors, label.Color) {
+			return InvalidBoardSpecError{fmt.Sprintf("couleur d'étiquette inconnue pour %s : '%s'", label.Name, label.Color)}
+		}
+	}
+	for _, field := range spec.CustomFields {
+		if field.Type != "" && !contains(CustomFieldTypes, field.Type) {
+			return InvalidBoardSpecError{fmt.Sprintf("type de champ personnalisé inconnu pour %s : %s", field.Name, field.Type)}
+		}
+	}
+	for _, rule := range spec.Rules {
+		if rule.Action != "addMember" && rule.Action != "removeMember" {
+			return InvalidBoardSpecError{fmt.Sprintf("action de règle inconnue : %s", rule.Action)}
+		}
+		if rule.Label == "" || rule.Username == "" {
+			return InvalidBoardSpecError{"les règles doivent préciser une étiquette et un utilisateur"}
+		}
+	}
+	return nil
+}
+
+func firstDuplicate[T any](items []T, name func(T) string) (string, bool) {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[name(item)] {
+			return name(item), true
+		}
+		seen[name(item)] = true
+	}
+	return "", false
+}
+
+// Empty est vrai lorsque la board correspond déjà à la spécification
+func (plan BoardPlan) Empty() bool {
+	return len(plan.Steps) == 0
+}
+
+func (plan *BoardPlan) add(kind BoardPlanStepKind, name string, index int) {
+	plan.Steps = append(plan.Steps, BoardPlanStep{Kind: kind, Name: name, Index: index})
+}
+
+// Plan compare la spécification à la board de même slug retournée par SelectConfig et SelectRulesFromBoardID
+// et retourne les opérations nécessaires, sans rien modifier
+func (wekan *Wekan) Plan(ctx context.Context, spec BoardSpec) (_ BoardPlan, err error) {
+	ctx, end := wekan.observe(ctx, "Plan", Targets{"slug": string(spec.Slug)})
+	defer end(&err)
+	if err := spec.Validate(); err != nil {
+		return BoardPlan{}, err
+	}
+	if !wekan.inDomain(spec.Slug) {
+		return BoardPlan{}, ForbiddenOperationError{fmt.Errorf("le slug %s n'appartient pas au domaine", spec.Slug)}
+	}
+	config, err := wekan.SelectConfig(ctx)
+	if err != nil {
+		return BoardPlan{}, err
+	}
+
+	plan := BoardPlan{Spec: spec}
+	var rules Rules
+	configBoard, exists := config.BoardBySlug(spec.Slug)
+	if exists {
+		plan.BoardID = configBoard.Board.ID
+		if rules, err = wekan.SelectRulesFromBoardID(ctx, plan.BoardID); err != nil {
+			return BoardPlan{}, err
+		}
+		if configBoard.Board.Title != spec.Title {
+			plan.add(StepRenameBoard, string(spec.Title), 0)
+		}
+	} else {
+		plan.add(StepCreateBoard, string(spec.Slug), 0)
+	}
+
+	for i, title := range spec.Swimlanes {
+		if _, ok := config.SwimlaneByTitle(plan.BoardID, title); !ok {
+			plan.add(StepCreateSwimlane, title, i)
+		}
+	}
+	for i, title := range spec.Lists {
+		if _, ok := config.ListByTitle(plan.BoardID, title); !ok {
+			plan.add(StepCreateList, title, i)
+		}
+	}
+	for i, labelSpec := range spec.Labels {
+		label, ok := config.LabelByName(plan.BoardID, labelSpec.Name)
+		if !ok {
+			plan.add(StepCreateLabel, string(labelSpec.Name), i)
+		} else if label.Color != labelSpec.Color {
+			plan.add(StepSetLabelColor, string(labelSpec.Name), i)
+		}
+	}
+	for i, field := range spec.CustomFields {
+		if _, ok := config.CustomFieldByName(plan.BoardID, field.Name); !ok {
+			plan.add(StepCreateCustomField, field.Name, i)
+		}
+	}
+	if err := wekan.planRuleMembers(ctx, &plan, configBoard.Board); err != nil {
+		return BoardPlan{}, err
+	}
+	for i, ruleSpec := range spec.Rules {
+		label, ok := config.LabelByName(plan.BoardID, ruleSpec.Label)
+		if !ok || !rules.contains(ruleSpec, label.ID) {
+			plan.add(StepCreateRule, fmt.Sprintf("%s %s (%s)", ruleSpec.Action, ruleSpec.Username, ruleSpec.Label), i)
+		}
+	}
+	return plan, nil
+}
+
+// planRuleMembers ajoute au plan les utilisateurs cités par les règles qui ne sont pas membres actifs de la board,
+// l'acteur courant est administrateur d'une board créée par le plan
+func (wekan *Wekan) planRuleMembers(ctx context.Context, plan *BoardPlan, board Board) error {
+	planned := make(map[Username]bool)
+	for i, ruleSpec := range plan.Spec.Rules {
+		if planned[ruleSpec.Username] {
+			continue
+		}
+		planned[ruleSpec.Username] = true
+		user, err := wekan.GetUserFromUsername(ctx, ruleSpec.Username)
+		if err != nil {
+			return err
+		}
+		// ActorID n'est connu qu'après AssertPrivileged lorsque l'acteur est l'utilisateur admin
+		isActor := user.ID == wekan.actorUserID || (wekan.actorUserID == "" && user.Username == wekan.adminUsername)
+		if plan.BoardID == "" && isActor {
+			continue
+		}
+		if !board.UserIsActiveMember(user) {
+			plan.add(StepAddBoardMember, string(user.Username), i)
+		}
+	}
+	return nil
+}
+
+func (rules Rules) contains(spec RuleSpec, labelID BoardLabelID) bool {
+	for _, rule := range rules {
+		if rule.Action.ActionType == spec.Action && rule.Action.Username == spec.Username && rule.Trigger.LabelID == labelID {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply exécute les opérations du plan dans une transaction et retourne l'identifiant de la board.
+// Une board créée a pour administrateur l'acteur courant, les utilisateurs cités par les règles en deviennent membres actifs.
+func (wekan *Wekan) Apply(ctx context.Context, plan BoardPlan) (_ BoardID, err error) {
+	ctx, end := wekan.observe(ctx, "Apply", Targets{"boardID": string(plan.BoardID), "slug": string(plan.Spec.Slug)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return "", err
+	}
+	// le plan a pu être construit par un autre objet Wekan ou modifié depuis Plan
+	if err := plan.Spec.Validate(); err != nil {
+		return "", err
+	}
+	if !wekan.inDomain(plan.Spec.Slug) {
+		return "", ForbiddenOperationError{fmt.Errorf("le slug %s n'appartient pas au domaine", plan.Spec.Slug)}
+	}
+	boardID := plan.BoardID
+	err = wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, step := range plan.Steps {
+			createdBoardID, err := wekan.applyStep(ctx, plan.Spec, boardID, step)
+			if err != nil {
+				return err
+			}
+			if createdBoardID != "" {
+				boardID = createdBoardID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return boardID, nil
+}
+
+// applyStep exécute une opération du plan, l'identifiant de la board n'est retourné que par StepCreateBoard
+func (wekan *Wekan) applyStep(ctx context.Context, spec BoardSpec, boardID BoardID, step BoardPlanStep) (BoardID, error) {
+	if err := spec.checkStepIndex(step); err != nil {
+		return "", err
+	}
+	switch step.Kind {
+	case StepCreateBoard:
+		board := BuildBoard(string(spec.Title), string(spec.Slug), "board")
+		if err := wekan.InsertBoard(ctx, board); err != nil {
+			return "", err
+		}
+		return board.ID, wekan.AddMemberToBoard(ctx, board.ID, BoardMember{UserID: wekan.ActorID(), IsAdmin: true, IsActive: true})
+	case StepRenameBoard:
+		return "", wekan.RenameBoard(ctx, boardID, spec.Title)
+	case StepCreateSwimlane:
+		return "", wekan.InsertSwimlane(ctx, BuildSwimlane(boardID, "swimlane", spec.Swimlanes[step.Index], float64(step.Index)))
+	case StepCreateList:
+		list := BuildList(boardID, spec.Lists[step.Index], float64(step.Index))
+		if err := wekan.InsertList(ctx, list); err != nil {
+			return "", err
+		}
+		_, err := wekan.insertActivity(ctx, newActivityCreateList(wekan.ActorID(), boardID, list.ID))
+		return "", err
+	case StepCreateLabel:
+		board, err := wekan.GetBoardFromID(ctx, boardID)
+		if err != nil {
+			return "", err
+		}
+		label := spec.Labels[step.Index]
+		return "", wekan.InsertBoardLabel(ctx, board, NewBoardLabel(string(label.Name), label.Color))
+	case StepSetLabelColor:
+		board, err := wekan.GetBoardFromID(ctx, boardID)
+		if err != nil {
+			return "", err
+		}
+		label := spec.Labels[step.Index]
+		return "", wekan.SetBoardLabelColor(ctx, boardID, board.GetLabelByName(label.Name).ID, label.Color)
+	case StepCreateCustomField:
+		fieldSpec := spec.CustomFields[step.Index]
+		fieldType := fieldSpec.Type
+		if fieldType == "" {
+			fieldType = "text"
+		}
+		field := BuildCustomField(fieldSpec.Name, fieldType, boardID)
+		field.ShowOnCard = fieldSpec.ShowOnCard
+		field.ShowLabelOnMiniCard = fieldSpec.ShowLabelOnMiniCard
+		field.AutomaticallyOnCard = fieldSpec.AutomaticallyOnCard
+		return "", wekan.InsertCustomField(ctx, field)
+	case StepAddBoardMember:
+		user, err := wekan.GetUserFromUsername(ctx, spec.Rules[step.Index].Username)
+		if err != nil {
+			return "", err
+		}
+		_, err = wekan.ensureUserIsActiveBoardMember(ctx, boardID, user.ID)
+		return "", err
+	case StepCreateRule:
+		return "", wekan.applyRuleSpec(ctx, boardID, spec.Rules[step.Index])
+	}
+	return "", InvalidBoardSpecError{fmt.Sprintf("opération inconnue : %s", step.Kind)}
+}
+
+// checkStepIndex vérifie que l'opération désigne un objet de la spécification
+func (spec BoardSpec) checkStepIndex(step BoardPlanStep) error {
+	var count int
+	switch step.Kind {
+	case StepCreateSwimlane:
+		count = len(spec.Swimlanes)
+	case StepCreateList:
+		count = len(spec.Lists)
+	case StepCreateLabel, StepSetLabelColor:
+		count = len(spec.Labels)
+	case StepCreateCustomField:
+		count = len(spec.CustomFields)
+	case StepAddBoardMember, StepCreateRule:
+		count = len(spec.Rules)
+	default:
+		return nil
+	}
+	if step.Index < 0 || step.Index >= count {
+		return InvalidBoardSpecError{fmt.Sprintf("l'opération %s %s désigne l'élément %d, absent de la spécification", step.Kind, step.Name, step.Index)}
+	}
+	return nil
+}
+
+func (wekan *Wekan) applyRuleSpec(ctx context.Context, boardID BoardID, ruleSpec RuleSpec) error {
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	user, err := wekan.GetUserFromUsername(ctx, ruleSpec.Username)
+	if err != nil {
+		return err
+	}
+	if !board.UserIsActiveMember(user) {
+		return UserIsNotMemberError{user.ID}
+	}
+	label := board.GetLabelByName(ruleSpec.Label)
+	if label == (BoardLabel{}) {
+		return BoardLabelNotFoundError{Board: board}
+	}
+	rule := board.BuildRuleAddMember(user, ruleSpec.Label)
+	if ruleSpec.Action == "removeMember" {
+		rule = board.BuildRuleRemoveMember(user, ruleSpec.Label)
+	}
+	return wekan.InsertRule(ctx, rule)
+}
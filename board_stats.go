@@ -0,0 +1,193 @@
+package libwekan
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DefaultInactivityPeriod est la durée sans activité au-delà de laquelle une carte non archivée est comptée comme inactive
+const DefaultInactivityPeriod = 30 * 24 * time.Hour
+
+// StatsOptions précise le calcul de BoardStats et DomainStats
+type StatsOptions struct {
+	// InactivityPeriod remplace DefaultInactivityPeriod lorsqu'elle est positive
+	InactivityPeriod time.Duration
+}
+
+// inactiveSince retourne la date avant laquelle la dernière activité d'une carte la rend inactive
+func (opts StatsOptions) inactiveSince() time.Time {
+	period := opts.InactivityPeriod
+	if period <= 0 {
+		period = DefaultInactivityPeriod
+	}
+	return toMongoTime(time.Now().Add(-period))
+}
+
+// CardCounts dénombre les cartes, Active et Archived forment une partition de Cards,
+// Inactive compte les cartes actives dont dateLastActivity est antérieure à InactiveSince des statistiques
+type CardCounts struct {
+	Cards    int `bson:"cards"`
+	Active   int `bson:"-"`
+	Archived int `bson:"archived"`
+	Inactive int `bson:"inactive"`
+}
+
+// BoardStats rassemble les décomptes d'une board, les répartitions ne portent que sur les cartes actives
+type BoardStats struct {
+	BoardID       BoardID
+	InactiveSince time.Time
+	CardCounts
+	ByList     map[ListID]int
+	BySwimlane map[SwimlaneID]int
+	ByLabel    map[BoardLabelID]int
+	ByMember   map[UserID]int
+	ByAssignee map[UserID]int
+}
+
+// DomainStats rassemble les décomptes des boards du domaine, les boards sans carte n'apparaissent pas dans Boards
+type DomainStats struct {
+	InactiveSince time.Time
+	CardCounts
+	Boards map[BoardID]CardCounts
+}
+
+type statsCount struct {
+	ID    string `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+type boardStatsFacets struct {
+	Totals    []CardCounts `bson:"totals"`
+	Lists     []statsCount `bson:"lists"`
+	Swimlanes []statsCount `bson:"swimlanes"`
+	Labels    []statsCount `bson:"labels"`
+	Members   []statsCount `bson:"members"`
+	Assignees []statsCount `bson:"assignees"`
+}
+
+type boardCardCounts struct {
+	BoardID    BoardID `bson:"_id"`
+	CardCounts `bson:",inline"`
+}
+
+// cardCountsGroup retourne les accumulateurs `$group` de CardCounts, l'identifiant du groupe est id
+func cardCountsGroup(id interface{}, inactiveSince time.Time) bson.M {
+	return bson.M{
+		"_id":      id,
+		"cards":    bson.M{"$sum": 1},
+		"archived": bson.M{"$sum": bson.M{"$cond": bson.A{"$archived", 1, 0}}},
+		"inactive": bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$and": bson.A{
+				bson.M{"$not": bson.A{"$archived"}},
+				bson.M{"$lt": bson.A{"$dateLastActivity", inactiveSince}},
+			}},
+			1, 0,
+		}}},
+	}
+}
+
+// countActiveCardsBy retourne la facette comptant les cartes actives par valeur du champ, les tableaux sont dépliés
+func countActiveCardsBy(field string, unwind bool) bson.A {
+	facet := bson.A{bson.M{"$match": bson.M{"archived": false}}}
+	if unwind {
+		facet = append(facet, bson.M{"$unwind": "$" + field})
+	}
+	return append(facet, bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}})
+}
+
+// BuildBoardStatsPipeline retourne le pipeline, sur la collection cards, produisant les décomptes de la board en un seul document
+func (wekan *Wekan) BuildBoardStatsPipeline(boardID BoardID, inactiveSince time.Time) Pipeline {
+	return Pipeline{
+		bson.M{"$match": bson.M{"boardId": boardID}},
+		bson.M{"$facet": bson.M{
+			"totals":    bson.A{bson.M{"$group": cardCountsGroup(nil, inactiveSince)}},
+			"lists":     countActiveCardsBy("listId", false),
+			"swimlanes": countActiveCardsBy("swimlaneId", false),
+			"labels":    countActiveCardsBy("labelIds", true),
+			"members":   countActiveCardsBy("members", true),
+			"assignees": countActiveCardsBy("assignees", true),
+		}},
+	}
+}
+
+// BuildDomainStatsPipeline retourne le pipeline, sur la collection boards, produisant les décomptes de chaque board du domaine
+func (wekan *Wekan) BuildDomainStatsPipeline(inactiveSince time.Time) Pipeline {
+	pipeline := wekan.BuildDomainCardsPipeline()
+	pipeline.AppendStage(bson.M{"$group": cardCountsGroup("$boardId", inactiveSince)})
+	return pipeline
+}
+
+// BoardStats calcule côté serveur les décomptes de cartes de la board : totaux, cartes inactives depuis opts.InactivityPeriod,
+// et répartition des cartes actives par liste, swimlane, étiquette, membre et assigné
+func (wekan *Wekan) BoardStats(ctx context.Context, boardID BoardID, opts StatsOptions) (_ BoardStats, err error) {
+	ctx, end := wekan.observe(ctx, "BoardStats", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	if err := boardID.Check(ctx, wekan); err != nil {
+		return BoardStats{}, err
+	}
+	inactiveSince := opts.inactiveSince()
+	cur, err := wekan.db.Collection("cards").Aggregate(ctx, wekan.BuildBoardStatsPipeline(boardID, inactiveSince))
+	if err != nil {
+		return BoardStats{}, UnexpectedMongoError{err}
+	}
+	var facets []boardStatsFacets
+	if err := cur.All(ctx, &facets); err != nil {
+		return BoardStats{}, UnexpectedMongoDecodeError{err}
+	}
+
+	stats := BoardStats{BoardID: boardID, InactiveSince: inactiveSince}
+	var facet boardStatsFacets
+	if len(facets) > 0 {
+		facet = facets[0]
+	}
+	if len(facet.Totals) > 0 {
+		stats.CardCounts = facet.Totals[0].withActive()
+	}
+	stats.ByList = countsByID[ListID](facet.Lists)
+	stats.BySwimlane = countsByID[SwimlaneID](facet.Swimlanes)
+	stats.ByLabel = countsByID[BoardLabelID](facet.Labels)
+	stats.ByMember = countsByID[UserID](facet.Members)
+	stats.ByAssignee = countsByID[UserID](facet.Assignees)
+	return stats, nil
+}
+
+// DomainStats calcule côté serveur les décomptes de cartes de chaque board du domaine et leur total
+func (wekan *Wekan) DomainStats(ctx context.Context, opts StatsOptions) (_ DomainStats, err error) {
+	ctx, end := wekan.observe(ctx, "DomainStats", nil)
+	defer end(&err)
+	inactiveSince := opts.inactiveSince()
+	cur, err := wekan.db.Collection("boards").Aggregate(ctx, wekan.BuildDomainStatsPipeline(inactiveSince))
+	if err != nil {
+		return DomainStats{}, UnexpectedMongoError{err}
+	}
+	var boards []boardCardCounts
+	if err := cur.All(ctx, &boards); err != nil {
+		return DomainStats{}, UnexpectedMongoDecodeError{err}
+	}
+
+	stats := DomainStats{InactiveSince: inactiveSince, Boards: make(map[BoardID]CardCounts, len(boards))}
+	for _, board := range boards {
+		counts := board.CardCounts.withActive()
+		stats.Boards[board.BoardID] = counts
+		stats.Cards += counts.Cards
+		stats.Active += counts.Active
+		stats.Archived += counts.Archived
+		stats.Inactive += counts.Inactive
+	}
+	return stats, nil
+}
+
+func (counts CardCounts) withActive() CardCounts {
+	counts.Active = counts.Cards - counts.Archived
+	return counts
+}
+
+func countsByID[ID ~string](counts []statsCount) map[ID]int {
+	byID := make(map[ID]int, len(counts))
+	for _, count := range counts {
+		byID[ID(count.ID)] = count.Count
+	}
+	return byID
+}
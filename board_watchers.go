@@ -0,0 +1,187 @@
+package libwekan
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WatchLevel est le niveau de suivi d'une board proposé par Wekan
+type WatchLevel string
+
+const (
+	WatchLevelWatching WatchLevel = "watching"
+	WatchLevelTracking WatchLevel = "tracking"
+	WatchLevelMuted    WatchLevel = "muted"
+)
+
+// WatchLevels liste les niveaux de suivi acceptés par WatchBoard
+var WatchLevels = []WatchLevel{WatchLevelWatching, WatchLevelTracking, WatchLevelMuted}
+
+// BoardWatcher est un élément de la propriété `watchers` d'une board
+type BoardWatcher struct {
+	UserID UserID     `bson:"userId" json:"userId,omitempty"`
+	Level  WatchLevel `bson:"level" json:"level,omitempty"`
+}
+
+// GetWatcher retourne le suivi de la board par l'utilisateur, vide si l'utilisateur ne la suit pas
+func (board Board) GetWatcher(userID UserID) BoardWatcher {
+	for _, watcher := range board.Watchers {
+		if watcher.UserID == userID {
+			return watcher
+		}
+	}
+	return BoardWatcher{}
+}
+
+// WatchBoard abonne un membre de la board aux notifications avec le niveau donné, ou modifie son niveau
+func (wekan *Wekan) WatchBoard(ctx context.Context, boardID BoardID, userID UserID, level WatchLevel) (err error) {
+	ctx, end := wekan.observe(ctx, "WatchBoard", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	if !contains(WatchLevels, level) {
+		return InvalidValueError{"level", string(level)}
+	}
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	if board.GetMember(userID) == (BoardMember{}) {
+		return UserIsNotMemberError{userID}
+	}
+
+	watcher := board.GetWatcher(userID)
+	if watcher.Level == level {
+		return NothingDoneError{}
+	}
+	// le filtre empêche un second abonnement si l'utilisateur s'est abonné depuis la lecture de la board
+	filter := bson.M{"_id": boardID, "watchers.userId": bson.M{"$ne": userID}}
+	update := bson.M{"$push": bson.M{"watchers": BoardWatcher{userID, level}}}
+	var opts []*options.UpdateOptions
+	if watcher != (BoardWatcher{}) {
+		filter = bson.M{"_id": boardID}
+		update = bson.M{"$set": bson.M{"watchers.$[watcher].level": level}}
+		opts = append(opts, &options.UpdateOptions{
+			ArrayFilters: &options.ArrayFilters{
+				Filters: bson.A{bson.M{"watcher.userId": userID}}},
+		})
+	}
+	stats, err := wekan.db.Collection("boards").UpdateOne(ctx, filter, update, opts...)
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	if stats.ModifiedCount == 0 {
+		return NothingDoneError{}
+	}
+	return nil
+}
+
+// UnwatchBoard désabonne l'utilisateur des notifications de la board
+func (wekan *Wekan) UnwatchBoard(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "UnwatchBoard", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	if err := boardID.Check(ctx, wekan); err != nil {
+		return err
+	}
+	stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+		bson.M{"_id": boardID},
+		bson.M{"$pull": bson.M{"watchers": bson.M{"userId": userID}}},
+	)
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	if stats.ModifiedCount == 0 {
+		return NothingDoneError{}
+	}
+	return nil
+}
+
+// StarBoard ajoute la board aux favoris de l'utilisateur et incrémente le compteur `stars` de la board
+func (wekan *Wekan) StarBoard(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "StarBoard", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	return wekan.updateStar(ctx, boardID, userID, true)
+}
+
+// UnstarBoard retire la board des favoris de l'utilisateur et décrémente le compteur `stars` de la board
+func (wekan *Wekan) UnstarBoard(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "UnstarBoard", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	return wekan.updateStar(ctx, boardID, userID, false)
+}
+
+// updateStar modifie dans une transaction le profil de l'utilisateur puis, s'il a changé, le compteur de la board
+func (wekan *Wekan) updateStar(ctx context.Context, boardID BoardID, userID UserID, star bool) error {
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	if err := boardID.Check(ctx, wekan); err != nil {
+		return err
+	}
+	if err := userID.Check(ctx, wekan); err != nil {
+		return err
+	}
+	userFilter := bson.M{"_id": userID, "profile.starredBoards": bson.M{"$ne": boardID}}
+	userUpdate := bson.M{"$addToSet": bson.M{"profile.starredBoards": boardID}}
+	boardFilter := bson.M{"_id": boardID}
+	increment := 1
+	if !star {
+		userFilter["profile.starredBoards"] = boardID
+		userUpdate = bson.M{"$pull": bson.M{"profile.starredBoards": boardID}}
+		// le compteur n'est jamais négatif, même s'il était déjà incohérent avec les profils
+		boardFilter["stars"] = bson.M{"$gt": 0}
+		increment = -1
+	}
+	userUpdate["$set"] = bson.M{"modifiedAt": toMongoTime(time.Now())}
+
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		stats, err := wekan.db.Collection("users").UpdateOne(ctx, userFilter, userUpdate)
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		if stats.ModifiedCount == 0 {
+			return NothingDoneError{}
+		}
+		_, err = wekan.db.Collection("boards").UpdateOne(ctx, boardFilter, bson.M{"$inc": bson.M{"stars": increment}})
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		return nil
+	})
+}
+
+// InviteToBoard rend l'utilisateur membre actif de la board et ajoute la board aux invitations de son profil,
+// comme le fait l'invitation depuis l'interface de Wekan
+func (wekan *Wekan) InviteToBoard(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "InviteToBoard", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		joined, err := wekan.ensureUserIsActiveBoardMember(ctx, boardID, userID)
+		if err != nil {
+			return err
+		}
+		stats, err := wekan.db.Collection("users").UpdateOne(ctx,
+			bson.M{"_id": userID, "profile.invitedBoards": bson.M{"$ne": boardID}},
+			bson.M{
+				"$addToSet": bson.M{"profile.invitedBoards": boardID},
+				"$set":      bson.M{"modifiedAt": toMongoTime(time.Now())},
+			})
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		if !joined && stats.ModifiedCount == 0 {
+			return NothingDoneError{}
+		}
+		return nil
+	})
+}
@@ -2,6 +2,8 @@ package libwekan
 
 import (
 	"context"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,44 +15,44 @@ import (
 
 // Board représente un objet de la collection `boards`
 type Board struct {
-	ID                         BoardID       `bson:"_id" json:"_id,omitempty"`
-	Title                      BoardTitle    `bson:"title" json:"title,omitempty"`
-	Permission                 string        `bson:"permission" json:"permission,omitempty"`
-	Sort                       float64       `bson:"sort" json:"sort,omitempty"`
-	Archived                   bool          `bson:"archived" json:"archived,omitempty"`
-	CreatedAt                  time.Time     `bson:"createdAt" json:"createdAt,omitempty"`
-	ModifiedAt                 time.Time     `bson:"modifiedAt" json:"modifiedAt,omitempty"`
-	Stars                      int           `bson:"stars" json:"stars,omitempty"`
-	Labels                     []BoardLabel  `bson:"labels" json:"labels,omitempty"`
-	Members                    []BoardMember `bson:"members" json:"members,omitempty"`
-	Color                      string        `bson:"color" json:"color,omitempty"`
-	SubtasksDefaultBoardId     *string       `bson:"subtasksDefaultBoardId" json:"subtasksDefaultBoardId,omitempty"`
-	SubtasksDefaultListId      *string       `bson:"subtasksDefaultListId" json:"subtasksDefaultListId,omitempty"`
-	DateSettingsDefaultBoardId *string       `bson:"dateSettingsDefaultBoardId" json:"dateSettingsDefaultBoardId,omitempty"`
-	DateSettingsDefaultListId  *string       `bson:"dateSettingsDefaultListId" json:"dateSettingsDefaultListId,omitempty"`
-	AllowsSubtasks             bool          `bson:"allowsSubtasks" json:"allowsSubtasks,omitempty"`
-	AllowsAttachments          bool          `json:"allowsAttachments"`
-	AllowsChecklists           bool          `json:"allowsChecklists"`
-	AllowsComments             bool          `json:"allowsComments"`
-	AllowsDescriptionTitle     bool          `json:"allowsDescriptionTitle"`
-	AllowsDescriptionText      bool          `json:"allowsDescriptionText"`
-	AllowsActivities           bool          `json:"allowsActivities"`
-	AllowsLabels               bool          `json:"allowsLabels"`
-	AllowsAssignee             bool          `json:"allowsAssignee"`
-	AllowsMembers              bool          `json:"allowsMembers"`
-	AllowsRequestedBy          bool          `json:"allowsRequestedBy"`
-	AllowsAssignedBy           bool          `json:"allowsAssignedBy"`
-	AllowsReceivedDate         bool          `json:"allowsReceivedDate"`
-	AllowsStartDate            bool          `json:"allowsStartDate"`
-	AllowsEndDate              bool          `json:"allowsEndDate"`
-	AllowsDueDate              bool          `json:"allowsDueDate"`
-	PresentParentTask          string        `bson:"presentParentTask" json:"presentParentTask,omitempty"`
-	IsOvertime                 bool          `bson:"isOvertime" json:"isOvertime,omitempty"`
-	Type                       string        `bson:"type" json:"type,omitempty"`
-	Slug                       BoardSlug     `bson:"slug" json:"slug,omitempty"`
-	Watchers                   []interface{} `bson:"watchers" json:"watchers,omitempty"`
-	AllowsCardNumber           bool          `bson:"allowsCardNumber" json:"allowsCardNumber,omitempty"`
-	AllowsShowLists            bool          `bson:"allowsShowLists" json:"allowsShowLists,omitempty"`
+	ID                         BoardID        `bson:"_id" json:"_id,omitempty"`
+	Title                      BoardTitle     `bson:"title" json:"title,omitempty"`
+	Permission                 string         `bson:"permission" json:"permission,omitempty"`
+	Sort                       float64        `bson:"sort" json:"sort,omitempty"`
+	Archived                   bool           `bson:"archived" json:"archived,omitempty"`
+	CreatedAt                  time.Time      `bson:"createdAt" json:"createdAt,omitempty"`
+	ModifiedAt                 time.Time      `bson:"modifiedAt" json:"modifiedAt,omitempty"`
+	Stars                      int            `bson:"stars" json:"stars,omitempty"`
+	Labels                     []BoardLabel   `bson:"labels" json:"labels,omitempty"`
+	Members                    []BoardMember  `bson:"members" json:"members,omitempty"`
+	Color                      string         `bson:"color" json:"color,omitempty"`
+	SubtasksDefaultBoardId     *string        `bson:"subtasksDefaultBoardId" json:"subtasksDefaultBoardId,omitempty"`
+	SubtasksDefaultListId      *string        `bson:"subtasksDefaultListId" json:"subtasksDefaultListId,omitempty"`
+	DateSettingsDefaultBoardId *string        `bson:"dateSettingsDefaultBoardId" json:"dateSettingsDefaultBoardId,omitempty"`
+	DateSettingsDefaultListId  *string        `bson:"dateSettingsDefaultListId" json:"dateSettingsDefaultListId,omitempty"`
+	AllowsSubtasks             bool           `bson:"allowsSubtasks" json:"allowsSubtasks,omitempty"`
+	AllowsAttachments          bool           `bson:"allowsAttachments" json:"allowsAttachments"`
+	AllowsChecklists           bool           `bson:"allowsChecklists" json:"allowsChecklists"`
+	AllowsComments             bool           `bson:"allowsComments" json:"allowsComments"`
+	AllowsDescriptionTitle     bool           `bson:"allowsDescriptionTitle" json:"allowsDescriptionTitle"`
+	AllowsDescriptionText      bool           `bson:"allowsDescriptionText" json:"allowsDescriptionText"`
+	AllowsActivities           bool           `bson:"allowsActivities" json:"allowsActivities"`
+	AllowsLabels               bool           `bson:"allowsLabels" json:"allowsLabels"`
+	AllowsAssignee             bool           `bson:"allowsAssignee" json:"allowsAssignee"`
+	AllowsMembers              bool           `bson:"allowsMembers" json:"allowsMembers"`
+	AllowsRequestedBy          bool           `bson:"allowsRequestedBy" json:"allowsRequestedBy"`
+	AllowsAssignedBy           bool           `bson:"allowsAssignedBy" json:"allowsAssignedBy"`
+	AllowsReceivedDate         bool           `bson:"allowsReceivedDate" json:"allowsReceivedDate"`
+	AllowsStartDate            bool           `bson:"allowsStartDate" json:"allowsStartDate"`
+	AllowsEndDate              bool           `bson:"allowsEndDate" json:"allowsEndDate"`
+	AllowsDueDate              bool           `bson:"allowsDueDate" json:"allowsDueDate"`
+	PresentParentTask          string         `bson:"presentParentTask" json:"presentParentTask,omitempty"`
+	IsOvertime                 bool           `bson:"isOvertime" json:"isOvertime,omitempty"`
+	Type                       string         `bson:"type" json:"type,omitempty"`
+	Slug                       BoardSlug      `bson:"slug" json:"slug,omitempty"`
+	Watchers                   []BoardWatcher `bson:"watchers" json:"watchers,omitempty"`
+	AllowsCardNumber           bool           `bson:"allowsCardNumber" json:"allowsCardNumber,omitempty"`
+	AllowsShowLists            bool           `bson:"allowsShowLists" json:"allowsShowLists,omitempty"`
 }
 
 type BoardLabelID string
@@ -113,9 +115,11 @@ func (board Board) GetLabelByID(id BoardLabelID) BoardLabel {
 }
 
 // GetBoardFromSlug retourne l'objet board à partir du champ .slug
-func (wekan *Wekan) GetBoardFromSlug(ctx context.Context, slug BoardSlug) (Board, error) {
+func (wekan *Wekan) GetBoardFromSlug(ctx context.Context, slug BoardSlug) (_ Board, err error) {
+	ctx, end := wekan.observe(ctx, "GetBoardFromSlug", Targets{"slug": string(slug)})
+	defer end(&err)
 	var board Board
-	err := wekan.db.Collection("boards").FindOne(ctx, bson.M{"slug": slug}).Decode(&board)
+	err = wekan.db.Collection("boards").FindOne(ctx, bson.M{"slug": slug}).Decode(&board)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return Board{}, boardNotFoundWithSlug(slug)
@@ -126,9 +130,11 @@ func (wekan *Wekan) GetBoardFromSlug(ctx context.Context, slug BoardSlug) (Board
 }
 
 // GetBoardFromTitle retourne l'objet board à partir du champ title
-func (wekan *Wekan) GetBoardFromTitle(ctx context.Context, title BoardTitle) (Board, error) {
+func (wekan *Wekan) GetBoardFromTitle(ctx context.Context, title BoardTitle) (_ Board, err error) {
+	ctx, end := wekan.observe(ctx, "GetBoardFromTitle", Targets{"title": string(title)})
+	defer end(&err)
 	var board Board
-	err := wekan.db.Collection("boards").FindOne(ctx, bson.M{"title": title}).Decode(&board)
+	err = wekan.db.Collection("boards").FindOne(ctx, bson.M{"title": title}).Decode(&board)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return Board{}, boardNotFoundWithTitle(title)
@@ -139,7 +145,9 @@ func (wekan *Wekan) GetBoardFromTitle(ctx context.Context, title BoardTitle) (Bo
 }
 
 // GetBoardFromID retourne l'objet board à partir du champ ._id
-func (wekan *Wekan) GetBoardFromID(ctx context.Context, id BoardID) (Board, error) {
+func (wekan *Wekan) GetBoardFromID(ctx context.Context, id BoardID) (_ Board, err error) {
+	ctx, end := wekan.observe(ctx, "GetBoardFromID", Targets{"boardID": string(id)})
+	defer end(&err)
 	var board Board
 	if err := wekan.db.Collection("boards").FindOne(ctx, bson.M{"_id": id}).Decode(&board); err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -170,8 +178,10 @@ func (board Board) UserIsActiveMember(user User) bool {
 	return board.GetMember(user.ID).IsActive
 }
 
-// AddMemberToBoard ajoute un objet BoardMember sur la board
-func (wekan *Wekan) AddMemberToBoard(ctx context.Context, boardID BoardID, boardMember BoardMember) error {
+// AddMemberToBoard ajoute un objet BoardMember sur la board, l'activité est insérée dans la même transaction si le membre est actif
+func (wekan *Wekan) AddMemberToBoard(ctx context.Context, boardID BoardID, boardMember BoardMember) (err error) {
+	ctx, end := wekan.observe(ctx, "AddMemberToBoard", Targets{"boardID": string(boardID), "userID": string(boardMember.UserID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -180,39 +190,38 @@ func (wekan *Wekan) AddMemberToBoard(ctx context.Context, boardID BoardID, board
 		return err
 	}
 
-	toInsertBoardMember := boardMember
-	// l'utilisateur est activé par la méthode EnableBoardMember pour prendre en charge l'insertion de l'activity
-	toInsertBoardMember.IsActive = false
-
-	_, err := wekan.db.Collection("boards").UpdateOne(ctx,
-		bson.M{"_id": boardID},
-		bson.M{
-			"$push": bson.M{
-				"members": toInsertBoardMember,
-			},
-		})
-	if err != nil {
-		return UnexpectedMongoError{err}
-	}
-
-	if boardMember.IsActive {
-		err = wekan.EnableBoardMember(ctx, boardID, boardMember.UserID)
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+			bson.M{"_id": boardID},
+			bson.M{
+				"$push": bson.M{
+					"members": boardMember,
+				},
+				"$set": bson.M{"modifiedAt": toMongoTime(time.Now())},
+			})
 		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		if stats.ModifiedCount == 1 && boardMember.IsActive {
+			_, err = wekan.insertActivity(ctx, newActivityAddBoardMember(wekan.ActorID(), boardMember.UserID, boardID))
 			return err
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // EnableBoardMember active l'utilisateur dans la propriété `member` d'une board
-func (wekan *Wekan) EnableBoardMember(ctx context.Context, boardID BoardID, userID UserID) error {
+func (wekan *Wekan) EnableBoardMember(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "EnableBoardMember", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
+	// le filtre sur l'état du membre évite de modifier modifiedAt et d'insérer une activité lorsqu'il est déjà actif
 	updateResults, err := wekan.db.Collection("boards").UpdateOne(ctx,
-		bson.M{"_id": boardID},
+		bson.M{"_id": boardID, "members": bson.M{"$elemMatch": bson.M{"userId": userID, "isActive": false}}},
 		bson.M{
-			"$set": bson.M{"members.$[member].isActive": true},
+			"$set": bson.M{"members.$[member].isActive": true, "modifiedAt": toMongoTime(time.Now())},
 		},
 		&options.UpdateOptions{
 			ArrayFilters: &options.ArrayFilters{
@@ -223,15 +232,17 @@ func (wekan *Wekan) EnableBoardMember(ctx context.Context, boardID BoardID, user
 		return UnexpectedMongoError{err}
 	}
 	if updateResults.ModifiedCount == 1 {
-		activity := newActivityAddBoardMember(wekan.adminUserID, userID, boardID)
-		_, err = wekan.insertActivity(context.Background(), activity)
+		activity := newActivityAddBoardMember(wekan.ActorID(), userID, boardID)
+		_, err = wekan.insertActivity(ctx, activity)
 		return errors.Wrap(err, "erreur pendant l'insertion d'une activité")
 	}
 	return nil
 }
 
 // DisableBoardMember desactive l'utilisateur dans la propriété `member` d'une board
-func (wekan *Wekan) DisableBoardMember(ctx context.Context, boardID BoardID, userID UserID) error {
+func (wekan *Wekan) DisableBoardMember(ctx context.Context, boardID BoardID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "DisableBoardMember", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -240,9 +251,10 @@ func (wekan *Wekan) DisableBoardMember(ctx context.Context, boardID BoardID, use
 			ProtectedUserError{userID},
 		}
 	}
-	updateStats, err := wekan.db.Collection("boards").UpdateOne(ctx, bson.M{"_id": boardID},
+	updateStats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+		bson.M{"_id": boardID, "members": bson.M{"$elemMatch": bson.M{"userId": userID, "isActive": true}}},
 		bson.M{
-			"$set": bson.M{"members.$[member].isActive": false},
+			"$set": bson.M{"members.$[member].isActive": false, "modifiedAt": toMongoTime(time.Now())},
 		},
 		&options.UpdateOptions{
 			ArrayFilters: &options.ArrayFilters{
@@ -253,15 +265,23 @@ func (wekan *Wekan) DisableBoardMember(ctx context.Context, boardID BoardID, use
 		return UnexpectedMongoError{err}
 	}
 	if updateStats.ModifiedCount == 1 {
-		activity := newActivityRemoveBoardMember(wekan.adminUserID, userID, boardID)
-		_, err = wekan.insertActivity(context.Background(), activity)
+		activity := newActivityRemoveBoardMember(wekan.ActorID(), userID, boardID)
+		_, err = wekan.insertActivity(ctx, activity)
 		return err
 	}
 	return nil
 }
 
 // EnsureUserIsActiveBoardMember fait en sorte de rendre l'utilisateur participant et actif à une board
-func (wekan *Wekan) EnsureUserIsActiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
+func (wekan *Wekan) EnsureUserIsActiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureUserIsActiveBoardMember", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureUserIsActiveBoardMember(ctx, boardID, userID)
+	})
+}
+
+func (wekan *Wekan) ensureUserIsActiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return false, err
 	}
@@ -283,7 +303,15 @@ func (wekan *Wekan) EnsureUserIsActiveBoardMember(ctx context.Context, boardID B
 }
 
 // EnsureUserIsInactiveBoardMember fait en sorte de désactiver un utilisateur sur une board lorsqu'il est participant
-func (wekan *Wekan) EnsureUserIsInactiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
+func (wekan *Wekan) EnsureUserIsInactiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureUserIsInactiveBoardMember", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureUserIsInactiveBoardMember(ctx, boardID, userID)
+	})
+}
+
+func (wekan *Wekan) ensureUserIsInactiveBoardMember(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return false, err
 	}
@@ -301,7 +329,15 @@ func (wekan *Wekan) EnsureUserIsInactiveBoardMember(ctx context.Context, boardID
 	return false, nil
 }
 
-func (wekan *Wekan) EnsureUserIsBoardAdmin(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
+func (wekan *Wekan) EnsureUserIsBoardAdmin(ctx context.Context, boardID BoardID, userID UserID) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureUserIsBoardAdmin", Targets{"boardID": string(boardID), "userID": string(userID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureUserIsBoardAdmin(ctx, boardID, userID)
+	})
+}
+
+func (wekan *Wekan) ensureUserIsBoardAdmin(ctx context.Context, boardID BoardID, userID UserID) (bool, error) {
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return false, err
 	}
@@ -371,12 +407,14 @@ func BuildBoard(title string, slug string, boardType string) Board {
 	return board
 }
 
-func (wekan *Wekan) InsertBoard(ctx context.Context, board Board) error {
+func (wekan *Wekan) InsertBoard(ctx context.Context, board Board) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertBoard", Targets{"boardID": string(board.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
 
-	_, err := wekan.insertActivity(ctx, newActivityCreateBoard(wekan.adminUserID, board.ID))
+	_, err = wekan.insertActivity(ctx, newActivityCreateBoard(wekan.ActorID(), board.ID))
 	if err != nil {
 		return err
 	}
@@ -387,7 +425,9 @@ func (wekan *Wekan) InsertBoard(ctx context.Context, board Board) error {
 	return nil
 }
 
-func (wekan *Wekan) InsertBoardLabel(ctx context.Context, board Board, boardLabel BoardLabel) error {
+func (wekan *Wekan) InsertBoardLabel(ctx context.Context, board Board, boardLabel BoardLabel) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertBoardLabel", Targets{"boardID": string(board.ID), "boardLabelID": string(boardLabel.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -414,7 +454,9 @@ func (wekan *Wekan) InsertBoardLabel(ctx context.Context, board Board, boardLabe
 }
 
 // SelectBoardsFromMemberID retourne les boards où on trouve le memberID passé en paramètre
-func (wekan *Wekan) SelectBoardsFromMemberID(ctx context.Context, memberID UserID) ([]Board, error) {
+func (wekan *Wekan) SelectBoardsFromMemberID(ctx context.Context, memberID UserID) (_ []Board, err error) {
+	ctx, end := wekan.observe(ctx, "SelectBoardsFromMemberID", Targets{"memberID": string(memberID)})
+	defer end(&err)
 	var boards []Board
 	query := bson.M{
 		"members.userId": memberID,
@@ -431,7 +473,9 @@ func (wekan *Wekan) SelectBoardsFromMemberID(ctx context.Context, memberID UserI
 }
 
 // SelectDomainBoards retourne les boards correspondant à la slugDomainRegexp
-func (wekan *Wekan) SelectDomainBoards(ctx context.Context) ([]Board, error) {
+func (wekan *Wekan) SelectDomainBoards(ctx context.Context) (_ []Board, err error) {
+	ctx, end := wekan.observe(ctx, "SelectDomainBoards", nil)
+	defer end(&err)
 	var boards []Board
 	query := bson.M{
 		"slug": primitive.Regex{Pattern: wekan.slugDomainRegexp, Options: "i"},
@@ -447,6 +491,12 @@ func (wekan *Wekan) SelectDomainBoards(ctx context.Context) ([]Board, error) {
 	return boards, nil
 }
 
+// inDomain est vrai lorsque le slug correspond à la slugDomainRegexp, sans tenir compte de la casse comme les requêtes
+func (wekan *Wekan) inDomain(slug BoardSlug) bool {
+	matched, err := regexp.MatchString("(?i)"+wekan.slugDomainRegexp, string(slug))
+	return err == nil && matched
+}
+
 // HasLabelName est vrai lorsque la board dispose du labelName passé en paramètre
 func (board Board) HasLabelName(name BoardLabelName) bool {
 	for _, label := range board.Labels {
@@ -474,3 +524,133 @@ func (board Board) HasAllLabelNames(names []BoardLabelName) bool {
 	}
 	return true
 }
+
+// BoardColors liste les couleurs de board proposées par Wekan
+var BoardColors = []string{
+	"belize", "nephritis", "pomegranate", "pumpkin", "wisteria", "moderatepink", "strongcyan",
+	"limegreen", "midnight", "dark", "relax", "corteza", "clearblue", "natural", "modern", "moderndark", "exodark",
+}
+
+// Permissions d'une board
+const (
+	BoardPrivate = "private"
+	BoardPublic  = "public"
+)
+
+// BoardFeature désigne une fonctionnalité d'une board activable par SetBoardFeature, la valeur est le nom du champ mongodb
+type BoardFeature string
+
+const (
+	FeatureSubtasks         BoardFeature = "allowsSubtasks"
+	FeatureAttachments      BoardFeature = "allowsAttachments"
+	FeatureChecklists       BoardFeature = "allowsChecklists"
+	FeatureComments         BoardFeature = "allowsComments"
+	FeatureDescriptionTitle BoardFeature = "allowsDescriptionTitle"
+	FeatureDescriptionText  BoardFeature = "allowsDescriptionText"
+	FeatureActivities       BoardFeature = "allowsActivities"
+	FeatureLabels           BoardFeature = "allowsLabels"
+	FeatureAssignee         BoardFeature = "allowsAssignee"
+	FeatureMembers          BoardFeature = "allowsMembers"
+	FeatureRequestedBy      BoardFeature = "allowsRequestedBy"
+	FeatureAssignedBy       BoardFeature = "allowsAssignedBy"
+	FeatureReceivedDate     BoardFeature = "allowsReceivedDate"
+	FeatureStartDate        BoardFeature = "allowsStartDate"
+	FeatureEndDate          BoardFeature = "allowsEndDate"
+	FeatureDueDate          BoardFeature = "allowsDueDate"
+	FeatureCardNumber       BoardFeature = "allowsCardNumber"
+	FeatureShowLists        BoardFeature = "allowsShowLists"
+)
+
+var boardFeatures = []BoardFeature{
+	FeatureSubtasks, FeatureAttachments, FeatureChecklists, FeatureComments, FeatureDescriptionTitle, FeatureDescriptionText,
+	FeatureActivities, FeatureLabels, FeatureAssignee, FeatureMembers, FeatureRequestedBy, FeatureAssignedBy,
+	FeatureReceivedDate, FeatureStartDate, FeatureEndDate, FeatureDueDate, FeatureCardNumber, FeatureShowLists,
+}
+
+// RenameBoard modifie le titre de la board, le slug n'est pas modifié
+func (wekan *Wekan) RenameBoard(ctx context.Context, boardID BoardID, title BoardTitle) (err error) {
+	ctx, end := wekan.observe(ctx, "RenameBoard", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	if strings.TrimSpace(string(title)) == "" {
+		return InvalidValueError{"title", string(title)}
+	}
+	return wekan.updateBoard(ctx, boardID, "title", title, nil, "")
+}
+
+// ArchiveBoard place la board dans la corbeille de Wekan
+func (wekan *Wekan) ArchiveBoard(ctx context.Context, boardID BoardID) (err error) {
+	ctx, end := wekan.observe(ctx, "ArchiveBoard", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	return wekan.updateBoard(ctx, boardID, "archived", true, bson.M{"archivedAt": toMongoTime(time.Now())}, "archivedBoard")
+}
+
+// UnarchiveBoard restaure une board archivée
+func (wekan *Wekan) UnarchiveBoard(ctx context.Context, boardID BoardID) (err error) {
+	ctx, end := wekan.observe(ctx, "UnarchiveBoard", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	return wekan.updateBoard(ctx, boardID, "archived", false, nil, "restoredBoard")
+}
+
+// SetBoardColor modifie la couleur de la board, qui doit figurer dans BoardColors
+func (wekan *Wekan) SetBoardColor(ctx context.Context, boardID BoardID, color string) (err error) {
+	ctx, end := wekan.observe(ctx, "SetBoardColor", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	if !contains(BoardColors, color) {
+		return InvalidValueError{"color", color}
+	}
+	return wekan.updateBoard(ctx, boardID, "color", color, nil, "")
+}
+
+// SetBoardPermission rend la board privée (BoardPrivate) ou publique (BoardPublic)
+func (wekan *Wekan) SetBoardPermission(ctx context.Context, boardID BoardID, permission string) (err error) {
+	ctx, end := wekan.observe(ctx, "SetBoardPermission", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	if permission != BoardPrivate && permission != BoardPublic {
+		return InvalidValueError{"permission", permission}
+	}
+	return wekan.updateBoard(ctx, boardID, "permission", permission, nil, "")
+}
+
+// SetBoardFeature active ou désactive une fonctionnalité de la board
+func (wekan *Wekan) SetBoardFeature(ctx context.Context, boardID BoardID, feature BoardFeature, enabled bool) (err error) {
+	ctx, end := wekan.observe(ctx, "SetBoardFeature", Targets{"boardID": string(boardID), "feature": string(feature)})
+	defer end(&err)
+	if !contains(boardFeatures, feature) {
+		return InvalidValueError{"feature", string(feature)}
+	}
+	return wekan.updateBoard(ctx, boardID, string(feature), enabled, nil, "")
+}
+
+// updateBoard affecte value au champ field de la board, ainsi que les valeurs de extra, met à jour modifiedAt
+// et insère l'activité dans la même transaction. Wekan n'a pas d'activité pour le titre, la couleur, la visibilité
+// ou les fonctionnalités d'une board : activityType est alors vide et aucune activité n'est insérée.
+// NothingDoneError est retournée lorsque le champ a déjà la valeur demandée.
+func (wekan *Wekan) updateBoard(ctx context.Context, boardID BoardID, field string, value interface{}, extra bson.M, activityType string) error {
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	if _, err := wekan.GetBoardFromID(ctx, boardID); err != nil {
+		return err
+	}
+	set := bson.M{field: value, "modifiedAt": toMongoTime(time.Now())}
+	for key, extraValue := range extra {
+		set[key] = extraValue
+	}
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		stats, err := wekan.db.Collection("boards").UpdateOne(ctx,
+			bson.M{"_id": boardID, field: bson.M{"$ne": value}},
+			bson.M{"$set": set},
+		)
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
+		if stats.ModifiedCount == 0 {
+			return NothingDoneError{}
+		}
+		if activityType == "" {
+			return nil
+		}
+		_, err = wekan.insertActivity(ctx, newActivityUpdateBoard(wekan.ActorID(), boardID, activityType))
+		return err
+	})
+}
@@ -464,6 +464,63 @@ func TestBoards_DisableBoardMember_cant_disable_admin(t *testing.T) {
 	ass.ErrorIs(err, ProtectedUserError{admin.ID})
 }
 
+func TestBoards_updateBoard(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	board, _, _ := createTestBoard(t, "", 0, 0)
+
+	// WHEN
+	errRename := wekan.RenameBoard(ctx, board.ID, "nouveau titre")
+	errFeature := wekan.SetBoardFeature(ctx, board.ID, FeatureSubtasks, false)
+	errNothing := wekan.SetBoardPermission(ctx, board.ID, BoardPrivate)
+
+	// THEN
+	ass.NoError(errRename)
+	ass.NoError(errFeature)
+	ass.ErrorIs(errNothing, ErrNothingDone)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.Equal(BoardTitle("nouveau titre"), actualBoard.Title)
+	ass.False(actualBoard.AllowsSubtasks)
+	// Wekan n'a pas d'activité pour le titre ou les fonctionnalités d'une board
+	activities, _ := wekan.SelectActivitiesFromQuery(ctx, bson.M{"boardId": board.ID, "activityType": bson.M{"$in": bson.A{"renamedBoard", "changedBoardFeature"}}})
+	ass.Empty(activities)
+}
+
+func TestBoards_DeleteBoard(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	board, _, lists := createTestBoard(t, "", 1, 2)
+
+	// WHEN
+	deletion, err := wekan.DeleteBoard(ctx, board.ID, DeleteBoardOptions{Force: true})
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal(1, deletion.Deleted["boards"])
+	ass.Equal(2, deletion.Deleted["lists"])
+	ass.Equal(1, deletion.Deleted["swimlanes"])
+	_, err = wekan.GetBoardFromID(ctx, board.ID)
+	ass.ErrorIs(err, ErrNotFound)
+	_, err = wekan.GetListFromID(ctx, lists[0].ID)
+	ass.ErrorIs(err, ErrNotFound)
+}
+
+func TestBoards_BoardStats(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	board, swimlanes, lists := createTestBoard(t, "", 1, 1)
+	createTestCard(t, wekan.adminUserID, &board.ID, &(swimlanes[0].ID), &(lists[0].ID))
+
+	// WHEN
+	stats, err := wekan.BoardStats(ctx, board.ID, StatsOptions{})
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal(CardCounts{Cards: 1, Active: 1}, stats.CardCounts)
+	ass.Equal(map[ListID]int{lists[0].ID: 1}, stats.ByList)
+	ass.Equal(map[SwimlaneID]int{swimlanes[0].ID: 1}, stats.BySwimlane)
+}
+
 func createTestBoard(t *testing.T, suffix string, swimlanesCount int, listsCount int) (Board, []Swimlane, []List) {
 	ctx := context.Background()
 	board := BuildBoard(t.Name()+suffix, t.Name()+suffix, "board")
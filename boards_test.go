@@ -210,3 +210,24 @@ func TestBoard_HasAnyLabelNames_MissingLabel(t *testing.T) {
 
 	assert.False(t, board.HasAnyLabelNames([]BoardLabelName{name2}))
 }
+
+func TestBoardMember_Role(t *testing.T) {
+	ass := assert.New(t)
+	for _, role := range BoardRoles {
+		member := BoardMember{UserID: "toto", IsActive: true, IsWorker: true}.WithRole(role)
+		ass.Equal(role, member.Role())
+		ass.True(member.IsActive)
+	}
+	ass.Equal(RoleAdmin, BoardMember{IsAdmin: true, IsCommentOnly: true}.Role())
+}
+
+func TestBoard_ActiveAdmins(t *testing.T) {
+	board := Board{
+		Members: []BoardMember{
+			{UserID: "admin", IsAdmin: true, IsActive: true},
+			{UserID: "inactif", IsAdmin: true},
+			{UserID: "membre", IsActive: true},
+		},
+	}
+	assert.Equal(t, []UserID{"admin"}, board.ActiveAdmins())
+}
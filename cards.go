@@ -3,7 +3,6 @@ package libwekan
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -121,7 +120,9 @@ func (card *Card) AddMember(memberID UserID) {
 	}
 }
 
-func (wekan *Wekan) SelectCardsFromQuery(ctx context.Context, query bson.M) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromQuery(ctx context.Context, query bson.M) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromQuery", nil)
+	defer end(&err)
 	var cards []Card
 	cur, err := wekan.db.Collection("cards").Find(ctx, query)
 	if err != nil {
@@ -134,31 +135,42 @@ func (wekan *Wekan) SelectCardsFromQuery(ctx context.Context, query bson.M) ([]C
 	return cards, nil
 }
 
-func (wekan *Wekan) SelectCardsFromUserID(ctx context.Context, userID UserID) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromUserID(ctx context.Context, userID UserID) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromUserID", Targets{"userID": string(userID)})
+	defer end(&err)
 	return wekan.SelectCardsFromQuery(ctx, bson.M{"userId": userID})
 }
 
-func (wekan *Wekan) SelectCardsFromMemberID(ctx context.Context, userID UserID) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromMemberID(ctx context.Context, userID UserID) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromMemberID", Targets{"userID": string(userID)})
+	defer end(&err)
 	return wekan.SelectCardsFromQuery(ctx, bson.M{"members": userID})
 }
 
-func (wekan *Wekan) SelectCardsFromBoardID(ctx context.Context, boardID BoardID) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromBoardID(ctx context.Context, boardID BoardID) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromBoardID", Targets{"boardID": string(boardID)})
+	defer end(&err)
 	return wekan.SelectCardsFromQuery(ctx, bson.M{"boardId": boardID})
 }
 
-func (wekan *Wekan) SelectCardsFromSwimlaneID(ctx context.Context, swimlaneID SwimlaneID) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromSwimlaneID(ctx context.Context, swimlaneID SwimlaneID) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromSwimlaneID", Targets{"swimlaneID": string(swimlaneID)})
+	defer end(&err)
 	return wekan.SelectCardsFromQuery(ctx, bson.M{"swimlaneId": swimlaneID})
 }
 
-func (wekan *Wekan) SelectCardsFromListID(ctx context.Context, listID ListID) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromListID(ctx context.Context, listID ListID) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromListID", Targets{"listID": string(listID)})
+	defer end(&err)
 	return wekan.SelectCardsFromQuery(ctx, bson.M{"listId": listID})
 }
 
 // SelectCardsFromPipeline retourne les objets correspondant au modèle Card à partir d'un pipeline mongodb
-func (wekan *Wekan) SelectCardsFromPipeline(ctx context.Context, collection string, pipeline Pipeline) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromPipeline(ctx context.Context, collection string, pipeline Pipeline) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromPipeline", nil)
+	defer end(&err)
 	cur, err := wekan.db.Collection(collection).Aggregate(ctx, pipeline)
 	if err != nil {
-		fmt.Println(err)
 		return nil, UnexpectedMongoError{err}
 	}
 	var cards []Card
@@ -170,10 +182,11 @@ func (wekan *Wekan) SelectCardsFromPipeline(ctx context.Context, collection stri
 }
 
 // SelectCardsWithCommentsFromPipeline retourne les objets correspondant au modèle CardWithComments à partir d'un pipeline mongodb
-func (wekan *Wekan) SelectCardsWithCommentsFromPipeline(ctx context.Context, collection string, pipeline Pipeline) ([]CardWithComments, error) {
+func (wekan *Wekan) SelectCardsWithCommentsFromPipeline(ctx context.Context, collection string, pipeline Pipeline) (_ []CardWithComments, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsWithCommentsFromPipeline", nil)
+	defer end(&err)
 	cur, err := wekan.db.Collection(collection).Aggregate(ctx, pipeline)
 	if err != nil {
-		fmt.Println(err)
 		return nil, UnexpectedMongoError{err}
 	}
 	var cards []CardWithComments
@@ -184,12 +197,16 @@ func (wekan *Wekan) SelectCardsWithCommentsFromPipeline(ctx context.Context, col
 	return cards, nil
 }
 
-func (wekan *Wekan) SelectCardsFromCustomTextField(ctx context.Context, name string, value string) ([]Card, error) {
+func (wekan *Wekan) SelectCardsFromCustomTextField(ctx context.Context, name string, value string) (_ []Card, err error) {
+	ctx, end := wekan.observe(ctx, "SelectCardsFromCustomTextField", nil)
+	defer end(&err)
 	pipeline := wekan.BuildCardFromCustomTextFieldPipeline(name, value)
 	return wekan.SelectCardsFromPipeline(ctx, "customFields", pipeline)
 }
 
-func (wekan *Wekan) GetCardFromID(ctx context.Context, cardID CardID) (Card, error) {
+func (wekan *Wekan) GetCardFromID(ctx context.Context, cardID CardID) (_ Card, err error) {
+	ctx, end := wekan.observe(ctx, "GetCardFromID", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	cards, err := wekan.SelectCardsFromQuery(ctx, bson.M{"_id": cardID})
 	if err != nil {
 		return Card{}, UnexpectedMongoError{err}
@@ -203,7 +220,9 @@ func (wekan *Wekan) GetCardFromID(ctx context.Context, cardID CardID) (Card, err
 	return cards[0], nil
 }
 
-func (wekan *Wekan) GetCardWithCommentsFromID(ctx context.Context, cardID CardID) (CardWithComments, error) {
+func (wekan *Wekan) GetCardWithCommentsFromID(ctx context.Context, cardID CardID) (_ CardWithComments, err error) {
+	ctx, end := wekan.observe(ctx, "GetCardWithCommentsFromID", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	pipeline := Pipeline{
 		bson.M{
 			"$match": bson.M{
@@ -217,7 +236,7 @@ func (wekan *Wekan) GetCardWithCommentsFromID(ctx context.Context, cardID CardID
 		},
 		bson.M{
 			"$lookup": bson.M{
-				"from":         "comments",
+				"from":         wekan.schemaMapping().CommentsCollection,
 				"localField":   "card._id",
 				"foreignField": "cardId",
 				"as":           "comments",
@@ -238,7 +257,9 @@ func (wekan *Wekan) GetCardWithCommentsFromID(ctx context.Context, cardID CardID
 	return cards[0], nil
 }
 
-func (wekan *Wekan) InsertCard(ctx context.Context, card Card) error {
+func (wekan *Wekan) InsertCard(ctx context.Context, card Card) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertCard", Targets{"cardID": string(card.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -250,19 +271,26 @@ func (wekan *Wekan) InsertCard(ctx context.Context, card Card) error {
 	); err != nil {
 		return err
 	}
-	if _, err := wekan.db.Collection("cards").InsertOne(ctx, card); err != nil {
-		return UnexpectedMongoError{err}
+	if wekan.actorUserID != "" {
+		card.UserID = wekan.actorUserID
 	}
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := wekan.db.Collection("cards").InsertOne(ctx, card); err != nil {
+			return UnexpectedMongoError{err}
+		}
 
-	activity, err := wekan.newActivityCreateCardFromCard(ctx, card)
-	if err != nil {
+		activity, err := wekan.newActivityCreateCardFromCard(ctx, card)
+		if err != nil {
+			return err
+		}
+		_, err = wekan.insertActivity(ctx, activity)
 		return err
-	}
-	_, err = wekan.insertActivity(ctx, activity)
-	return err
+	})
 }
 
-func (wekan *Wekan) AddLabelToCard(ctx context.Context, cardID CardID, labelID BoardLabelID) error {
+func (wekan *Wekan) AddLabelToCard(ctx context.Context, cardID CardID, labelID BoardLabelID) (err error) {
+	ctx, end := wekan.observe(ctx, "AddLabelToCard", Targets{"cardID": string(cardID), "labelID": string(labelID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -297,7 +325,7 @@ func (wekan *Wekan) AddLabelToCard(ctx context.Context, cardID CardID, labelID B
 func (wekan *Wekan) BuildDomainCardsPipeline() Pipeline {
 	matchBoardsStage := bson.M{
 		"$match": bson.M{
-			"slug": bson.M{"$regex": wekan.slugDomainRegexp},
+			"slug": primitive.Regex{Pattern: wekan.slugDomainRegexp, Options: "i"},
 		},
 	}
 
@@ -459,7 +487,9 @@ func (wekan *Wekan) BuildCardFromCustomTextFieldsPipeline(name string, values []
 	return pipeline
 }
 
-func (wekan *Wekan) ArchiveCard(ctx context.Context, cardID CardID) error {
+func (wekan *Wekan) ArchiveCard(ctx context.Context, cardID CardID) (err error) {
+	ctx, end := wekan.observe(ctx, "ArchiveCard", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	update, err := wekan.db.Collection("cards").UpdateOne(ctx, bson.M{
 		"_id":      cardID,
 		"archived": false,
@@ -484,7 +514,9 @@ func (wekan *Wekan) ArchiveCard(ctx context.Context, cardID CardID) error {
 	return nil
 }
 
-func (wekan *Wekan) UnarchiveCard(ctx context.Context, cardID CardID) error {
+func (wekan *Wekan) UnarchiveCard(ctx context.Context, cardID CardID) (err error) {
+	ctx, end := wekan.observe(ctx, "UnarchiveCard", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	update, err := wekan.db.Collection("cards").UpdateOne(ctx, bson.M{
 		"_id":      cardID,
 		"archived": true,
@@ -520,7 +552,9 @@ func (config *Config) CustomFieldWithName(card Card, name string) string {
 }
 
 // TODO: écrire un test
-func (wekan *Wekan) UpdateCardDescription(ctx context.Context, cardID CardID, description string) error {
+func (wekan *Wekan) UpdateCardDescription(ctx context.Context, cardID CardID, description string) (err error) {
+	ctx, end := wekan.observe(ctx, "UpdateCardDescription", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	stats, err := wekan.db.Collection("cards").UpdateOne(ctx,
 		bson.M{"_id": cardID},
 		bson.M{
@@ -534,7 +568,7 @@ func (wekan *Wekan) UpdateCardDescription(ctx context.Context, cardID CardID, de
 		},
 	)
 	if stats.MatchedCount == 0 {
-		return CardNotFoundError{cardID: cardID}
+		return CardNotFoundError{CardID: cardID}
 	}
 	if stats.ModifiedCount == 0 {
 		return NothingDoneError{}
@@ -545,7 +579,16 @@ func (wekan *Wekan) UpdateCardDescription(ctx context.Context, cardID CardID, de
 	return nil
 }
 
-func (wekan *Wekan) EnsureMoveCardList(ctx context.Context, cardID CardID, listID ListID, userID UserID) error {
+func (wekan *Wekan) EnsureMoveCardList(ctx context.Context, cardID CardID, listID ListID, userID UserID) (err error) {
+	ctx, end := wekan.observe(ctx, "EnsureMoveCardList", Targets{"cardID": string(cardID), "listID": string(listID), "userID": string(userID)})
+	defer end(&err)
+	_, err = retryEnsure(ctx, wekan, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, wekan.ensureMoveCardList(ctx, cardID, listID, userID)
+	})
+	return err
+}
+
+func (wekan *Wekan) ensureMoveCardList(ctx context.Context, cardID CardID, listID ListID, userID UserID) error {
 	card, err := cardID.GetDocument(ctx, wekan)
 	if err != nil {
 		return err
@@ -557,27 +600,34 @@ func (wekan *Wekan) EnsureMoveCardList(ctx context.Context, cardID CardID, listI
 
 	// si la liste n'est pas dans cette board, on retourne une erreur
 	lists, err := wekan.SelectListsFromBoardID(ctx, card.BoardID)
+	if err != nil {
+		return err
+	}
 	listsIDs := mapSlice(lists, func(list List) ListID { return list.ID })
 	if !contains(listsIDs, listID) {
-		return ListNotFoundError{listID: listID}
+		return ListNotFoundError{ListID: listID}
 	}
 
-	// pas besoin de vérifier les stats, nous savons déjà que la liste est différente
-	_, err = wekan.db.Collection("cards").UpdateOne(ctx, bson.M{"_id": cardID}, bson.M{"$set": bson.M{"listId": listID}})
-	if err != nil {
-		return UnexpectedMongoError{err}
-	}
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		// pas besoin de vérifier les stats, nous savons déjà que la liste est différente
+		_, err := wekan.db.Collection("cards").UpdateOne(ctx, bson.M{"_id": cardID}, bson.M{"$set": bson.M{"listId": listID}})
+		if err != nil {
+			return UnexpectedMongoError{err}
+		}
 
-	// insertion de l'activité
-	activity, err := wekan.newActivityMoveCardFromMovedCard(ctx, card, userID)
-	if err != nil {
+		// insertion de l'activité
+		activity, err := wekan.newActivityMoveCardFromMovedCard(ctx, card, userID)
+		if err != nil {
+			return err
+		}
+		_, err = wekan.insertActivity(ctx, activity)
 		return err
-	}
-	_, err = wekan.insertActivity(ctx, activity)
-	return err
+	})
 }
 
-func (wekan *Wekan) SetCardEndAt(ctx context.Context, cardID CardID, endAt *time.Time) error {
+func (wekan *Wekan) SetCardEndAt(ctx context.Context, cardID CardID, endAt *time.Time) (err error) {
+	ctx, end := wekan.observe(ctx, "SetCardEndAt", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	filter := bson.M{"_id": cardID}
 	update := bson.M{"$set": bson.M{"endAt": endAt}}
 	stats, err := wekan.db.Collection("cards").UpdateOne(ctx, filter, update)
@@ -590,7 +640,9 @@ func (wekan *Wekan) SetCardEndAt(ctx context.Context, cardID CardID, endAt *time
 	return nil
 }
 
-func (wekan *Wekan) SetCardStartAt(ctx context.Context, cardID CardID, startAt *time.Time) error {
+func (wekan *Wekan) SetCardStartAt(ctx context.Context, cardID CardID, startAt *time.Time) (err error) {
+	ctx, end := wekan.observe(ctx, "SetCardStartAt", Targets{"cardID": string(cardID)})
+	defer end(&err)
 	filter := bson.M{"_id": cardID}
 	update := bson.M{"$set": bson.M{"startAt": startAt}}
 	stats, err := wekan.db.Collection("cards").UpdateOne(ctx, filter, update)
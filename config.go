@@ -20,41 +20,28 @@ type ConfigBoard struct {
 type Config struct {
 	Boards map[BoardID]ConfigBoard `bson:"boards" json:"boards,omitempty"`
 	Users  map[UserID]User         `bson:"users" json:"users,omitempty"`
+	lookup *configIndex
 }
 
+// CustomFieldID parcourt les champs personnalisés, lorsque plusieurs champs portent ce nom celui de plus petit ID est retenu.
+//
+// Deprecated: Config.CustomFieldID utilise les index d'une configuration préparée
 func (fields ConfigCustomFields) CustomFieldID(fieldName string) CardCustomFieldID {
-	for fieldID, field := range fields {
-		if field.Name == fieldName {
-			return fieldID
-		}
-	}
-	return ""
+	field, _ := fields.byName(fieldName)
+	return field.ID
 }
 
-func (config *Config) Copy() Config {
-	return *config
-}
-
-func (config *Config) GetUserByUsername(username Username) (User, bool) {
-	for _, user := range config.Users {
-		if user.Username == username {
-			return user, true
-		}
-	}
-	return User{}, false
+func (fields ConfigCustomFields) byName(name string) (CustomField, bool) {
+	return scanFirst(fields,
+		func(field CustomField) bool { return field.Name == name },
+		func(field CustomField) CardCustomFieldID { return field.ID })
 }
 
-func (config *Config) GetCardCustomFieldByName(card Card, name string) (string, bool) {
-	for _, customField := range card.CustomFields {
-		board, ok := config.Boards[card.BoardID]
-		if ok {
-			customFieldName := board.CustomFields[customField.ID]
-			if customFieldName.Name == name {
-				return customField.Value, true
-			}
-		}
-	}
-	return "", false
+// Copy retourne une copie superficielle de la configuration, non préparée puisqu'elle est destinée à être modifiée
+func (config *Config) Copy() Config {
+	copied := *config
+	copied.lookup = nil
+	return copied
 }
 
 func buildConfigPipeline(slugDomainRegexp string) []bson.M {
@@ -203,7 +190,9 @@ func buildConfigPipeline(slugDomainRegexp string) []bson.M {
 	}
 }
 
-func (wekan *Wekan) SelectConfig(ctx context.Context) (Config, error) {
+func (wekan *Wekan) SelectConfig(ctx context.Context) (_ Config, err error) {
+	ctx, end := wekan.observe(ctx, "SelectConfig", nil)
+	defer end(&err)
 	var config Config
 	if wekan.db == nil {
 		return Config{}, fmt.Errorf("impossible de sélectionner la configuration car la base de données n'est pas définie")
@@ -214,16 +203,18 @@ func (wekan *Wekan) SelectConfig(ctx context.Context) (Config, error) {
 	if err != nil {
 		return config, UnexpectedMongoError{err}
 	}
-	cur.Next(ctx)
-	err = cur.Decode(&config)
-	if err != nil {
-		fmt.Println(err)
-		return Config{}, UnexpectedMongoDecodeError{err}
+	// aucune board dans le domaine : l'agrégation ne retourne aucun document
+	if cur.Next(ctx) {
+		err = cur.Decode(&config)
+		if err != nil {
+			return Config{}, UnexpectedMongoDecodeError{err}
+		}
 	}
 	err = cur.Close(ctx)
 	if err != nil {
 		return Config{}, UnexpectedMongoDecodeError{err}
 	}
 
+	config.Prepare()
 	return config, nil
 }
@@ -0,0 +1,223 @@
+package libwekan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DefaultConfigPollInterval est l'intervalle de scrutation utilisé lorsque les change streams ne sont pas disponibles
+const DefaultConfigPollInterval = 30 * time.Second
+
+// DefaultConfigDebounce est le délai pendant lequel les modifications signalées par change stream sont regroupées
+// avant de rafraîchir la configuration, une rafale d'écritures ne provoque ainsi qu'un rafraîchissement
+const DefaultConfigDebounce = 200 * time.Millisecond
+
+// configCollections sont les collections dont le contenu est repris dans Config
+var configCollections = []string{"boards", "swimlanes", "lists", "customFields", "users"}
+
+// ConfigCache conserve le résultat de SelectConfig et le tient à jour pendant l'exécution de Run.
+// Chaque rafraîchissement construit un nouvel objet Config : les instantanés retournés par Snapshot ne sont jamais modifiés
+// et peuvent être lus sans verrou, à condition que l'appelant ne les modifie pas lui-même.
+type ConfigCache struct {
+	wekan        Wekan
+	pollInterval time.Duration
+	config       atomic.Pointer[Config]
+	version      atomic.Uint64
+	// refreshes numérote les rafraîchissements dans l'ordre de leur lancement
+	refreshes atomic.Uint64
+	mu        sync.Mutex
+	// applied est le numéro du dernier rafraîchissement pris en compte, protégé par mu comme lastError
+	applied   uint64
+	lastError error
+}
+
+// NewConfigCache charge la configuration et retourne le cache correspondant,
+// pollInterval n'est utilisé qu'en l'absence de change streams (DefaultConfigPollInterval si nul)
+func (wekan *Wekan) NewConfigCache(ctx context.Context, pollInterval time.Duration) (_ *ConfigCache, err error) {
+	ctx, end := wekan.observe(ctx, "NewConfigCache", nil)
+	defer end(&err)
+	if pollInterval <= 0 {
+		pollInterval = DefaultConfigPollInterval
+	}
+	cache := &ConfigCache{wekan: *wekan, pollInterval: pollInterval}
+	if err := cache.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Snapshot retourne la dernière configuration chargée
+func (cache *ConfigCache) Snapshot() Config {
+	return *cache.config.Load()
+}
+
+// Version est incrémentée à chaque rafraîchissement réussi
+func (cache *ConfigCache) Version() uint64 {
+	return cache.version.Load()
+}
+
+// LastError retourne l'erreur du dernier rafraîchissement, nil s'il a réussi
+func (cache *ConfigCache) LastError() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.lastError
+}
+
+// Refresh recharge la configuration, l'instantané précédent est conservé en cas d'erreur.
+// La lecture se fait sans verrou ; le résultat d'un rafraîchissement terminé après un rafraîchissement
+// lancé plus tard est ignoré, car il est moins récent que l'instantané déjà en place.
+func (cache *ConfigCache) Refresh(ctx context.Context) error {
+	refresh := cache.refreshes.Add(1)
+	config, err := cache.wekan.SelectConfig(ctx)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if refresh < cache.applied {
+		return err
+	}
+	cache.applied = refresh
+	cache.lastError = err
+	if err != nil {
+		return err
+	}
+	cache.config.Store(&config)
+	cache.version.Add(1)
+	return nil
+}
+
+// Run tient le cache à jour jusqu'à l'annulation de ctx et retourne alors ctx.Err().
+// Les modifications sont suivies par change stream, ou à défaut par scrutation des champs modifiedAt
+// lorsque le serveur ne propose pas de change streams. Un flux interrompu est rouvert,
+// toutes les pollInterval tant que sa réouverture échoue.
+// Les erreurs de rafraîchissement n'interrompent pas Run, elles sont disponibles via LastError.
+func (cache *ConfigCache) Run(ctx context.Context) error {
+	opened := false
+	for {
+		stream, err := cache.wekan.db.Watch(ctx, configCollections)
+		if err != nil && !opened {
+			return cache.poll(ctx)
+		}
+		delay := DefaultConfigDebounce
+		if err != nil {
+			cache.setLastError(UnexpectedMongoError{err})
+			delay = cache.pollInterval
+		} else {
+			opened = true
+			if err := cache.follow(ctx, stream); err != nil && ctx.Err() == nil {
+				cache.setLastError(UnexpectedMongoError{err})
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// follow rafraîchit le cache après chaque série de modifications signalées par le flux, jusqu'à sa fermeture,
+// et retourne l'erreur du flux. Les modifications survenant pendant DefaultConfigDebounce sont regroupées.
+func (cache *ConfigCache) follow(ctx context.Context, stream ChangeStream) error {
+	followCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer stream.Close(context.Background()) // nolint:errcheck
+
+	changes := make(chan struct{}, 1)
+	streamErr := make(chan error, 1)
+	go func() {
+		defer close(changes)
+		for stream.Next(followCtx) {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+		streamErr <- stream.Err()
+	}()
+
+	// les modifications survenues entre le chargement initial et l'ouverture du flux ne sont pas signalées
+	_ = cache.Refresh(ctx)
+	for {
+		if _, ok := <-changes; !ok {
+			return <-streamErr
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(DefaultConfigDebounce):
+		}
+		// la notification reçue pendant l'attente est couverte par ce rafraîchissement
+		select {
+		case <-changes:
+		default:
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		_ = cache.Refresh(ctx)
+	}
+}
+
+func (cache *ConfigCache) setLastError(err error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.lastError = err
+}
+
+func (cache *ConfigCache) poll(ctx context.Context) error {
+	signature, _ := cache.signature(ctx)
+	// comme pour follow, les modifications antérieures à la première signature doivent être prises en compte
+	_ = cache.Refresh(ctx)
+	ticker := time.NewTicker(cache.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		current, err := cache.signature(ctx)
+		if err != nil {
+			cache.setLastError(err)
+			continue
+		}
+		if current != signature && cache.Refresh(ctx) == nil {
+			signature = current
+		}
+	}
+}
+
+// signature résume l'état des collections de la configuration : la date de dernière modification
+// signale les insertions et modifications, le nombre de documents les suppressions
+func (cache *ConfigCache) signature(ctx context.Context) (string, error) {
+	pipeline := []bson.M{{"$group": bson.M{
+		"_id":        0,
+		"modifiedAt": bson.M{"$max": "$modifiedAt"},
+		"count":      bson.M{"$sum": 1},
+	}}}
+	var parts []string
+	for _, name := range configCollections {
+		cur, err := cache.wekan.db.Collection(name).Aggregate(ctx, pipeline)
+		if err != nil {
+			return "", UnexpectedMongoError{err}
+		}
+		var state []struct {
+			ModifiedAt time.Time `bson:"modifiedAt"`
+			Count      int       `bson:"count"`
+		}
+		if err := cur.All(ctx, &state); err != nil {
+			return "", UnexpectedMongoDecodeError{err}
+		}
+		for _, s := range state {
+			parts = append(parts, fmt.Sprintf("%s:%d:%d", name, s.ModifiedAt.UnixNano(), s.Count))
+		}
+	}
+	return strings.Join(parts, "|"), nil
+}
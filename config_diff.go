@@ -0,0 +1,215 @@
+package libwekan
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigEntity désigne le type d'objet concerné par une ConfigChange
+type ConfigEntity string
+
+const (
+	EntityBoard       ConfigEntity = "board"
+	EntitySwimlane    ConfigEntity = "swimlane"
+	EntityList        ConfigEntity = "list"
+	EntityCustomField ConfigEntity = "customField"
+	EntityLabel       ConfigEntity = "label"
+	EntityMember      ConfigEntity = "member"
+	EntityUser        ConfigEntity = "user"
+)
+
+// ordre de présentation des changements
+var configEntities = []ConfigEntity{EntityBoard, EntitySwimlane, EntityList, EntityCustomField, EntityLabel, EntityMember, EntityUser}
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// FieldChange décrit la modification d'un champ, identifié par son nom dans la structure Go
+type FieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// ConfigChange décrit l'ajout, la suppression ou la modification d'un objet de la configuration.
+// BoardID est vide pour les utilisateurs, Fields n'est renseigné que pour les modifications.
+type ConfigChange struct {
+	Kind    ChangeKind
+	Entity  ConfigEntity
+	BoardID BoardID
+	ID      string
+	Name    string
+	Fields  []FieldChange
+}
+
+type ConfigChanges []ConfigChange
+
+// champs ignorés par la comparaison : horodatages techniques, éléments comparés séparément
+// et données de session des utilisateurs (jetons)
+var ignoredDiffFields = map[string]bool{
+	"ModifiedAt": true,
+	"UpdatedAt":  true,
+	"Labels":     true,
+	"Members":    true,
+	"Services":   true,
+}
+
+// ConfigDiff retourne les changements permettant de passer de old à new, triés par type d'objet, board et identifiant.
+// Les swimlanes, listes, champs, étiquettes et membres d'une board ajoutée ou supprimée sont également signalés.
+func ConfigDiff(old, new Config) ConfigChanges {
+	var changes ConfigChanges
+	changes = append(changes, diffEntities(EntityBoard, "", old.Boards, new.Boards, func(board ConfigBoard) (string, interface{}) {
+		return string(board.Board.Slug), board.Board
+	})...)
+
+	for boardID := range unionKeys(old.Boards, new.Boards) {
+		oldBoard, newBoard := old.Boards[boardID], new.Boards[boardID]
+		changes = append(changes, diffEntities(EntitySwimlane, boardID, oldBoard.Swimlanes, newBoard.Swimlanes, func(swimlane Swimlane) (string, interface{}) {
+			return swimlane.Title, swimlane
+		})...)
+		changes = append(changes, diffEntities(EntityList, boardID, oldBoard.Lists, newBoard.Lists, func(list List) (string, interface{}) {
+			return list.Title, list
+		})...)
+		changes = append(changes, diffEntities(EntityCustomField, boardID, oldBoard.CustomFields, newBoard.CustomFields, func(field CustomField) (string, interface{}) {
+			return field.Name, field
+		})...)
+		changes = append(changes, diffEntities(EntityLabel, boardID, labelsByID(oldBoard.Board.Labels), labelsByID(newBoard.Board.Labels), func(label BoardLabel) (string, interface{}) {
+			return string(label.Name), label
+		})...)
+		changes = append(changes, diffEntities(EntityMember, boardID, membersByID(oldBoard.Board.Members), membersByID(newBoard.Board.Members), func(member BoardMember) (string, interface{}) {
+			user, ok := new.Users[member.UserID]
+			if !ok {
+				user = old.Users[member.UserID]
+			}
+			return string(user.Username), member
+		})...)
+	}
+
+	changes = append(changes, diffEntities(EntityUser, "", old.Users, new.Users, func(user User) (string, interface{}) {
+		return string(user.Username), user
+	})...)
+
+	changes.sort()
+	return changes
+}
+
+// Empty indique que les deux configurations sont équivalentes
+func (changes ConfigChanges) Empty() bool {
+	return len(changes) == 0
+}
+
+// Entity retourne les changements qui concernent le type d'objet entity
+func (changes ConfigChanges) Entity(entity ConfigEntity) ConfigChanges {
+	var selected ConfigChanges
+	for _, change := range changes {
+		if change.Entity == entity {
+			selected = append(selected, change)
+		}
+	}
+	return selected
+}
+
+func (change ConfigChange) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s %s %s (%s)", change.Entity, change.Kind, change.ID, change.Name)
+	if change.BoardID != "" {
+		fmt.Fprintf(&builder, " board=%s", change.BoardID)
+	}
+	for _, field := range change.Fields {
+		fmt.Fprintf(&builder, " %s: %v → %v", field.Field, field.Old, field.New)
+	}
+	return builder.String()
+}
+
+func (changes ConfigChanges) sort() {
+	order := make(map[ConfigEntity]int, len(configEntities))
+	for i, entity := range configEntities {
+		order[entity] = i
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Entity != b.Entity {
+			return order[a.Entity] < order[b.Entity]
+		}
+		if a.BoardID != b.BoardID {
+			return a.BoardID < b.BoardID
+		}
+		return a.ID < b.ID
+	})
+}
+
+// diffEntities compare deux ensembles d'objets indexés par identifiant,
+// describe fournit le nom de l'objet et la valeur à comparer champ par champ
+func diffEntities[K ~string, V any](entity ConfigEntity, boardID BoardID, old, new map[K]V, describe func(V) (string, interface{})) []ConfigChange {
+	var changes []ConfigChange
+	for id, oldValue := range old {
+		name, oldCompared := describe(oldValue)
+		newValue, ok := new[id]
+		if !ok {
+			changes = append(changes, ConfigChange{Kind: ChangeRemoved, Entity: entity, BoardID: boardID, ID: string(id), Name: name})
+			continue
+		}
+		name, newCompared := describe(newValue)
+		if fields := fieldChanges(oldCompared, newCompared); len(fields) > 0 {
+			changes = append(changes, ConfigChange{Kind: ChangeModified, Entity: entity, BoardID: boardID, ID: string(id), Name: name, Fields: fields})
+		}
+	}
+	for id, newValue := range new {
+		if _, ok := old[id]; !ok {
+			name, _ := describe(newValue)
+			changes = append(changes, ConfigChange{Kind: ChangeAdded, Entity: entity, BoardID: boardID, ID: string(id), Name: name})
+		}
+	}
+	return changes
+}
+
+// fieldChanges compare les champs de premier niveau de deux structures de même type
+func fieldChanges(old, new interface{}) []FieldChange {
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+	var fields []FieldChange
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if !field.IsExported() || ignoredDiffFields[field.Name] {
+			continue
+		}
+		oldField, newField := oldValue.Field(i).Interface(), newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			fields = append(fields, FieldChange{Field: field.Name, Old: oldField, New: newField})
+		}
+	}
+	return fields
+}
+
+func unionKeys[K comparable, V any](a, b map[K]V) map[K]struct{} {
+	keys := make(map[K]struct{}, len(a)+len(b))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+func labelsByID(labels []BoardLabel) map[BoardLabelID]BoardLabel {
+	byID := make(map[BoardLabelID]BoardLabel, len(labels))
+	for _, label := range labels {
+		byID[label.ID] = label
+	}
+	return byID
+}
+
+func membersByID(members []BoardMember) map[UserID]BoardMember {
+	byID := make(map[UserID]BoardMember, len(members))
+	for _, member := range members {
+		byID[member.UserID] = member
+	}
+	return byID
+}
@@ -0,0 +1,83 @@
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testDiffConfig() Config {
+	board := BuildBoard("Tableau CRP", "tableau-crp", "board")
+	board.ID = "boardID"
+	board.Labels = []BoardLabel{{ID: "labelID", Name: "label", Color: "green"}}
+	board.Members = []BoardMember{{UserID: "userID", IsActive: true}}
+	return Config{
+		Boards: map[BoardID]ConfigBoard{
+			"boardID": {
+				Board:        board,
+				Swimlanes:    map[SwimlaneID]Swimlane{"swimlaneID": {ID: "swimlaneID", Title: "swimlane"}},
+				Lists:        map[ListID]List{"listID": {ID: "listID", Title: "liste"}},
+				CustomFields: ConfigCustomFields{"fieldID": {ID: "fieldID", Name: "Siret"}},
+			},
+		},
+		Users: map[UserID]User{"userID": {ID: "userID", Username: "user"}},
+	}
+}
+
+func TestConfigDiff_identicalConfigs(t *testing.T) {
+	assert.True(t, ConfigDiff(testDiffConfig(), testDiffConfig()).Empty())
+}
+
+func TestConfigDiff_fieldLevelChanges(t *testing.T) {
+	ass := assert.New(t)
+	old, new := testDiffConfig(), testDiffConfig()
+	board := new.Boards["boardID"]
+	board.Board.Title = "Nouveau titre"
+	board.Board.Labels = []BoardLabel{{ID: "labelID", Name: "label", Color: "red"}}
+	board.Board.Members = []BoardMember{{UserID: "userID", IsActive: false}}
+	board.Lists = map[ListID]List{"listID": {ID: "listID", Title: "liste renommée"}}
+	new.Boards["boardID"] = board
+
+	changes := ConfigDiff(old, new)
+
+	ass.Equal(ConfigChanges{
+		{Kind: ChangeModified, Entity: EntityBoard, ID: "boardID", Name: "tableau-crp",
+			Fields: []FieldChange{{Field: "Title", Old: BoardTitle("Tableau CRP"), New: BoardTitle("Nouveau titre")}}},
+		{Kind: ChangeModified, Entity: EntityList, BoardID: "boardID", ID: "listID", Name: "liste renommée",
+			Fields: []FieldChange{{Field: "Title", Old: "liste", New: "liste renommée"}}},
+		{Kind: ChangeModified, Entity: EntityLabel, BoardID: "boardID", ID: "labelID", Name: "label",
+			Fields: []FieldChange{{Field: "Color", Old: "green", New: "red"}}},
+		{Kind: ChangeModified, Entity: EntityMember, BoardID: "boardID", ID: "userID", Name: "user",
+			Fields: []FieldChange{{Field: "IsActive", Old: true, New: false}}},
+	}, changes)
+}
+
+func TestConfigDiff_addedAndRemoved(t *testing.T) {
+	ass := assert.New(t)
+	old, new := testDiffConfig(), testDiffConfig()
+	board := new.Boards["boardID"]
+	board.Swimlanes = map[SwimlaneID]Swimlane{"otherSwimlaneID": {ID: "otherSwimlaneID", Title: "autre"}}
+	board.CustomFields = ConfigCustomFields{}
+	new.Boards["boardID"] = board
+	new.Users["otherUserID"] = User{ID: "otherUserID", Username: "other"}
+
+	changes := ConfigDiff(old, new)
+
+	ass.Equal(ConfigChanges{
+		{Kind: ChangeAdded, Entity: EntitySwimlane, BoardID: "boardID", ID: "otherSwimlaneID", Name: "autre"},
+		{Kind: ChangeRemoved, Entity: EntitySwimlane, BoardID: "boardID", ID: "swimlaneID", Name: "swimlane"},
+		{Kind: ChangeRemoved, Entity: EntityCustomField, BoardID: "boardID", ID: "fieldID", Name: "Siret"},
+		{Kind: ChangeAdded, Entity: EntityUser, ID: "otherUserID", Name: "other"},
+	}, changes)
+	ass.Len(changes.Entity(EntitySwimlane), 2)
+	ass.Equal("user added otherUserID (other)", changes.Entity(EntityUser)[0].String())
+}
+
+func TestConfigDiff_ignoresTechnicalFields(t *testing.T) {
+	old, new := testDiffConfig(), testDiffConfig()
+	user := new.Users["userID"]
+	user.Services.OIDC.AccessToken = "secret"
+	new.Users["userID"] = user
+
+	assert.True(t, ConfigDiff(old, new).Empty())
+}
@@ -3,8 +3,11 @@
 package libwekan
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestConfig_MatchingBoardIsPresent(t *testing.T) {
@@ -42,3 +45,25 @@ func TestConfig_NotMatchingBoardIsAbsent(t *testing.T) {
 	_, ok := config.Boards[boardID]
 	ass.False(ok)
 }
+
+func TestConfigCache_RunRefreshesNewBoard(t *testing.T) {
+	ass := assert.New(t)
+	cache, err := wekan.NewConfigCache(ctx, 10*time.Millisecond)
+	ass.NoError(err)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go cache.Run(runCtx)
+
+	boardID := BoardID(newId())
+	err = wekan.InsertBoard(ctx, Board{
+		ID:   boardID,
+		Slug: BoardSlug("tableau-crp-" + t.Name()),
+	})
+	ass.NoError(err)
+
+	ass.Eventually(func() bool {
+		_, ok := cache.Snapshot().Boards[boardID]
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,213 @@
+package libwekan
+
+import (
+	"strings"
+	"sync"
+)
+
+type boardKey[K comparable] struct {
+	boardID BoardID
+	key     K
+}
+
+// configLookup indexe une Config par nom, lorsque plusieurs objets portent le même nom celui de plus petit ID est retenu
+type configLookup struct {
+	boardsBySlug       map[BoardSlug]BoardID
+	boardsByTitle      map[BoardTitle]BoardID
+	listsByTitle       map[boardKey[string]]List
+	swimlanesByTitle   map[boardKey[string]]Swimlane
+	labelsByName       map[boardKey[BoardLabelName]]BoardLabel
+	customFieldsByName map[boardKey[string]]CustomField
+	usersByUsername    map[Username]UserID
+	usersByEmail       map[string]UserID
+}
+
+// configIndex construit une seule fois, à la première recherche, les index d'une configuration préparée
+type configIndex struct {
+	once  sync.Once
+	table *configLookup
+}
+
+// Prepare associe à la configuration des index de recherche par nom, construits à la première recherche.
+// SelectConfig et ConfigCache retournent des configurations déjà préparées, les recherches y sont en temps constant.
+// Sur une configuration non préparée (construite à la main, décodée ou copiée par Copy), les recherches parcourent la configuration.
+// Prepare doit être appelée à nouveau après toute modification d'une configuration préparée.
+func (config *Config) Prepare() {
+	config.lookup = &configIndex{}
+}
+
+// lookupTable retourne les index de la configuration, nil si elle n'est pas préparée
+func (config *Config) lookupTable() *configLookup {
+	if config.lookup == nil {
+		return nil
+	}
+	config.lookup.once.Do(func() {
+		config.lookup.table = newConfigLookup(*config)
+	})
+	return config.lookup.table
+}
+
+func newConfigLookup(config Config) *configLookup {
+	lookup := &configLookup{
+		boardsBySlug:       make(map[BoardSlug]BoardID),
+		boardsByTitle:      make(map[BoardTitle]BoardID),
+		listsByTitle:       make(map[boardKey[string]]List),
+		swimlanesByTitle:   make(map[boardKey[string]]Swimlane),
+		labelsByName:       make(map[boardKey[BoardLabelName]]BoardLabel),
+		customFieldsByName: make(map[boardKey[string]]CustomField),
+		usersByUsername:    make(map[Username]UserID),
+		usersByEmail:       make(map[string]UserID),
+	}
+	for boardID, configBoard := range config.Boards {
+		indexFirst(lookup.boardsBySlug, configBoard.Board.Slug, boardID, func(id BoardID) BoardID { return id })
+		indexFirst(lookup.boardsByTitle, configBoard.Board.Title, boardID, func(id BoardID) BoardID { return id })
+		for _, list := range configBoard.Lists {
+			indexFirst(lookup.listsByTitle, boardKey[string]{boardID, list.Title}, list, func(list List) ListID { return list.ID })
+		}
+		for _, swimlane := range configBoard.Swimlanes {
+			indexFirst(lookup.swimlanesByTitle, boardKey[string]{boardID, swimlane.Title}, swimlane, func(swimlane Swimlane) SwimlaneID { return swimlane.ID })
+		}
+		for _, label := range configBoard.Board.Labels {
+			indexFirst(lookup.labelsByName, boardKey[BoardLabelName]{boardID, label.Name}, label, func(label BoardLabel) BoardLabelID { return label.ID })
+		}
+		for _, field := range configBoard.CustomFields {
+			indexFirst(lookup.customFieldsByName, boardKey[string]{boardID, field.Name}, field, func(field CustomField) CardCustomFieldID { return field.ID })
+		}
+	}
+	for userID, user := range config.Users {
+		indexFirst(lookup.usersByUsername, user.Username, userID, func(id UserID) UserID { return id })
+		for _, email := range user.Emails {
+			indexFirst(lookup.usersByEmail, strings.ToLower(email.Address), userID, func(id UserID) UserID { return id })
+		}
+	}
+	return lookup
+}
+
+// indexFirst ajoute value à l'index sauf si une valeur de plus petit identifiant y figure déjà
+func indexFirst[K comparable, V any, I ~string](index map[K]V, key K, value V, id func(V) I) {
+	if existing, ok := index[key]; ok && id(existing) <= id(value) {
+		return
+	}
+	index[key] = value
+}
+
+// scanFirst retourne la valeur de plus petit identifiant parmi celles qui satisfont match
+func scanFirst[K comparable, V any, I ~string](values map[K]V, match func(V) bool, id func(V) I) (V, bool) {
+	var first V
+	found := false
+	for _, value := range values {
+		if match(value) && (!found || id(value) < id(first)) {
+			first, found = value, true
+		}
+	}
+	return first, found
+}
+
+func (config *Config) BoardBySlug(slug BoardSlug) (ConfigBoard, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		boardID, ok := lookup.boardsBySlug[slug]
+		return config.Boards[boardID], ok
+	}
+	return scanFirst(config.Boards,
+		func(board ConfigBoard) bool { return board.Board.Slug == slug },
+		func(board ConfigBoard) BoardID { return board.Board.ID })
+}
+
+func (config *Config) BoardByTitle(title BoardTitle) (ConfigBoard, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		boardID, ok := lookup.boardsByTitle[title]
+		return config.Boards[boardID], ok
+	}
+	return scanFirst(config.Boards,
+		func(board ConfigBoard) bool { return board.Board.Title == title },
+		func(board ConfigBoard) BoardID { return board.Board.ID })
+}
+
+func (config *Config) ListByTitle(boardID BoardID, title string) (List, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		list, ok := lookup.listsByTitle[boardKey[string]{boardID, title}]
+		return list, ok
+	}
+	return scanFirst(config.Boards[boardID].Lists,
+		func(list List) bool { return list.Title == title },
+		func(list List) ListID { return list.ID })
+}
+
+func (config *Config) SwimlaneByTitle(boardID BoardID, title string) (Swimlane, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		swimlane, ok := lookup.swimlanesByTitle[boardKey[string]{boardID, title}]
+		return swimlane, ok
+	}
+	return scanFirst(config.Boards[boardID].Swimlanes,
+		func(swimlane Swimlane) bool { return swimlane.Title == title },
+		func(swimlane Swimlane) SwimlaneID { return swimlane.ID })
+}
+
+func (config *Config) LabelByName(boardID BoardID, name BoardLabelName) (BoardLabel, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		label, ok := lookup.labelsByName[boardKey[BoardLabelName]{boardID, name}]
+		return label, ok
+	}
+	labels := make(map[int]BoardLabel)
+	for i, label := range config.Boards[boardID].Board.Labels {
+		labels[i] = label
+	}
+	return scanFirst(labels,
+		func(label BoardLabel) bool { return label.Name == name },
+		func(label BoardLabel) BoardLabelID { return label.ID })
+}
+
+func (config *Config) CustomFieldByName(boardID BoardID, name string) (CustomField, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		field, ok := lookup.customFieldsByName[boardKey[string]{boardID, name}]
+		return field, ok
+	}
+	return config.Boards[boardID].CustomFields.byName(name)
+}
+
+// CustomFieldID retourne l'identifiant du champ personnalisé de la board portant ce nom, vide s'il n'existe pas
+func (config *Config) CustomFieldID(boardID BoardID, name string) CardCustomFieldID {
+	field, _ := config.CustomFieldByName(boardID, name)
+	return field.ID
+}
+
+func (config *Config) GetUserByUsername(username Username) (User, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		userID, ok := lookup.usersByUsername[username]
+		return config.Users[userID], ok
+	}
+	return scanFirst(config.Users,
+		func(user User) bool { return user.Username == username },
+		func(user User) UserID { return user.ID })
+}
+
+// GetUserByEmail recherche un utilisateur par adresse électronique, sans tenir compte de la casse
+func (config *Config) GetUserByEmail(email string) (User, bool) {
+	if lookup := config.lookupTable(); lookup != nil {
+		userID, ok := lookup.usersByEmail[strings.ToLower(email)]
+		return config.Users[userID], ok
+	}
+	return scanFirst(config.Users,
+		func(user User) bool {
+			for _, address := range user.Emails {
+				if strings.EqualFold(address.Address, email) {
+					return true
+				}
+			}
+			return false
+		},
+		func(user User) UserID { return user.ID })
+}
+
+func (config *Config) GetCardCustomFieldByName(card Card, name string) (string, bool) {
+	field, ok := config.CustomFieldByName(card.BoardID, name)
+	if !ok {
+		return "", false
+	}
+	for _, customField := range card.CustomFields {
+		if customField.ID == field.ID {
+			return customField.Value, true
+		}
+	}
+	return "", false
+}
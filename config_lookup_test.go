@@ -0,0 +1,115 @@
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigLookup_findsByName(t *testing.T) {
+	ass := assert.New(t)
+	config := testDiffConfig()
+	user := config.Users["userID"]
+	user.Emails = []UserEmail{{Address: "User@Example.com"}}
+	config.Users["userID"] = user
+	config.Prepare()
+
+	board, ok := config.BoardBySlug("tableau-crp")
+	ass.True(ok)
+	ass.Equal(BoardID("boardID"), board.Board.ID)
+	_, ok = config.BoardByTitle("Tableau CRP")
+	ass.True(ok)
+	list, ok := config.ListByTitle("boardID", "liste")
+	ass.True(ok)
+	ass.Equal(ListID("listID"), list.ID)
+	swimlane, ok := config.SwimlaneByTitle("boardID", "swimlane")
+	ass.True(ok)
+	ass.Equal(SwimlaneID("swimlaneID"), swimlane.ID)
+	label, ok := config.LabelByName("boardID", "label")
+	ass.True(ok)
+	ass.Equal(BoardLabelID("labelID"), label.ID)
+	field, ok := config.CustomFieldByName("boardID", "Siret")
+	ass.True(ok)
+	ass.Equal(CardCustomFieldID("fieldID"), field.ID)
+	actualUser, ok := config.GetUserByUsername("user")
+	ass.True(ok)
+	ass.Equal(UserID("userID"), actualUser.ID)
+	actualUser, ok = config.GetUserByEmail("user@example.com")
+	ass.True(ok)
+	ass.Equal(UserID("userID"), actualUser.ID)
+
+	_, ok = config.ListByTitle("otherBoardID", "liste")
+	ass.False(ok)
+	_, ok = config.GetUserByUsername("unknown")
+	ass.False(ok)
+}
+
+func TestConfigLookup_withoutPrepare(t *testing.T) {
+	config := testDiffConfig()
+	list, ok := config.ListByTitle("boardID", "liste")
+	assert.True(t, ok)
+	assert.Equal(t, ListID("listID"), list.ID)
+}
+
+func TestConfigLookup_duplicateNamesKeepSmallestID(t *testing.T) {
+	config := testDiffConfig()
+	board := config.Boards["boardID"]
+	board.Lists["aListID"] = List{ID: "aListID", Title: "liste"}
+	board.Lists["zListID"] = List{ID: "zListID", Title: "liste"}
+	config.Prepare()
+
+	list, _ := config.ListByTitle("boardID", "liste")
+	assert.Equal(t, ListID("aListID"), list.ID)
+}
+
+func TestConfigLookup_GetCardCustomFieldByName(t *testing.T) {
+	ass := assert.New(t)
+	config := testDiffConfig()
+	config.Prepare()
+	card := Card{BoardID: "boardID", CustomFields: []CardCustomField{{ID: "fieldID", Value: "12345678901234"}}}
+
+	value, ok := config.GetCardCustomFieldByName(card, "Siret")
+	ass.True(ok)
+	ass.Equal("12345678901234", value)
+	_, ok = config.GetCardCustomFieldByName(card, "Unknown")
+	ass.False(ok)
+}
+
+func TestConfigLookup_withoutPrepare_keepsSmallestID(t *testing.T) {
+	config := testDiffConfig()
+	board := config.Boards["boardID"]
+	board.Lists["zListID"] = List{ID: "zListID", Title: "liste"}
+	board.Lists["aListID"] = List{ID: "aListID", Title: "liste"}
+
+	list, _ := config.ListByTitle("boardID", "liste")
+	assert.Equal(t, ListID("aListID"), list.ID)
+	assert.Nil(t, config.lookup)
+}
+
+func TestConfigLookup_Copy_isNotPrepared(t *testing.T) {
+	ass := assert.New(t)
+	config := testDiffConfig()
+	config.Prepare()
+	_, ok := config.ListByTitle("boardID", "liste")
+	ass.True(ok)
+
+	copied := config.Copy()
+	copied.Boards = map[BoardID]ConfigBoard{"otherBoardID": {
+		Board: Board{ID: "otherBoardID", Slug: "tableau-crp-copie"},
+		Lists: map[ListID]List{"otherListID": {ID: "otherListID", Title: "liste"}},
+	}}
+
+	board, ok := copied.BoardBySlug("tableau-crp-copie")
+	ass.True(ok)
+	ass.Equal(BoardID("otherBoardID"), board.Board.ID)
+	_, ok = config.BoardBySlug("tableau-crp-copie")
+	ass.False(ok)
+}
+
+func TestConfigLookup_CustomFieldID(t *testing.T) {
+	config := testDiffConfig()
+	config.Prepare()
+	assert.Equal(t, CardCustomFieldID("fieldID"), config.CustomFieldID("boardID", "Siret"))
+	assert.Equal(t, CardCustomFieldID("fieldID"), config.Boards["boardID"].CustomFields.CustomFieldID("Siret"))
+	assert.Equal(t, CardCustomFieldID(""), config.CustomFieldID("boardID", "Unknown"))
+}
@@ -1,19 +1,25 @@
 package libwekan
 
-import "time"
+import (
+	"context"
+	"time"
+)
+
+// CustomFieldTypes liste les types de champ personnalisé proposés par Wekan
+var CustomFieldTypes = []string{"text", "number", "date", "dropdown", "currency", "checkbox", "stringtemplate"}
 
 type CustomField struct {
-	ID                  CardCustomFieldID `bson:"_id" json:"_id,omitempty"`
-	Name                string
-	Type                string
-	Settings            CustomFieldSettings
-	ShowOnCard          bool
-	ShowLabelOnMiniCard bool
-	AutomaticallyOnCard bool
-	BoardIDs            []BoardID
-	CreatedAt           time.Time
-	ModifiedAt          time.Time
-	ShowSumAtTopOfList  bool
+	ID                  CardCustomFieldID   `bson:"_id" json:"_id,omitempty"`
+	Name                string              `bson:"name"`
+	Type                string              `bson:"type"`
+	Settings            CustomFieldSettings `bson:"settings"`
+	ShowOnCard          bool                `bson:"showOnCard"`
+	ShowLabelOnMiniCard bool                `bson:"showLabelOnMiniCard"`
+	AutomaticallyOnCard bool                `bson:"automaticallyOnCard"`
+	BoardIDs            []BoardID           `bson:"boardIds"`
+	CreatedAt           time.Time           `bson:"createdAt"`
+	ModifiedAt          time.Time           `bson:"modifiedAt"`
+	ShowSumAtTopOfList  bool                `bson:"showSumAtTopOfList"`
 }
 
 type CustomFieldSettings struct {
@@ -22,3 +28,37 @@ type CustomFieldSettings struct {
 		Name string `bson:"name" json:"name,omitempty"`
 	} `bson:"dropdownItems" json:"dropdownItems,omitempty"`
 }
+
+func BuildCustomField(name string, fieldType string, boardID BoardID) CustomField {
+	return CustomField{
+		ID:         CardCustomFieldID(newId()),
+		Name:       name,
+		Type:       fieldType,
+		BoardIDs:   []BoardID{boardID},
+		CreatedAt:  toMongoTime(time.Now()),
+		ModifiedAt: toMongoTime(time.Now()),
+	}
+}
+
+func (wekan *Wekan) InsertCustomField(ctx context.Context, customField CustomField) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertCustomField", Targets{"customFieldID": string(customField.ID)})
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return err
+	}
+	for _, boardID := range customField.BoardIDs {
+		if _, err := wekan.GetBoardFromID(ctx, boardID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := wekan.db.Collection("customFields").InsertOne(ctx, customField); err != nil {
+		return UnexpectedMongoError{err}
+	}
+	for _, boardID := range customField.BoardIDs {
+		if _, err := wekan.insertActivity(ctx, newActivityCreateCustomField(wekan.ActorID(), boardID, customField.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
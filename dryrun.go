@@ -0,0 +1,235 @@
+package libwekan
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PlannedMutation décrit une écriture qui aurait été exécutée hors du mode dry-run
+type PlannedMutation struct {
+	Operation    string        `json:"operation"`
+	Collection   string        `json:"collection"`
+	Filter       interface{}   `json:"filter,omitempty"`
+	Update       interface{}   `json:"update,omitempty"`
+	ArrayFilters []interface{} `json:"arrayFilters,omitempty"`
+	Document     interface{}   `json:"document,omitempty"`
+	Activity     *Activity     `json:"activity,omitempty"`
+}
+
+// Journal collecte les mutations planifiées par un objet Wekan en mode dry-run
+type Journal struct {
+	mu        sync.Mutex
+	mutations []PlannedMutation
+}
+
+// Mutations retourne les mutations planifiées dans leur ordre d'exécution
+func (journal *Journal) Mutations() []PlannedMutation {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	mutations := make([]PlannedMutation, len(journal.mutations))
+	copy(mutations, journal.mutations)
+	return mutations
+}
+
+// Activities retourne les activités qui auraient été insérées
+func (journal *Journal) Activities() []Activity {
+	var activities []Activity
+	for _, mutation := range journal.Mutations() {
+		if mutation.Activity != nil {
+			activities = append(activities, *mutation.Activity)
+		}
+	}
+	return activities
+}
+
+// Reset vide le journal
+func (journal *Journal) Reset() {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	journal.mutations = nil
+}
+
+func (journal *Journal) record(mutation PlannedMutation) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	journal.mutations = append(journal.mutations, mutation)
+}
+
+// DryRun retourne une copie de l'objet Wekan dont les écritures sont consignées dans le journal au lieu d'être exécutées.
+// Les lectures sont effectuées sur la base réelle et ne reflètent donc pas les mutations planifiées,
+// une mise à jour est considérée comme effective dès que son filtre sélectionne un document.
+func (wekan *Wekan) DryRun() (Wekan, *Journal) {
+	journal := &Journal{}
+	dryRunWekan := *wekan
+	dryRunWekan.db = dryRunStorage{wekan.db, journal}
+	return dryRunWekan, journal
+}
+
+type dryRunStorage struct {
+	storage Storage
+	journal *Journal
+}
+
+type dryRunCollection struct {
+	collection Collection
+	name       string
+	journal    *Journal
+}
+
+func (storage dryRunStorage) Collection(name string) Collection {
+	return dryRunCollection{storage.storage.Collection(name), name, storage.journal}
+}
+
+func (storage dryRunStorage) Ping(ctx context.Context) error {
+	return storage.storage.Ping(ctx)
+}
+
+func (storage dryRunStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (storage dryRunStorage) Watch(ctx context.Context, collections []string) (ChangeStream, error) {
+	return storage.storage.Watch(ctx, collections)
+}
+
+func (c dryRunCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) SingleResult {
+	return c.collection.FindOne(ctx, filter, opts...)
+}
+
+func (c dryRunCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Cursor, error) {
+	return c.collection.Find(ctx, filter, opts...)
+}
+
+func (c dryRunCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Cursor, error) {
+	return c.collection.Aggregate(ctx, pipeline, opts...)
+}
+
+func (c dryRunCollection) InsertOne(_ context.Context, document interface{}, _ ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	mutation := PlannedMutation{
+		Operation:  "insertOne",
+		Collection: c.name,
+		Document:   document,
+	}
+	if activity, ok := document.(Activity); ok {
+		mutation.Activity = &activity
+	}
+	c.journal.record(mutation)
+	return &mongo.InsertOneResult{}, nil
+}
+
+func (c dryRunCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	matched, err := c.countMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	mutation := PlannedMutation{
+		Operation:  "updateOne",
+		Collection: c.name,
+		Filter:     filter,
+		Update:     update,
+	}
+	for _, opt := range opts {
+		if opt != nil && opt.ArrayFilters != nil {
+			mutation.ArrayFilters = append(mutation.ArrayFilters, opt.ArrayFilters.Filters...)
+		}
+	}
+	if matched > 0 {
+		c.journal.record(mutation)
+	}
+	return &mongo.UpdateResult{MatchedCount: matched, ModifiedCount: matched}, nil
+}
+
+func (c dryRunCollection) DeleteOne(ctx context.Context, filter interface{}, _ ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	matched, err := c.countMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if matched > 0 {
+		c.journal.record(PlannedMutation{
+			Operation:  "deleteOne",
+			Collection: c.name,
+			Filter:     filter,
+		})
+	}
+	return &mongo.DeleteResult{DeletedCount: matched}, nil
+}
+
+func (c dryRunCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	matched, err := c.countAllMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	mutation := PlannedMutation{
+		Operation:  "updateMany",
+		Collection: c.name,
+		Filter:     filter,
+		Update:     update,
+	}
+	for _, opt := range opts {
+		if opt != nil && opt.ArrayFilters != nil {
+			mutation.ArrayFilters = append(mutation.ArrayFilters, opt.ArrayFilters.Filters...)
+		}
+	}
+	if matched > 0 {
+		c.journal.record(mutation)
+	}
+	return &mongo.UpdateResult{MatchedCount: matched, ModifiedCount: matched}, nil
+}
+
+func (c dryRunCollection) DeleteMany(ctx context.Context, filter interface{}, _ ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	matched, err := c.countAllMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if matched > 0 {
+		c.journal.record(PlannedMutation{
+			Operation:  "deleteMany",
+			Collection: c.name,
+			Filter:     filter,
+		})
+	}
+	return &mongo.DeleteResult{DeletedCount: matched}, nil
+}
+
+// countAllMatching retourne le nombre de documents sélectionnés par le filtre
+func (c dryRunCollection) countAllMatching(ctx context.Context, filter interface{}) (int64, error) {
+	cur, err := c.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var documents []bson.M
+	if err := cur.All(ctx, &documents); err != nil {
+		return 0, err
+	}
+	return int64(len(documents)), nil
+}
+
+// countMatching retourne 1 si le filtre sélectionne au moins un document, 0 sinon
+func (c dryRunCollection) countMatching(ctx context.Context, filter interface{}) (int64, error) {
+	var document bson.M
+	err := c.collection.FindOne(ctx, filter).Decode(&document)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (c dryRunCollection) ListIndexes(ctx context.Context) (Cursor, error) {
+	return c.collection.ListIndexes(ctx)
+}
+
+func (c dryRunCollection) CreateIndex(_ context.Context, model mongo.IndexModel) (string, error) {
+	c.journal.record(PlannedMutation{
+		Operation:  "createIndex",
+		Collection: c.name,
+		Document:   model.Keys,
+	})
+	return "", nil
+}
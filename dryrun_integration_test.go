@@ -0,0 +1,54 @@
+//go:build integration
+
+// nolint:errcheck
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun_EnsureUserIsActiveBoardMember(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	board, _, _ := createTestBoard(t, "", 1, 1)
+	user := createTestUser(t, "")
+	dryRunWekan, journal := wekan.DryRun()
+
+	// WHEN
+	modified, err := dryRunWekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+
+	// THEN
+	ass.NoError(err)
+	ass.True(modified)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.False(actualBoard.UserIsMember(user))
+	activities := journal.Activities()
+	ass.Len(activities, 1)
+	ass.Equal("addBoardMember", activities[0].ActivityType)
+	ass.Equal(board.ID, activities[0].BoardID)
+}
+
+func TestDryRun_InsertRule(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	board, _, _ := createTestBoard(t, "", 1, 1)
+	user := createTestUser(t, "")
+	wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	board, _ = wekan.GetBoardFromID(ctx, board.ID)
+	rule := board.BuildRuleAddMember(user, board.Labels[0].Name)
+	dryRunWekan, journal := wekan.DryRun()
+
+	// WHEN
+	err := dryRunWekan.InsertRule(ctx, rule)
+
+	// THEN
+	ass.NoError(err)
+	_, err = wekan.SelectRuleFromID(ctx, rule.ID)
+	ass.IsType(RuleNotFoundError{}, err)
+	mutations := journal.Mutations()
+	ass.Len(mutations, 3)
+	collections := mapSlice(mutations, func(mutation PlannedMutation) string { return mutation.Collection })
+	ass.Equal([]string{"actions", "triggers", "rules"}, collections)
+}
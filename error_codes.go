@@ -0,0 +1,323 @@
+package libwekan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorCode est un identifiant stable d'erreur, destiné aux consommateurs de l'API
+// qui ne doivent pas dépendre du texte (français) des messages
+type ErrorCode string
+
+const (
+	CodeUnknown                   ErrorCode = "unknown"
+	CodeUserAlreadyExists         ErrorCode = "user_already_exists"
+	CodeUserNotFound              ErrorCode = "user_not_found"
+	CodeBoardNotFound             ErrorCode = "board_not_found"
+	CodeNotPrivileged             ErrorCode = "not_privileged"
+	CodeProtectedUser             ErrorCode = "protected_user"
+	CodeEmptyRule                 ErrorCode = "empty_rule"
+	CodeBoardLabelAlreadyExists   ErrorCode = "board_label_already_exists"
+	CodeBoardLabelNotFound        ErrorCode = "board_label_not_found"
+	CodeMongoError                ErrorCode = "mongo_error"
+	CodeMongoDecodeError          ErrorCode = "mongo_decode_error"
+	CodeActivityAlreadySet        ErrorCode = "activity_already_set"
+	CodeMongoUnreachable          ErrorCode = "mongo_unreachable"
+	CodeInvalidMongoConfiguration ErrorCode = "invalid_mongo_configuration"
+	CodeForbiddenOperation        ErrorCode = "forbidden_operation"
+	CodeNotImplemented            ErrorCode = "not_implemented"
+	CodeListNotFound              ErrorCode = "list_not_found"
+	CodeSwimlaneNotFound          ErrorCode = "swimlane_not_found"
+	CodeCardNotFound              ErrorCode = "card_not_found"
+	CodeRuleNotFound              ErrorCode = "rule_not_found"
+	CodeActionNotFound            ErrorCode = "action_not_found"
+	CodeTriggerNotFound           ErrorCode = "trigger_not_found"
+	CodeNothingDone               ErrorCode = "nothing_done"
+	CodeActivityNotFound          ErrorCode = "activity_not_found"
+	CodeUserIsNotMember           ErrorCode = "user_is_not_member"
+	CodeRetryExhausted            ErrorCode = "retry_exhausted"
+	CodeUnsupportedSchema         ErrorCode = "unsupported_schema"
+	CodeUnhealthy                 ErrorCode = "unhealthy"
+	CodeInvalidBoardSpec          ErrorCode = "invalid_board_spec"
+	CodeInvalidValue              ErrorCode = "invalid_value"
+	CodeBoardAlreadyExists        ErrorCode = "board_already_exists"
+	CodeLastBoardAdmin            ErrorCode = "last_board_admin"
+	CodeBoardModified             ErrorCode = "board_modified"
+)
+
+// ErrorDetails contient les informations structurées d'une erreur (identifiants, champs en cause…)
+type ErrorDetails map[string]interface{}
+
+// CodedError est implémentée par toutes les erreurs typées de libwekan
+type CodedError interface {
+	error
+	// Code retourne l'identifiant stable de l'erreur
+	Code() ErrorCode
+	// HTTPStatus suggère le statut HTTP à retourner au client
+	HTTPStatus() int
+	// Details retourne les informations structurées de l'erreur, jamais nil
+	Details() ErrorDetails
+}
+
+// ErrorCodeOf retourne le code de la première CodedError de la chaîne de err,
+// CodeUnknown si aucune n'est trouvée et "" si err est nil
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return CodeUnknown
+}
+
+// HTTPStatusOf retourne le statut HTTP suggéré pour err, 500 pour une erreur inconnue et 200 si err est nil
+func HTTPStatusOf(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.HTTPStatus()
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrorDetailsOf retourne les détails de la première CodedError de la chaîne de err
+func ErrorDetailsOf(err error) ErrorDetails {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Details()
+	}
+	return ErrorDetails{}
+}
+
+// MessageCatalog associe un modèle de message à chaque code d'erreur.
+// Les modèles peuvent faire référence aux détails de l'erreur avec la syntaxe {clé}
+type MessageCatalog map[ErrorCode]string
+
+// EnglishMessages est un catalogue de messages en anglais, à utiliser à la place des messages français de Error()
+var EnglishMessages = MessageCatalog{
+	CodeUnknown:                   "unexpected error",
+	CodeUserAlreadyExists:         "user already exists (userID: {userID}, username: {username})",
+	CodeUserNotFound:              "unknown user ({key})",
+	CodeBoardNotFound:             "no board found with {field} '{value}'",
+	CodeNotPrivileged:             "user is not an administrator (userID: {userID})",
+	CodeProtectedUser:             "this operation is forbidden on this user (userID: {userID})",
+	CodeEmptyRule:                 "an empty rule cannot be inserted",
+	CodeBoardLabelAlreadyExists:   "a label named '{labelName}' already exists in board {boardID}",
+	CodeBoardLabelNotFound:        "label {boardLabelID} was not found in board {boardID}",
+	CodeMongoError:                "database query failed",
+	CodeMongoDecodeError:          "database result could not be decoded",
+	CodeActivityAlreadySet:        "activity is already set (activityType: {activityType})",
+	CodeMongoUnreachable:          "database is unreachable",
+	CodeInvalidMongoConfiguration: "invalid database connection settings",
+	CodeForbiddenOperation:        "forbidden operation",
+	CodeNotImplemented:            "not implemented: {method}",
+	CodeListNotFound:              "list not found (listID: {listID})",
+	CodeSwimlaneNotFound:          "swimlane not found (swimlaneID: {swimlaneID})",
+	CodeCardNotFound:              "card not found (cardID: {cardID})",
+	CodeRuleNotFound:              "rule not found (ruleID: {ruleID})",
+	CodeActionNotFound:            "action not found (actionID: {actionID})",
+	CodeTriggerNotFound:           "trigger not found (triggerID: {triggerID})",
+	CodeNothingDone:               "the operation had no effect",
+	CodeActivityNotFound:          "activity not found ({key})",
+	CodeUserIsNotMember:           "this operation requires the user to be a member (userID: {userID})",
+	CodeRetryExhausted:            "operation failed after {attempts} attempts",
+	CodeUnsupportedSchema:         "unsupported Wekan version: {reason}",
+	CodeUnhealthy:                 "Wekan instance is not operational: {problems}",
+	CodeInvalidBoardSpec:          "invalid board specification: {reason}",
+	CodeInvalidValue:              "invalid value for {field}: '{value}'",
+	CodeBoardAlreadyExists:        "a board already exists with slug '{slug}'",
+	CodeLastBoardAdmin:            "user {userID} is the last admin of board {boardID}",
+	CodeBoardModified:             "board {boardID} was modified during the operation",
+}
+
+// Message traduit err à l'aide du catalogue, en retournant err.Error() lorsque le code n'y figure pas
+func (catalog MessageCatalog) Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	template, ok := catalog[ErrorCodeOf(err)]
+	if !ok {
+		return err.Error()
+	}
+	for key, value := range ErrorDetailsOf(err) {
+		template = strings.ReplaceAll(template, "{"+key+"}", formatDetail(value))
+	}
+	return template
+}
+
+func formatDetail(value interface{}) string {
+	if values, ok := value.([]string); ok {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+func withReason(details ErrorDetails, err error) ErrorDetails {
+	if err != nil {
+		details["reason"] = err.Error()
+	}
+	return details
+}
+
+func (e UserAlreadyExistsError) Code() ErrorCode { return CodeUserAlreadyExists }
+func (e UserAlreadyExistsError) HTTPStatus() int { return http.StatusConflict }
+func (e UserAlreadyExistsError) Details() ErrorDetails {
+	return ErrorDetails{"userID": string(e.User.ID), "username": string(e.User.Username)}
+}
+
+func (e UserNotFoundError) Code() ErrorCode       { return CodeUserNotFound }
+func (e UserNotFoundError) HTTPStatus() int       { return http.StatusNotFound }
+func (e UserNotFoundError) Details() ErrorDetails { return ErrorDetails{"key": e.Key} }
+
+func (e BoardNotFoundError) Code() ErrorCode { return CodeBoardNotFound }
+func (e BoardNotFoundError) HTTPStatus() int { return http.StatusNotFound }
+func (e BoardNotFoundError) Details() ErrorDetails {
+	return ErrorDetails{"field": e.Field, "value": e.Value}
+}
+
+func (e NotPrivilegedError) Code() ErrorCode { return CodeNotPrivileged }
+func (e NotPrivilegedError) HTTPStatus() int { return http.StatusForbidden }
+func (e NotPrivilegedError) Details() ErrorDetails {
+	return withReason(ErrorDetails{"userID": string(e.UserID)}, e.err)
+}
+
+func (e ProtectedUserError) Code() ErrorCode       { return CodeProtectedUser }
+func (e ProtectedUserError) HTTPStatus() int       { return http.StatusForbidden }
+func (e ProtectedUserError) Details() ErrorDetails { return ErrorDetails{"userID": string(e.UserID)} }
+
+func (e InsertEmptyRuleError) Code() ErrorCode       { return CodeEmptyRule }
+func (e InsertEmptyRuleError) HTTPStatus() int       { return http.StatusBadRequest }
+func (e InsertEmptyRuleError) Details() ErrorDetails { return ErrorDetails{} }
+
+func (e BoardLabelAlreadyExistsError) Code() ErrorCode { return CodeBoardLabelAlreadyExists }
+func (e BoardLabelAlreadyExistsError) HTTPStatus() int { return http.StatusConflict }
+func (e BoardLabelAlreadyExistsError) Details() ErrorDetails {
+	return ErrorDetails{"boardID": string(e.Board.ID), "labelName": string(e.BoardLabel.Name)}
+}
+
+func (e BoardLabelNotFoundError) Code() ErrorCode { return CodeBoardLabelNotFound }
+func (e BoardLabelNotFoundError) HTTPStatus() int { return http.StatusNotFound }
+func (e BoardLabelNotFoundError) Details() ErrorDetails {
+	return ErrorDetails{"boardID": string(e.Board.ID), "boardLabelID": string(e.BoardLabelID)}
+}
+
+func (e UnexpectedMongoError) Code() ErrorCode       { return CodeMongoError }
+func (e UnexpectedMongoError) HTTPStatus() int       { return http.StatusInternalServerError }
+func (e UnexpectedMongoError) Details() ErrorDetails { return withReason(ErrorDetails{}, e.err) }
+
+func (e UnexpectedMongoDecodeError) Code() ErrorCode       { return CodeMongoDecodeError }
+func (e UnexpectedMongoDecodeError) HTTPStatus() int       { return http.StatusInternalServerError }
+func (e UnexpectedMongoDecodeError) Details() ErrorDetails { return withReason(ErrorDetails{}, e.err) }
+
+func (e AlreadySetActivityError) Code() ErrorCode { return CodeActivityAlreadySet }
+func (e AlreadySetActivityError) HTTPStatus() int { return http.StatusConflict }
+func (e AlreadySetActivityError) Details() ErrorDetails {
+	return ErrorDetails{"activityType": e.ActivityType}
+}
+
+func (e UnreachableMongoError) Code() ErrorCode       { return CodeMongoUnreachable }
+func (e UnreachableMongoError) HTTPStatus() int       { return http.StatusServiceUnavailable }
+func (e UnreachableMongoError) Details() ErrorDetails { return withReason(ErrorDetails{}, e.err) }
+
+func (e InvalidMongoConfigurationError) Code() ErrorCode { return CodeInvalidMongoConfiguration }
+func (e InvalidMongoConfigurationError) HTTPStatus() int { return http.StatusInternalServerError }
+func (e InvalidMongoConfigurationError) Details() ErrorDetails {
+	return withReason(ErrorDetails{}, e.err)
+}
+
+func (e ForbiddenOperationError) Code() ErrorCode       { return CodeForbiddenOperation }
+func (e ForbiddenOperationError) HTTPStatus() int       { return http.StatusForbidden }
+func (e ForbiddenOperationError) Details() ErrorDetails { return withReason(ErrorDetails{}, e.err) }
+
+func (e NotImplemented) Code() ErrorCode       { return CodeNotImplemented }
+func (e NotImplemented) HTTPStatus() int       { return http.StatusNotImplemented }
+func (e NotImplemented) Details() ErrorDetails { return ErrorDetails{"method": e.Method} }
+
+func (e ListNotFoundError) Code() ErrorCode       { return CodeListNotFound }
+func (e ListNotFoundError) HTTPStatus() int       { return http.StatusNotFound }
+func (e ListNotFoundError) Details() ErrorDetails { return ErrorDetails{"listID": string(e.ListID)} }
+
+func (e SwimlaneNotFoundError) Code() ErrorCode { return CodeSwimlaneNotFound }
+func (e SwimlaneNotFoundError) HTTPStatus() int { return http.StatusNotFound }
+func (e SwimlaneNotFoundError) Details() ErrorDetails {
+	return ErrorDetails{"swimlaneID": string(e.SwimlaneID)}
+}
+
+func (e CardNotFoundError) Code() ErrorCode       { return CodeCardNotFound }
+func (e CardNotFoundError) HTTPStatus() int       { return http.StatusNotFound }
+func (e CardNotFoundError) Details() ErrorDetails { return ErrorDetails{"cardID": string(e.CardID)} }
+
+func (e RuleNotFoundError) Code() ErrorCode       { return CodeRuleNotFound }
+func (e RuleNotFoundError) HTTPStatus() int       { return http.StatusNotFound }
+func (e RuleNotFoundError) Details() ErrorDetails { return ErrorDetails{"ruleID": string(e.RuleID)} }
+
+func (e ActionNotFoundError) Code() ErrorCode { return CodeActionNotFound }
+func (e ActionNotFoundError) HTTPStatus() int { return http.StatusNotFound }
+func (e ActionNotFoundError) Details() ErrorDetails {
+	return ErrorDetails{"actionID": string(e.ActionID)}
+}
+
+func (e TriggerNotFoundError) Code() ErrorCode { return CodeTriggerNotFound }
+func (e TriggerNotFoundError) HTTPStatus() int { return http.StatusNotFound }
+func (e TriggerNotFoundError) Details() ErrorDetails {
+	return ErrorDetails{"triggerID": string(e.TriggerID)}
+}
+
+// NothingDoneError signale que l'état demandé était déjà atteint : 409 laisse le client décider s'il s'agit d'un succès
+func (e NothingDoneError) Code() ErrorCode       { return CodeNothingDone }
+func (e NothingDoneError) HTTPStatus() int       { return http.StatusConflict }
+func (e NothingDoneError) Details() ErrorDetails { return ErrorDetails{} }
+
+func (e ActivityNotFoundError) Code() ErrorCode       { return CodeActivityNotFound }
+func (e ActivityNotFoundError) HTTPStatus() int       { return http.StatusNotFound }
+func (e ActivityNotFoundError) Details() ErrorDetails { return ErrorDetails{"key": e.Key} }
+
+func (e UserIsNotMemberError) Code() ErrorCode       { return CodeUserIsNotMember }
+func (e UserIsNotMemberError) HTTPStatus() int       { return http.StatusForbidden }
+func (e UserIsNotMemberError) Details() ErrorDetails { return ErrorDetails{"userID": string(e.UserID)} }
+
+func (e RetryExhaustedError) Code() ErrorCode { return CodeRetryExhausted }
+func (e RetryExhaustedError) HTTPStatus() int { return http.StatusServiceUnavailable }
+func (e RetryExhaustedError) Details() ErrorDetails {
+	return withReason(ErrorDetails{"attempts": e.Attempts}, e.err)
+}
+
+func (e UnsupportedSchemaError) Code() ErrorCode { return CodeUnsupportedSchema }
+func (e UnsupportedSchemaError) HTTPStatus() int { return http.StatusInternalServerError }
+func (e UnsupportedSchemaError) Details() ErrorDetails {
+	return ErrorDetails{"reason": e.Reason, "missingMigrations": e.MissingMigrations}
+}
+
+func (e UnhealthyError) Code() ErrorCode       { return CodeUnhealthy }
+func (e UnhealthyError) HTTPStatus() int       { return http.StatusServiceUnavailable }
+func (e UnhealthyError) Details() ErrorDetails { return ErrorDetails{"problems": e.Problems} }
+
+func (e InvalidBoardSpecError) Code() ErrorCode       { return CodeInvalidBoardSpec }
+func (e InvalidBoardSpecError) HTTPStatus() int       { return http.StatusBadRequest }
+func (e InvalidBoardSpecError) Details() ErrorDetails { return ErrorDetails{"reason": e.Reason} }
+
+func (e InvalidValueError) Code() ErrorCode { return CodeInvalidValue }
+func (e InvalidValueError) HTTPStatus() int { return http.StatusBadRequest }
+func (e InvalidValueError) Details() ErrorDetails {
+	return ErrorDetails{"field": e.Field, "value": e.Value}
+}
+
+func (e BoardAlreadyExistsError) Code() ErrorCode       { return CodeBoardAlreadyExists }
+func (e BoardAlreadyExistsError) HTTPStatus() int       { return http.StatusConflict }
+func (e BoardAlreadyExistsError) Details() ErrorDetails { return ErrorDetails{"slug": string(e.Slug)} }
+
+func (e LastBoardAdminError) Code() ErrorCode { return CodeLastBoardAdmin }
+func (e LastBoardAdminError) HTTPStatus() int { return http.StatusForbidden }
+func (e LastBoardAdminError) Details() ErrorDetails {
+	return ErrorDetails{"boardID": string(e.BoardID), "userID": string(e.UserID)}
+}
+
+func (e BoardModifiedError) Code() ErrorCode       { return CodeBoardModified }
+func (e BoardModifiedError) HTTPStatus() int       { return http.StatusConflict }
+func (e BoardModifiedError) Details() ErrorDetails { return ErrorDetails{"boardID": string(e.BoardID)} }
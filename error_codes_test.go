@@ -0,0 +1,69 @@
+package libwekan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes_allErrorsAreCoded(t *testing.T) {
+	codedErrors := []CodedError{
+		UserAlreadyExistsError{}, UserNotFoundError{}, BoardNotFoundError{}, NotPrivilegedError{},
+		ProtectedUserError{}, InsertEmptyRuleError{}, BoardLabelAlreadyExistsError{}, BoardLabelNotFoundError{},
+		UnexpectedMongoError{}, UnexpectedMongoDecodeError{}, AlreadySetActivityError{}, UnreachableMongoError{},
+		InvalidMongoConfigurationError{}, ForbiddenOperationError{}, NotImplemented{}, ListNotFoundError{},
+		SwimlaneNotFoundError{}, CardNotFoundError{}, RuleNotFoundError{}, ActionNotFoundError{},
+		TriggerNotFoundError{}, NothingDoneError{}, ActivityNotFoundError{}, UserIsNotMemberError{},
+		RetryExhaustedError{}, UnsupportedSchemaError{}, UnhealthyError{}, InvalidBoardSpecError{},
+		InvalidValueError{}, BoardAlreadyExistsError{}, LastBoardAdminError{}, BoardModifiedError{},
+	}
+	codes := make(map[ErrorCode]bool)
+	for _, e := range codedErrors {
+		assert.NotContains(t, codes, e.Code(), "code en double : %s", e.Code())
+		codes[e.Code()] = true
+		assert.Contains(t, EnglishMessages, e.Code())
+		assert.NotNil(t, e.Details())
+		assert.NotZero(t, e.HTTPStatus())
+	}
+}
+
+func TestErrorCodes_ErrorCodeOf(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal(ErrorCode(""), ErrorCodeOf(nil))
+	ass.Equal(CodeUnknown, ErrorCodeOf(errors.New("boom")))
+	ass.Equal(CodeCardNotFound, ErrorCodeOf(CardNotFoundError{"cardID"}))
+	wrapped := fmt.Errorf("contexte : %w", UserIsNotMemberError{"userID"})
+	ass.Equal(CodeUserIsNotMember, ErrorCodeOf(wrapped))
+}
+
+func TestErrorCodes_HTTPStatusOf(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal(http.StatusOK, HTTPStatusOf(nil))
+	ass.Equal(http.StatusInternalServerError, HTTPStatusOf(errors.New("boom")))
+	ass.Equal(http.StatusNotFound, HTTPStatusOf(boardNotFoundWithSlug("slug")))
+	ass.Equal(http.StatusForbidden, HTTPStatusOf(ForbiddenOperationError{errors.New("interdit")}))
+	ass.Equal(http.StatusConflict, HTTPStatusOf(BoardLabelAlreadyExistsError{}))
+	ass.Equal(http.StatusConflict, HTTPStatusOf(NothingDoneError{}))
+}
+
+func TestErrorCodes_ErrorDetailsOf(t *testing.T) {
+	ass := assert.New(t)
+	e := BoardLabelAlreadyExistsError{BoardLabel{Name: "label"}, Board{ID: "boardID"}}
+	ass.Equal(ErrorDetails{"boardID": "boardID", "labelName": "label"}, ErrorDetailsOf(e))
+	ass.Equal(ErrorDetails{"reason": "interdit"}, ErrorDetailsOf(ForbiddenOperationError{errors.New("interdit")}))
+	ass.Equal(ErrorDetails{}, ErrorDetailsOf(errors.New("boom")))
+}
+
+func TestErrorCodes_EnglishMessages(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal("", EnglishMessages.Message(nil))
+	ass.Equal("no board found with slug 'test'", EnglishMessages.Message(boardNotFoundWithSlug("test")))
+	ass.Equal("this operation requires the user to be a member (userID: userID)", EnglishMessages.Message(UserIsNotMemberError{"userID"}))
+	ass.Equal("Wekan instance is not operational: a, b", EnglishMessages.Message(UnhealthyError{[]string{"a", "b"}}))
+	ass.Equal("unexpected error", EnglishMessages.Message(errors.New("boom")))
+	ass.Equal("boom", MessageCatalog{}.Message(errors.New("boom")))
+	ass.Equal(NothingDoneError{}.Error(), MessageCatalog{}.Message(NothingDoneError{}))
+}
@@ -1,58 +1,87 @@
 package libwekan
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+)
+
+// Les erreurs typées de libwekan correspondent à l'une de ces erreurs sentinelles via errors.Is,
+// ce qui permet de traiter une famille d'erreurs sans connaître chaque type
+var (
+	ErrNotFound      = errors.New("document introuvable")
+	ErrForbidden     = errors.New("opération interdite")
+	ErrNothingDone   = errors.New("aucun effet")
+	ErrAlreadyExists = errors.New("document déjà existant")
 )
 
 type UserAlreadyExistsError struct {
-	user User
+	User User
 }
 
 func (e UserAlreadyExistsError) Error() string {
-	return fmt.Sprintf("l'utilisateur existe déjà (UserID: %s, Username: %s)", e.user.ID, e.user.Username)
+	return fmt.Sprintf("l'utilisateur existe déjà (UserID: %s, Username: %s)", e.User.ID, e.User.Username)
+}
+
+func (e UserAlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
 }
 
 type UserNotFoundError struct {
-	key string
+	Key string
 	err error
 }
 
 func (e UserNotFoundError) Error() string {
-	return fmt.Sprintf("l'utilisateur n'est pas connu (%s)", e.key)
+	return fmt.Sprintf("l'utilisateur n'est pas connu (%s)", e.Key)
 }
 
 func (e UserNotFoundError) Unwrap() error {
 	return e.err
 }
 
+func (e UserNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// BoardNotFoundError est retournée lorsqu'aucune board ne correspond à la valeur Value du champ Field (slug, boardID ou titre)
 type BoardNotFoundError struct {
-	msg string
-	err error
+	Field string
+	Value string
+	err   error
 }
 
 func boardNotFoundWithSlug(slug BoardSlug) error {
-	return BoardNotFoundError{msg: fmt.Sprintf("le slug : '%s'", slug)}
+	return BoardNotFoundError{Field: "slug", Value: string(slug)}
 }
 
 func boardNotFoundWithId(boardID BoardID) error {
-	return BoardNotFoundError{msg: fmt.Sprintf("le boardID : '%s'", boardID)}
+	return BoardNotFoundError{Field: "boardID", Value: string(boardID)}
 }
 
 func boardNotFoundWithTitle(boardTitle BoardTitle) error {
-	return BoardNotFoundError{msg: fmt.Sprintf("le titre : '%s'", boardTitle)}
+	return BoardNotFoundError{Field: "title", Value: string(boardTitle)}
 }
 
 func (e BoardNotFoundError) Error() string {
-	return fmt.Sprintf("aucun tableau n'a été trouvé avec %s", e.msg)
+	field := e.Field
+	if field == "title" {
+		field = "titre"
+	}
+	return fmt.Sprintf("aucun tableau n'a été trouvé avec le %s : '%s'", field, e.Value)
 }
 
 func (e BoardNotFoundError) Unwrap() error {
 	return e.err
 }
 
+func (e BoardNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
 type NotPrivilegedError struct {
-	id  UserID
-	err error
+	UserID UserID
+	err    error
 }
 
 func (e NotPrivilegedError) Unwrap() error {
@@ -60,15 +89,27 @@ func (e NotPrivilegedError) Unwrap() error {
 }
 
 func (e NotPrivilegedError) Error() string {
-	return fmt.Sprint("l'utilisateur n'est pas admin: id = "+e.id, e.err)
+	message := fmt.Sprintf("l'utilisateur n'est pas admin: id = %s", e.UserID)
+	if e.err == nil || e.err.Error() == "" {
+		return message
+	}
+	return fmt.Sprintf("%s (%s)", message, e.err)
+}
+
+func (e NotPrivilegedError) Is(target error) bool {
+	return target == ErrForbidden
 }
 
 type ProtectedUserError struct {
-	id UserID
+	UserID UserID
 }
 
 func (e ProtectedUserError) Error() string {
-	return fmt.Sprintf("cet action est interdite sur cet utilisateur (%s)", e.id)
+	return fmt.Sprintf("cet action est interdite sur cet utilisateur (%s)", e.UserID)
+}
+
+func (e ProtectedUserError) Is(target error) bool {
+	return target == ErrForbidden
 }
 
 type InsertEmptyRuleError struct {
@@ -79,21 +120,29 @@ func (e InsertEmptyRuleError) Error() string {
 }
 
 type BoardLabelAlreadyExistsError struct {
-	boardLabel BoardLabel
-	board      Board
+	BoardLabel BoardLabel
+	Board      Board
 }
 
 func (e BoardLabelAlreadyExistsError) Error() string {
-	return fmt.Sprintf("un objet BoardLabel existe déjà dans la board (%s) avec le même nom (%s)", e.board.ID, e.boardLabel.Name)
+	return fmt.Sprintf("un objet BoardLabel existe déjà dans la board (%s) avec le même nom (%s)", e.Board.ID, e.BoardLabel.Name)
+}
+
+func (e BoardLabelAlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
 }
 
 type BoardLabelNotFoundError struct {
-	boardLabelID BoardLabelID
-	board        Board
+	BoardLabelID BoardLabelID
+	Board        Board
 }
 
 func (e BoardLabelNotFoundError) Error() string {
-	return fmt.Sprintf("l'objet BoardLabel (id=%s) n'a pas été trouvé dans la board (%s)", e.boardLabelID, e.board.ID)
+	return fmt.Sprintf("l'objet BoardLabel (id=%s) n'a pas été trouvé dans la board (%s)", e.BoardLabelID, e.Board.ID)
+}
+
+func (e BoardLabelNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type UnexpectedMongoError struct {
@@ -121,11 +170,15 @@ func (e UnexpectedMongoDecodeError) Unwrap() error {
 }
 
 type AlreadySetActivityError struct {
-	activityType string
+	ActivityType string
 }
 
 func (e AlreadySetActivityError) Error() string {
-	return fmt.Sprintf("l'activité est déjà définie: activityType = %s", e.activityType)
+	return fmt.Sprintf("l'activité est déjà définie: activityType = %s", e.ActivityType)
+}
+
+func (e AlreadySetActivityError) Is(target error) bool {
+	return target == ErrAlreadyExists
 }
 
 type UnreachableMongoError struct {
@@ -164,52 +217,88 @@ func (e ForbiddenOperationError) Unwrap() error {
 	return e.err
 }
 
+func (e ForbiddenOperationError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
 type NotImplemented struct {
-	method string
+	Method string
 }
 
 func (e NotImplemented) Error() string {
-	return "not implemented : " + e.method
+	return "not implemented : " + e.Method
 }
 
 type ListNotFoundError struct {
-	listID ListID
+	ListID ListID
 }
 
 func (e ListNotFoundError) Error() string {
-	return fmt.Sprintf("la liste n'existe pas (ID: %s)", e.listID)
+	return fmt.Sprintf("la liste n'existe pas (ID: %s)", e.ListID)
+}
+
+func (e ListNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+type SwimlaneNotFoundError struct {
+	SwimlaneID SwimlaneID
+}
+
+func (e SwimlaneNotFoundError) Error() string {
+	return fmt.Sprintf("la swimlane n'existe pas (ID: %s)", e.SwimlaneID)
+}
+
+func (e SwimlaneNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type CardNotFoundError struct {
-	cardID CardID
+	CardID CardID
 }
 
 func (e CardNotFoundError) Error() string {
-	return fmt.Sprintf("la carte n'existe pas (ID: %s)", e.cardID)
+	return fmt.Sprintf("la carte n'existe pas (ID: %s)", e.CardID)
+}
+
+func (e CardNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type RuleNotFoundError struct {
-	ruleID RuleID
+	RuleID RuleID
 }
 
 func (e RuleNotFoundError) Error() string {
-	return fmt.Sprintf("la règle n'existe pas (ID: %s)", e.ruleID)
+	return fmt.Sprintf("la règle n'existe pas (ID: %s)", e.RuleID)
+}
+
+func (e RuleNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type ActionNotFoundError struct {
-	actionId ActionID
+	ActionID ActionID
 }
 
 func (e ActionNotFoundError) Error() string {
-	return fmt.Sprintf("l'action n'existe pas (ID: %s)", e.actionId)
+	return fmt.Sprintf("l'action n'existe pas (ID: %s)", e.ActionID)
+}
+
+func (e ActionNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type TriggerNotFoundError struct {
-	triggerId TriggerID
+	TriggerID TriggerID
 }
 
 func (e TriggerNotFoundError) Error() string {
-	return fmt.Sprintf("le trigger n'existe pas (ID: %s)", e.triggerId)
+	return fmt.Sprintf("le trigger n'existe pas (ID: %s)", e.TriggerID)
+}
+
+func (e TriggerNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type NothingDoneError struct{}
@@ -218,18 +307,131 @@ func (e NothingDoneError) Error() string {
 	return "le traitement n'a eu aucun effet"
 }
 
+func (e NothingDoneError) Is(target error) bool {
+	return target == ErrNothingDone
+}
+
 type ActivityNotFoundError struct {
-	key string
+	Key string
 }
 
 func (e ActivityNotFoundError) Error() string {
-	return fmt.Sprintf("l'activité n'existe pas (ID: %s)", e.key)
+	return fmt.Sprintf("l'activité n'existe pas (ID: %s)", e.Key)
+}
+
+func (e ActivityNotFoundError) Is(target error) bool {
+	return target == ErrNotFound
 }
 
 type UserIsNotMemberError struct {
-	userID UserID
+	UserID UserID
 }
 
 func (e UserIsNotMemberError) Error() string {
-	return fmt.Sprintf("cette action nécessite que l'utilisateur soit membre (id=%s)", e.userID)
+	return fmt.Sprintf("cette action nécessite que l'utilisateur soit membre (id=%s)", e.UserID)
+}
+
+func (e UserIsNotMemberError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+type RetryExhaustedError struct {
+	Attempts int
+	err      error
+}
+
+func (e RetryExhaustedError) Error() string {
+	return fmt.Sprintf("échec après %d tentatives : %s", e.Attempts, e.err)
+}
+
+func (e RetryExhaustedError) Unwrap() error {
+	return e.err
+}
+
+// RetriedError enveloppe l'erreur, non rejouable, d'une opération qui a déjà été rejouée,
+// elle ne porte pas de code propre : le code et le statut HTTP restent ceux de l'erreur enveloppée
+type RetriedError struct {
+	Attempts int
+	err      error
+}
+
+func (e RetriedError) Error() string {
+	return fmt.Sprintf("échec à la tentative %d : %s", e.Attempts, e.err)
+}
+
+func (e RetriedError) Unwrap() error {
+	return e.err
+}
+
+type UnsupportedSchemaError struct {
+	Reason            string
+	MissingMigrations []string
+}
+
+func (e UnsupportedSchemaError) Error() string {
+	if len(e.MissingMigrations) > 0 {
+		return fmt.Sprintf("version de Wekan non supportée, %s : %s", e.Reason, strings.Join(e.MissingMigrations, ", "))
+	}
+	return fmt.Sprintf("version de Wekan non supportée, %s", e.Reason)
+}
+
+type UnhealthyError struct {
+	Problems []string
+}
+
+func (e UnhealthyError) Error() string {
+	return fmt.Sprintf("l'instance wekan n'est pas opérationnelle : %s", strings.Join(e.Problems, " ; "))
+}
+
+type InvalidBoardSpecError struct {
+	Reason string
+}
+
+func (e InvalidBoardSpecError) Error() string {
+	return fmt.Sprintf("la spécification de board est invalide : %s", e.Reason)
+}
+
+// InvalidValueError est retournée lorsqu'une valeur fournie pour le champ Field n'est pas acceptée par Wekan
+type InvalidValueError struct {
+	Field string
+	Value string
+}
+
+func (e InvalidValueError) Error() string {
+	return fmt.Sprintf("valeur invalide pour %s : '%s'", e.Field, e.Value)
+}
+
+type BoardAlreadyExistsError struct {
+	Slug BoardSlug
+}
+
+func (e BoardAlreadyExistsError) Error() string {
+	return fmt.Sprintf("une board existe déjà avec le slug : '%s'", e.Slug)
+}
+
+func (e BoardAlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
+
+// LastBoardAdminError est retournée lorsqu'une opération retirerait le dernier administrateur actif d'une board
+type LastBoardAdminError struct {
+	BoardID BoardID
+	UserID  UserID
+}
+
+func (e LastBoardAdminError) Error() string {
+	return fmt.Sprintf("l'utilisateur (id=%s) est le dernier administrateur de la board (%s)", e.UserID, e.BoardID)
+}
+
+func (e LastBoardAdminError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// BoardModifiedError est retournée lorsque la board a été modifiée par ailleurs entre sa lecture et sa mise à jour
+type BoardModifiedError struct {
+	BoardID BoardID
+}
+
+func (e BoardModifiedError) Error() string {
+	return fmt.Sprintf("la board (%s) a été modifiée pendant l'opération", e.BoardID)
 }
@@ -11,13 +11,13 @@ import (
 
 func TestErrors_UserAlreadyExistsError(t *testing.T) {
 	e := UserAlreadyExistsError{User{ID: "testID", Username: "testID"}}
-	expected := fmt.Sprintf("l'utilisateur existe déjà (UserID: %s, Username: %s)", e.user.ID, e.user.Username)
+	expected := fmt.Sprintf("l'utilisateur existe déjà (UserID: %s, Username: %s)", e.User.ID, e.User.Username)
 	assert.EqualError(t, e, expected)
 }
 
 func TestErrors_UserNotFoundError(t *testing.T) {
-	e := UserNotFoundError{key: "test"}
-	expected := fmt.Sprintf("l'utilisateur n'est pas connu (%s)", e.key)
+	e := UserNotFoundError{Key: "test"}
+	expected := fmt.Sprintf("l'utilisateur n'est pas connu (%s)", e.Key)
 	assert.EqualError(t, e, expected)
 }
 
@@ -35,13 +35,16 @@ func TestErrors_BoardNotFoundError(t *testing.T) {
 
 func TestErrors_NotPrivilegedError(t *testing.T) {
 	e := NotPrivilegedError{"test", errors.New("")}
-	expected := fmt.Sprintf("l'utilisateur n'est pas admin: id = %s", e.id)
+	expected := fmt.Sprintf("l'utilisateur n'est pas admin: id = %s", e.UserID)
 	assert.EqualError(t, e, expected)
+	e = NotPrivilegedError{"test", errors.New("l'utilisateur n'est pas administrateur")}
+	assert.EqualError(t, e, "l'utilisateur n'est pas admin: id = test (l'utilisateur n'est pas administrateur)")
+	assert.EqualError(t, NotPrivilegedError{UserID: "test"}, "l'utilisateur n'est pas admin: id = test")
 }
 
 func TestErrors_ProtectedUserError(t *testing.T) {
 	e := ProtectedUserError{"test"}
-	expected := fmt.Sprintf("cet action est interdite sur cet utilisateur (%s)", e.id)
+	expected := fmt.Sprintf("cet action est interdite sur cet utilisateur (%s)", e.UserID)
 	assert.EqualError(t, e, expected)
 }
 
@@ -54,7 +57,7 @@ func TestErrors_InsertEmptyRuleError(t *testing.T) {
 
 func TestErrors_BoardLabelAlreadyExistsError(t *testing.T) {
 	e := BoardLabelAlreadyExistsError{BoardLabel{}, Board{}}
-	expected := fmt.Sprintf("un objet BoardLabel existe déjà dans la board (%s) avec le même nom (%s)", e.board.ID, e.boardLabel.Name)
+	expected := fmt.Sprintf("un objet BoardLabel existe déjà dans la board (%s) avec le même nom (%s)", e.Board.ID, e.BoardLabel.Name)
 	assert.EqualError(t, e, expected)
 }
 
@@ -74,7 +77,7 @@ func TestErrors_UnexpectedMongoDecodeError(t *testing.T) {
 
 func TestErrors_AlreadySetActityError(t *testing.T) {
 	e := AlreadySetActivityError{"test"}
-	expected := fmt.Sprintf("l'activité est déjà définie: activityType = %s", e.activityType)
+	expected := fmt.Sprintf("l'activité est déjà définie: activityType = %s", e.ActivityType)
 	assert.EqualError(t, e, expected)
 }
 
@@ -101,31 +104,31 @@ func TestErrors_ForbiddenOperationError(t *testing.T) {
 
 func TestErrors_NotImplemented(t *testing.T) {
 	e := NotImplemented{"test"}
-	expected := fmt.Sprintf("not implemented : " + e.method)
+	expected := fmt.Sprintf("not implemented : " + e.Method)
 	assert.EqualError(t, e, expected)
 }
 
 func TestErrors_CardNotFoundError(t *testing.T) {
 	e := CardNotFoundError{"test"}
-	expected := fmt.Sprintf("la carte n'existe pas (ID: %s)", e.cardID)
+	expected := fmt.Sprintf("la carte n'existe pas (ID: %s)", e.CardID)
 	assert.EqualError(t, e, expected)
 }
 
 func TestErrors_RuleNotFoundError(t *testing.T) {
 	e := RuleNotFoundError{"test"}
-	expected := fmt.Sprintf("la règle n'existe pas (ID: %s)", e.ruleID)
+	expected := fmt.Sprintf("la règle n'existe pas (ID: %s)", e.RuleID)
 	assert.EqualError(t, e, expected)
 }
 
 func TestErrors_ActionNotFoundError(t *testing.T) {
 	e := ActionNotFoundError{"test"}
-	expected := fmt.Sprintf("l'action n'existe pas (ID: %s)", e.actionId)
+	expected := fmt.Sprintf("l'action n'existe pas (ID: %s)", e.ActionID)
 	assert.EqualError(t, e, expected)
 }
 
 func TestErrors_TriggerNotFoundError(t *testing.T) {
 	e := TriggerNotFoundError{"test"}
-	expected := fmt.Sprintf("le trigger n'existe pas (ID: %s)", e.triggerId)
+	expected := fmt.Sprintf("le trigger n'existe pas (ID: %s)", e.TriggerID)
 	assert.EqualError(t, e, expected)
 }
 
@@ -136,11 +139,50 @@ func TestErrors_NothingDoneError(t *testing.T) {
 }
 func TestErrors_UnknownActivityError(t *testing.T) {
 	e := ActivityNotFoundError{"test"}
-	expected := fmt.Sprintf("l'activité n'existe pas (ID: %s)", e.key)
+	expected := fmt.Sprintf("l'activité n'existe pas (ID: %s)", e.Key)
 	assert.EqualError(t, e, expected)
 }
 func TestErrors_UserIsNotMemberError(t *testing.T) {
 	e := UserIsNotMemberError{"test"}
-	expected := fmt.Sprintf("cette action nécessite que l'utilisateur soit membre (id=%s)", e.userID)
-	assert.EqualError(t, e, expected)
+	expected := fmt.Sprintf("cette action nécessite que l'utilisateur soit membre (id=%s)", e.UserID)
+	assert.EqualError(t, e, expected)
+}
+
+func TestErrors_SwimlaneNotFoundError(t *testing.T) {
+	e := SwimlaneNotFoundError{"test"}
+	expected := fmt.Sprintf("la swimlane n'existe pas (ID: %s)", e.SwimlaneID)
+	assert.EqualError(t, e, expected)
+}
+
+func TestErrors_Is(t *testing.T) {
+	testCases := []struct {
+		err      error
+		sentinel error
+	}{
+		{UserNotFoundError{Key: "test"}, ErrNotFound},
+		{boardNotFoundWithId("test"), ErrNotFound},
+		{BoardLabelNotFoundError{}, ErrNotFound},
+		{ListNotFoundError{}, ErrNotFound},
+		{SwimlaneNotFoundError{}, ErrNotFound},
+		{CardNotFoundError{}, ErrNotFound},
+		{RuleNotFoundError{}, ErrNotFound},
+		{ActionNotFoundError{}, ErrNotFound},
+		{TriggerNotFoundError{}, ErrNotFound},
+		{ActivityNotFoundError{}, ErrNotFound},
+		{ForbiddenOperationError{ProtectedUserError{}}, ErrForbidden},
+		{NotPrivilegedError{}, ErrForbidden},
+		{ProtectedUserError{}, ErrForbidden},
+		{UserIsNotMemberError{}, ErrForbidden},
+		{NothingDoneError{}, ErrNothingDone},
+		{UserAlreadyExistsError{}, ErrAlreadyExists},
+		{BoardLabelAlreadyExistsError{}, ErrAlreadyExists},
+		{AlreadySetActivityError{}, ErrAlreadyExists},
+		{UnexpectedMongoError{CardNotFoundError{}}, ErrNotFound},
+	}
+	for _, testCase := range testCases {
+		assert.ErrorIs(t, testCase.err, testCase.sentinel, "%T", testCase.err)
+		assert.ErrorIs(t, fmt.Errorf("contexte : %w", testCase.err), testCase.sentinel, "%T", testCase.err)
+	}
+	assert.NotErrorIs(t, CardNotFoundError{}, ErrForbidden)
+	assert.NotErrorIs(t, UnexpectedMongoError{mongo.ErrNoDocuments}, ErrNotFound)
 }
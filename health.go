@@ -0,0 +1,101 @@
+package libwekan
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HealthReport synthétise l'état de la connexion, des privilèges et du périmètre de l'objet Wekan
+type HealthReport struct {
+	Reachable      bool                  `json:"reachable"`
+	Latency        time.Duration         `json:"latency"`
+	AdminExists    bool                  `json:"adminExists"`
+	AdminIsAdmin   bool                  `json:"adminIsAdmin"`
+	DomainBoards   int                   `json:"domainBoards"`
+	SchemaVersion  string                `json:"schemaVersion,omitempty"`
+	MissingIndexes []IndexRecommendation `json:"missingIndexes,omitempty"`
+	// Problems liste les vérifications en échec, le rapport est sain lorsqu'elle est vide
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Healthy est vrai lorsque la base est joignable, que l'utilisateur admin l'est vraiment et que la version est supportée.
+// Les index manquants dégradent les performances sans rendre le service indisponible.
+func (report HealthReport) Healthy() bool {
+	return len(report.Problems) == 0
+}
+
+// Health vérifie la connexion, les privilèges de l'utilisateur admin, le nombre de boards du domaine,
+// la version de Wekan et les index manquants. Le rapport est toujours renseigné,
+// une UnhealthyError est retournée lorsqu'une vérification bloquante échoue.
+func (wekan *Wekan) Health(ctx context.Context) (_ HealthReport, err error) {
+	ctx, end := wekan.observe(ctx, "Health", nil)
+	defer end(&err)
+	var report HealthReport
+
+	start := time.Now()
+	if err := wekan.db.Ping(ctx); err != nil {
+		report.Problems = append(report.Problems, UnreachableMongoError{err}.Error())
+		return report, UnhealthyError{report.Problems}
+	}
+	report.Reachable = true
+	report.Latency = time.Since(start)
+
+	admin, err := wekan.GetUserFromUsername(ctx, wekan.adminUsername)
+	if err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	} else {
+		report.AdminExists = true
+		report.AdminIsAdmin = admin.IsAdmin
+		if !admin.IsAdmin {
+			report.Problems = append(report.Problems, NotPrivilegedError{admin.ID, errors.New("l'utilisateur n'a pas le rôle administrateur")}.Error())
+		}
+	}
+
+	if count, err := wekan.countDomainBoards(ctx); err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	} else {
+		report.DomainBoards = count
+	}
+
+	if schema, err := wekan.detectSchema(ctx); err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	} else {
+		report.SchemaVersion = schema.Version.String()
+	}
+
+	if indexes, err := wekan.indexReport(ctx); err != nil {
+		report.Problems = append(report.Problems, err.Error())
+	} else {
+		report.MissingIndexes = indexes.Missing()
+	}
+
+	if !report.Healthy() {
+		return report, UnhealthyError{report.Problems}
+	}
+	return report, nil
+}
+
+func (wekan *Wekan) countDomainBoards(ctx context.Context) (int, error) {
+	pipeline := Pipeline{
+		bson.M{"$match": bson.M{"slug": primitive.Regex{Pattern: wekan.slugDomainRegexp, Options: "i"}}},
+		bson.M{"$count": "count"},
+	}
+	cur, err := wekan.db.Collection("boards").Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, UnexpectedMongoError{err}
+	}
+	var counts []struct {
+		Count int `bson:"count"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return 0, UnexpectedMongoDecodeError{err}
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].Count, nil
+}
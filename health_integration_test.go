@@ -0,0 +1,31 @@
+//go:build integration
+
+// nolint:errcheck
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Health(t *testing.T) {
+	ass := assert.New(t)
+
+	report, err := wekan.Health(ctx)
+
+	ass.NoError(err)
+	ass.True(report.Reachable)
+	ass.True(report.AdminIsAdmin)
+	ass.Equal("v6", report.SchemaVersion)
+}
+
+func TestHealth_Health_withUnreachableServer(t *testing.T) {
+	ass := assert.New(t)
+	badWekan := newTestBadWekan("notAWekanDB")
+
+	report, err := badWekan.Health(ctx)
+
+	ass.IsType(UnhealthyError{}, err)
+	ass.False(report.Reachable)
+}
@@ -0,0 +1,181 @@
+package libwekan
+
+import (
+	"context"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IndexRecommendation décrit un index nécessaire à une requête émise par libwekan
+type IndexRecommendation struct {
+	Collection string `json:"collection"`
+	Keys       bson.D `json:"keys"`
+	Reason     string `json:"reason"`
+}
+
+// IndexStatus indique si un index recommandé est couvert par un index existant ou a été créé
+type IndexStatus struct {
+	IndexRecommendation
+	// CoveredBy est le nom de l'index existant dont les premières clés correspondent à la recommandation
+	CoveredBy string `json:"coveredBy,omitempty"`
+	Created   bool   `json:"created,omitempty"`
+}
+
+// IndexReport compare les index existants et les index recommandés
+type IndexReport struct {
+	Existing    map[string][]string `json:"existing"`
+	Recommended []IndexStatus       `json:"recommended"`
+}
+
+type collectionIndex struct {
+	Name string `bson:"name"`
+	Key  bson.D `bson:"key"`
+}
+
+// Missing retourne les index recommandés qui ne sont ni couverts ni créés
+func (report IndexReport) Missing() []IndexRecommendation {
+	var missing []IndexRecommendation
+	for _, status := range report.Recommended {
+		if status.CoveredBy == "" && !status.Created {
+			missing = append(missing, status.IndexRecommendation)
+		}
+	}
+	return missing
+}
+
+// RecommendedIndexes retourne les index nécessaires aux requêtes émises par libwekan
+func (wekan *Wekan) RecommendedIndexes() []IndexRecommendation {
+	return []IndexRecommendation{
+		{"boards", bson.D{{Key: "slug", Value: 1}}, "GetBoardFromSlug, SelectDomainBoards, SelectConfig"},
+		{"boards", bson.D{{Key: "members.userId", Value: 1}}, "SelectBoardsFromMemberID"},
+		{"cards", bson.D{{Key: "boardId", Value: 1}}, "SelectCardsFromBoardID, BuildDomainCardsPipeline"},
+		{"cards", bson.D{{Key: "members", Value: 1}}, "SelectCardsFromMemberID"},
+		{"cards", bson.D{{Key: "userId", Value: 1}}, "SelectCardsFromUserID"},
+		{"cards", bson.D{{Key: "listId", Value: 1}}, "SelectCardsFromListID"},
+		{"cards", bson.D{{Key: "swimlaneId", Value: 1}}, "SelectCardsFromSwimlaneID"},
+		{"customFields", bson.D{{Key: "name", Value: 1}}, "BuildCardFromCustomTextFieldsPipeline"},
+		{"activities", bson.D{{Key: "cardId", Value: 1}}, "SelectActivitiesFromCardID"},
+		{"activities", bson.D{{Key: "boardId", Value: 1}}, "SelectActivitiesFromBoardID"},
+		{"lists", bson.D{{Key: "boardId", Value: 1}}, "SelectListsFromBoardID, SelectConfig"},
+		{"swimlanes", bson.D{{Key: "boardId", Value: 1}}, "GetSwimlanesFromBoardID, SelectConfig"},
+		{"rules", bson.D{{Key: "boardId", Value: 1}}, "SelectRulesFromBoardID"},
+		{wekan.schemaMapping().CommentsCollection, bson.D{{Key: "cardId", Value: 1}}, "GetCardWithCommentsFromID"},
+	}
+}
+
+// CheckIndexes retourne le rapport des index existants et recommandés sans rien modifier
+func (wekan *Wekan) CheckIndexes(ctx context.Context) (_ IndexReport, err error) {
+	ctx, end := wekan.observe(ctx, "CheckIndexes", nil)
+	defer end(&err)
+	return wekan.indexReport(ctx)
+}
+
+// EnsureIndexes crée les index recommandés qui ne sont pas couverts par un index existant
+func (wekan *Wekan) EnsureIndexes(ctx context.Context) (_ IndexReport, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureIndexes", nil)
+	defer end(&err)
+	if err := wekan.AssertPrivileged(ctx); err != nil {
+		return IndexReport{}, err
+	}
+	report, err := wekan.indexReport(ctx)
+	if err != nil {
+		return IndexReport{}, err
+	}
+	for i, status := range report.Recommended {
+		if status.CoveredBy != "" {
+			continue
+		}
+		name, err := wekan.db.Collection(status.Collection).CreateIndex(ctx, mongo.IndexModel{Keys: status.Keys})
+		if err != nil {
+			return report, UnexpectedMongoError{err}
+		}
+		report.Recommended[i].Created = true
+		report.Existing[status.Collection] = append(report.Existing[status.Collection], name)
+	}
+	return report, nil
+}
+
+func (wekan *Wekan) indexReport(ctx context.Context) (IndexReport, error) {
+	report := IndexReport{Existing: make(map[string][]string)}
+	indexes := make(map[string][]collectionIndex)
+	for _, recommendation := range wekan.RecommendedIndexes() {
+		collectionIndexes, ok := indexes[recommendation.Collection]
+		if !ok {
+			var err error
+			collectionIndexes, err = wekan.selectCollectionIndexes(ctx, recommendation.Collection)
+			if err != nil {
+				return IndexReport{}, err
+			}
+			indexes[recommendation.Collection] = collectionIndexes
+			report.Existing[recommendation.Collection] = mapSlice(collectionIndexes, func(index collectionIndex) string { return index.Name })
+		}
+		status := IndexStatus{IndexRecommendation: recommendation}
+		for _, index := range collectionIndexes {
+			if indexCovers(index.Key, recommendation.Keys) {
+				status.CoveredBy = index.Name
+				break
+			}
+		}
+		report.Recommended = append(report.Recommended, status)
+	}
+	return report, nil
+}
+
+func (wekan *Wekan) selectCollectionIndexes(ctx context.Context, collection string) ([]collectionIndex, error) {
+	cur, err := wekan.db.Collection(collection).ListIndexes(ctx)
+	if err != nil {
+		return nil, UnexpectedMongoError{err}
+	}
+	var indexes []collectionIndex
+	if err := cur.All(ctx, &indexes); err != nil {
+		return nil, UnexpectedMongoDecodeError{err}
+	}
+	return indexes, nil
+}
+
+// indexCovers vérifie que les clés recommandées sont un préfixe des clés de l'index, dans le même ordre et le même sens.
+// Un index sur un seul champ se parcourt dans les deux sens, son sens est alors ignoré.
+func indexCovers(key bson.D, recommended bson.D) bool {
+	if len(key) < len(recommended) {
+		return false
+	}
+	for i, element := range recommended {
+		if key[i].Key != element.Key {
+			return false
+		}
+		if len(recommended) == 1 && sameIndexAxis(key[i].Value, element.Value) {
+			continue
+		}
+		if !sameIndexDirection(key[i].Value, element.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameIndexDirection(a interface{}, b interface{}) bool {
+	return indexDirection(a) == indexDirection(b)
+}
+
+// sameIndexAxis compare deux sens d'index numériques sans tenir compte de leur signe
+func sameIndexAxis(a interface{}, b interface{}) bool {
+	directionA, okA := indexDirection(a).(float64)
+	directionB, okB := indexDirection(b).(float64)
+	return okA && okB && math.Abs(directionA) == math.Abs(directionB)
+}
+
+func indexDirection(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return value
+}
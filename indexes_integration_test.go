@@ -0,0 +1,25 @@
+//go:build integration
+
+// nolint:errcheck
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexes_EnsureIndexes(t *testing.T) {
+	ass := assert.New(t)
+
+	report, err := wekan.EnsureIndexes(ctx)
+
+	ass.NoError(err)
+	ass.Empty(report.Missing())
+	checked, err := wekan.CheckIndexes(ctx)
+	ass.NoError(err)
+	ass.Empty(checked.Missing())
+	for _, status := range checked.Recommended {
+		ass.NotEmpty(status.CoveredBy)
+	}
+}
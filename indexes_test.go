@@ -0,0 +1,36 @@
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIndexes_indexCovers(t *testing.T) {
+	ass := assert.New(t)
+	recommended := bson.D{{Key: "boardId", Value: 1}}
+
+	ass.True(indexCovers(bson.D{{Key: "boardId", Value: int32(1)}}, recommended))
+	ass.True(indexCovers(bson.D{{Key: "boardId", Value: 1.0}, {Key: "sort", Value: 1}}, recommended))
+	ass.False(indexCovers(bson.D{{Key: "sort", Value: 1}, {Key: "boardId", Value: 1}}, recommended))
+	ass.True(indexCovers(bson.D{{Key: "boardId", Value: -1}}, recommended))
+	ass.True(indexCovers(bson.D{{Key: "boardId", Value: int64(-1)}, {Key: "sort", Value: 1}}, recommended))
+	ass.False(indexCovers(bson.D{{Key: "boardId", Value: "hashed"}}, recommended))
+	ass.False(indexCovers(bson.D{}, recommended))
+
+	compound := bson.D{{Key: "boardId", Value: 1}, {Key: "sort", Value: 1}}
+	ass.True(indexCovers(bson.D{{Key: "boardId", Value: 1}, {Key: "sort", Value: 1}}, compound))
+	ass.False(indexCovers(bson.D{{Key: "boardId", Value: 1}, {Key: "sort", Value: -1}}, compound))
+}
+
+func TestIndexes_IndexReport_Missing(t *testing.T) {
+	report := IndexReport{
+		Recommended: []IndexStatus{
+			{IndexRecommendation: IndexRecommendation{Collection: "boards"}, CoveredBy: "slug_1"},
+			{IndexRecommendation: IndexRecommendation{Collection: "cards"}, Created: true},
+			{IndexRecommendation: IndexRecommendation{Collection: "rules"}},
+		},
+	}
+	assert.Equal(t, []IndexRecommendation{{Collection: "rules"}}, report.Missing())
+}
@@ -3,41 +3,53 @@ package libwekan
 import (
 	"context"
 	"errors"
-	"go.mongodb.org/mongo-driver/mongo"
+
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Wekan struct {
 	url              string
 	databaseName     string
-	client           *mongo.Client
-	db               *mongo.Database
+	db               Storage
 	adminUsername    Username
 	adminUserID      UserID
+	actorUserID      UserID
 	privileged       *bool
 	slugDomainRegexp string
+	observer         Observer
+	retryPolicy      RetryPolicy
+	schema           *Schema
 }
 
 // Init retourne un objet de type `Wekan`
 func Init(ctx context.Context, uri string, databaseName string, adminUsername Username, slugDomainRegexp string) (Wekan, error) {
-	clientOptions := options.Client().ApplyURI(uri)
-	client, err := mongo.Connect(ctx, clientOptions)
-	if err != nil {
-		return Wekan{}, InvalidMongoConfigurationError{err}
-	}
-	w := Wekan{
-		url:              uri,
-		databaseName:     databaseName,
-		client:           client,
-		db:               client.Database(databaseName),
+	return InitWithOptions(ctx, uri, databaseName, adminUsername, slugDomainRegexp)
+}
+
+// InitWithStorage retourne un objet `Wekan` s'appuyant sur l'implémentation de Storage fournie,
+// les options de connexion sont ignorées
+func InitWithStorage(storage Storage, adminUsername Username, slugDomainRegexp string, opts ...Option) Wekan {
+	o := applyInitOptions(options.Client(), opts...)
+	return Wekan{
+		db:               o.storage(storage),
 		adminUsername:    adminUsername,
 		slugDomainRegexp: slugDomainRegexp,
+		observer:         o.observer,
+		retryPolicy:      o.retryPolicy,
 	}
-	return w, nil
 }
 
-func (wekan *Wekan) Ping(ctx context.Context) error {
-	return wekan.client.Ping(ctx, nil)
+func (wekan *Wekan) Ping(ctx context.Context) (err error) {
+	ctx, end := wekan.observe(ctx, "Ping", nil)
+	defer end(&err)
+	return wekan.db.Ping(ctx)
+}
+
+// WithTransaction exécute fn dans une transaction lorsque le serveur le permet (replica set ou mongos),
+// sur un serveur standalone fn est exécutée sans transaction.
+// Toutes les opérations de fn doivent utiliser le contexte qui lui est transmis.
+func (wekan *Wekan) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return wekan.db.WithTransaction(ctx, fn)
 }
 
 // AssertPrivileged s'assure que l'utilisateur déclaré dans la propriété
@@ -55,11 +67,9 @@ func (wekan *Wekan) AssertPrivileged(ctx context.Context) error {
 	}
 	wekan.adminUserID = admin.ID
 	wekan.privileged = &admin.IsAdmin
-	//if !admin.IsAdmin {
-	//	return ForbiddenOperationError{
-	//		NotPrivilegedError{admin.ID, errors.New("L'utilisateur n'est pas administration")},
-	//	}
-	//}
+	if !admin.IsAdmin {
+		return NotPrivilegedError{admin.ID, errors.New("l'utilisateur n'est pas administrateur")}
+	}
 	return nil
 }
 
@@ -71,6 +81,24 @@ func (wekan *Wekan) AdminID() UserID {
 	return wekan.adminUserID
 }
 
+// As retourne une copie de l'objet Wekan agissant pour le compte de l'utilisateur fourni :
+// les activités et les documents créés lui sont attribués, les vérifications de privilèges restent
+// effectuées avec l'utilisateur admin
+func (wekan *Wekan) As(user User) Wekan {
+	actorWekan := *wekan
+	actorWekan.actorUserID = user.ID
+	return actorWekan
+}
+
+// ActorID retourne l'identifiant de l'utilisateur auquel sont attribuées les écritures,
+// l'utilisateur admin en l'absence d'appel à As
+func (wekan *Wekan) ActorID() UserID {
+	if wekan.actorUserID != "" {
+		return wekan.actorUserID
+	}
+	return wekan.adminUserID
+}
+
 //
 //func (wekan *Wekan) IsPrivileged() bool {
 //	return *wekan.privileged
@@ -67,7 +67,7 @@ func TestMain(m *testing.M) {
 		if err != nil {
 			return err
 		}
-		return wekan.client.Ping(context.TODO(), nil)
+		return wekan.Ping(context.TODO())
 	}); err != nil {
 		fmt.Printf("N'arrive pas à démarrer/restaurer Mongo: %s", err)
 	}
@@ -150,8 +150,7 @@ func newTestBadWekan(dbname string) Wekan {
 	clientOptions := options.Client().ApplyURI("mongodb://127.0.0.1:80").SetConnectTimeout(time.Millisecond).SetTimeout(time.Millisecond)
 	client, _ := mongo.Connect(context.Background(), clientOptions)
 	return Wekan{
-		client: client,
-		db:     client.Database(dbname),
+		db: newMongoStorage(client, dbname),
 	}
 }
 
@@ -0,0 +1,155 @@
+package libwekan
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// Option paramètre la connexion établie par InitWithOptions
+type Option func(*initOptions)
+
+type initOptions struct {
+	clientOptions *options.ClientOptions
+	client        *mongo.Client
+	logger        Logger
+	logLevels     LogLevels
+	observer      Observer
+	retryPolicy   RetryPolicy
+}
+
+// WithConnectTimeout fixe le délai maximal d'établissement d'une connexion
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetConnectTimeout(timeout)
+	}
+}
+
+// WithOperationTimeout fixe le délai maximal d'exécution de chaque opération
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetTimeout(timeout)
+	}
+}
+
+// WithServerSelectionTimeout fixe le délai maximal de sélection d'un serveur
+func WithServerSelectionTimeout(timeout time.Duration) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetServerSelectionTimeout(timeout)
+	}
+}
+
+// WithTLSConfig active TLS avec la configuration fournie
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetTLSConfig(config)
+	}
+}
+
+// WithMaxPoolSize fixe le nombre maximal de connexions du pool
+func WithMaxPoolSize(size uint64) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetMaxPoolSize(size)
+	}
+}
+
+// WithMinPoolSize fixe le nombre minimal de connexions du pool
+func WithMinPoolSize(size uint64) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetMinPoolSize(size)
+	}
+}
+
+// WithReadPreference fixe la préférence de lecture (primary, secondaryPreferred, …)
+func WithReadPreference(preference *readpref.ReadPref) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetReadPreference(preference)
+	}
+}
+
+// WithReadConcern fixe le niveau d'isolation des lectures
+func WithReadConcern(concern *readconcern.ReadConcern) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetReadConcern(concern)
+	}
+}
+
+// WithWriteConcern fixe le niveau d'acquittement des écritures
+func WithWriteConcern(concern *writeconcern.WriteConcern) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetWriteConcern(concern)
+	}
+}
+
+// WithAppName fixe le nom d'application transmis au serveur, visible dans les logs mongodb
+func WithAppName(name string) Option {
+	return func(o *initOptions) {
+		o.clientOptions.SetAppName(name)
+	}
+}
+
+// WithClient utilise un client mongodb déjà connecté, l'uri et les autres options de connexion sont alors ignorées
+func WithClient(client *mongo.Client) Option {
+	return func(o *initOptions) {
+		o.client = client
+	}
+}
+
+// WithLogger trace les opérations effectuées sur la base, aucune trace n'est émise en l'absence de logger
+func WithLogger(logger Logger) Option {
+	return func(o *initOptions) {
+		o.logger = logger
+	}
+}
+
+// WithLogLevels fixe les niveaux de trace utilisés par le logger, DefaultLogLevels par défaut
+func WithLogLevels(levels LogLevels) Option {
+	return func(o *initOptions) {
+		o.logLevels = levels
+	}
+}
+
+func buildInitOptions(uri string, opts ...Option) initOptions {
+	return applyInitOptions(options.Client().ApplyURI(uri), opts...)
+}
+
+func applyInitOptions(clientOptions *options.ClientOptions, opts ...Option) initOptions {
+	o := initOptions{clientOptions: clientOptions, logLevels: DefaultLogLevels}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// storage décore le stockage avec la journalisation de chaque tentative puis le rejeu des lectures
+func (o initOptions) storage(storage Storage) Storage {
+	return newRetryingStorage(newLoggingStorage(storage, o.logger, o.logLevels), o.retryPolicy)
+}
+
+// InitWithOptions retourne un objet `Wekan` dont la connexion est paramétrée par les options fournies
+func InitWithOptions(ctx context.Context, uri string, databaseName string, adminUsername Username, slugDomainRegexp string, opts ...Option) (Wekan, error) {
+	o := buildInitOptions(uri, opts...)
+	client := o.client
+	if client == nil {
+		var err error
+		client, err = mongo.Connect(ctx, o.clientOptions)
+		if err != nil {
+			return Wekan{}, InvalidMongoConfigurationError{err}
+		}
+	}
+	return Wekan{
+		url:              uri,
+		databaseName:     databaseName,
+		db:               o.storage(newMongoStorage(client, databaseName)),
+		adminUsername:    adminUsername,
+		slugDomainRegexp: slugDomainRegexp,
+		observer:         o.observer,
+		retryPolicy:      o.retryPolicy,
+	}, nil
+}
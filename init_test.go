@@ -1,8 +1,15 @@
 package libwekan
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"crypto/tls"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func TestWekan_AdminUsername(t *testing.T) {
@@ -27,6 +34,65 @@ func TestWekan_AdminID(t *testing.T) {
 	assert.Equal(t, unitWekan.AdminID(), expectedID)
 }
 
+func TestWekan_As(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	unitWekan := Wekan{
+		adminUserID: UserID("admin"),
+	}
+
+	// WHEN
+	actorWekan := unitWekan.As(User{ID: "actor"})
+
+	// THEN
+	ass.Equal(UserID("actor"), actorWekan.ActorID())
+	ass.Equal(UserID("admin"), actorWekan.AdminID())
+	ass.Equal(UserID("admin"), unitWekan.ActorID())
+}
+
+func TestWekan_buildInitOptions(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	tlsConfig := &tls.Config{ServerName: "wekan"}
+
+	// WHEN
+	o := buildInitOptions("mongodb://localhost:27017",
+		WithConnectTimeout(time.Second),
+		WithOperationTimeout(2*time.Second),
+		WithTLSConfig(tlsConfig),
+		WithMaxPoolSize(20),
+		WithMinPoolSize(2),
+		WithReadPreference(readpref.SecondaryPreferred()),
+		WithAppName("libwekan-test"),
+	)
+
+	// THEN
+	ass.Equal(time.Second, *o.clientOptions.ConnectTimeout)
+	ass.Equal(2*time.Second, *o.clientOptions.Timeout)
+	ass.Same(tlsConfig, o.clientOptions.TLSConfig)
+	ass.Equal(uint64(20), *o.clientOptions.MaxPoolSize)
+	ass.Equal(uint64(2), *o.clientOptions.MinPoolSize)
+	ass.Equal(readpref.SecondaryPreferred().Mode(), o.clientOptions.ReadPreference.Mode())
+	ass.Equal("libwekan-test", *o.clientOptions.AppName)
+	ass.Equal([]string{"localhost:27017"}, o.clientOptions.Hosts)
+	ass.Nil(o.client)
+}
+
+func TestWekan_InitWithOptions_withClient(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	ass.NoError(err)
+
+	// WHEN
+	unitWekan, err := InitWithOptions(context.Background(), "", "wekan", "admin", "^tableau-crp.*", WithClient(client))
+
+	// THEN
+	ass.NoError(err)
+	ass.Same(client, unitWekan.db.(mongoStorage).client)
+	ass.Equal("wekan", unitWekan.db.(mongoStorage).db.Name())
+}
+
 //func TestWekan_IsPrivileged(t *testing.T) {
 //	// GIVEN
 //	unitWekan := Wekan{
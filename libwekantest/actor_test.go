@@ -0,0 +1,39 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAs_recordsActorInActivitiesAndCards(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	// GIVEN
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-actor")
+	actor := createTestUser(t, &wekan, "actor")
+	member := createTestUser(t, &wekan, "member")
+	actorWekan := wekan.As(actor)
+
+	// WHEN
+	_, err := actorWekan.EnsureUserIsActiveBoardMember(ctx, board.ID, member.ID)
+	ass.NoError(err)
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), wekan.AdminID())
+	ass.NoError(actorWekan.InsertCard(ctx, card))
+
+	// THEN
+	activities, err := wekan.SelectActivitiesFromBoardID(ctx, board.ID)
+	ass.NoError(err)
+	actorActivities := map[string]libwekan.UserID{}
+	for _, activity := range activities {
+		actorActivities[activity.ActivityType] = activity.UserID
+	}
+	ass.Equal(wekan.AdminID(), actorActivities["createBoard"])
+	ass.Equal(actor.ID, actorActivities["addBoardMember"])
+	ass.Equal(actor.ID, actorActivities["createCard"])
+	actualCard, err := wekan.GetCardFromID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Equal(actor.ID, actualCard.UserID)
+	ass.Equal(wekan.AdminID(), actorWekan.AdminID())
+}
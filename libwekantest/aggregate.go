@@ -0,0 +1,477 @@
+package libwekantest
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// aggregate exécute les étapes du pipeline sur les documents, le verrou du stockage doit être détenu
+func aggregate(storage *Storage, documents []bson.M, stages []bson.M, vars bson.M) ([]bson.M, error) {
+	var err error
+	for _, stage := range stages {
+		for operator, argument := range stage {
+			documents, err = aggregateStage(storage, documents, operator, argument, vars)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", operator, err)
+			}
+		}
+	}
+	return documents, nil
+}
+
+func aggregateStage(storage *Storage, documents []bson.M, operator string, argument interface{}, vars bson.M) ([]bson.M, error) {
+	switch operator {
+	case "$match":
+		filter, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageMatch(documents, filter, vars)
+	case "$project":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageProject(documents, specification, vars)
+	case "$addFields", "$set":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageAddFields(documents, specification, vars)
+	case "$unset":
+		return stageUnset(documents, argument)
+	case "$lookup":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageLookup(storage, documents, specification, vars)
+	case "$unwind":
+		return stageUnwind(documents, argument)
+	case "$replaceRoot":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageReplaceRoot(documents, specification["newRoot"], vars)
+	case "$replaceWith":
+		return stageReplaceRoot(documents, argument, vars)
+	case "$group":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageGroup(documents, specification, vars)
+	case "$facet":
+		specification, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("un document est attendu")
+		}
+		return stageFacet(storage, documents, specification, vars)
+	case "$sort":
+		return sortDocuments(documents, argument)
+	case "$skip":
+		skip, _ := toFloat(argument)
+		return documents[minInt(int(skip), len(documents)):], nil
+	case "$limit":
+		limit, _ := toFloat(argument)
+		return documents[:minInt(int(limit), len(documents))], nil
+	case "$count":
+		field, ok := argument.(string)
+		if !ok {
+			return nil, fmt.Errorf("un nom de champ est attendu")
+		}
+		if len(documents) == 0 {
+			return nil, nil
+		}
+		return []bson.M{{field: int32(len(documents))}}, nil
+	}
+	return nil, fmt.Errorf("étape d'agrégation non supportée")
+}
+
+func stageMatch(documents []bson.M, filter bson.M, vars bson.M) ([]bson.M, error) {
+	var matched []bson.M
+	for _, document := range documents {
+		ok, err := matchDocument(document, filter, vars)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, document)
+		}
+	}
+	return matched, nil
+}
+
+// stageFacet exécute chaque sous-pipeline sur une copie des documents et produit un document unique
+func stageFacet(storage *Storage, documents []bson.M, specification bson.M, vars bson.M) ([]bson.M, error) {
+	result := bson.M{}
+	for field, pipeline := range specification {
+		stages, err := normalizePipeline(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		copied := make([]bson.M, len(documents))
+		for i, document := range documents {
+			copied[i] = deepCopy(document).(bson.M)
+		}
+		facet, err := aggregate(storage, copied, stages, vars)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		array := bson.A{}
+		for _, document := range facet {
+			array = append(array, document)
+		}
+		result[field] = array
+	}
+	return []bson.M{result}, nil
+}
+
+func isExclusion(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return !b
+	}
+	if f, ok := toFloat(value); ok {
+		return f == 0
+	}
+	return false
+}
+
+func isInclusion(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+	return false
+}
+
+func stageProject(documents []bson.M, specification bson.M, vars bson.M) ([]bson.M, error) {
+	exclusionOnly := true
+	for key, value := range specification {
+		if key != "_id" && !isExclusion(value) {
+			exclusionOnly = false
+		}
+	}
+	projected := make([]bson.M, 0, len(documents))
+	for _, document := range documents {
+		var result bson.M
+		if exclusionOnly {
+			result = deepCopy(document).(bson.M)
+			for key := range specification {
+				unsetPath(result, key)
+			}
+			projected = append(projected, result)
+			continue
+		}
+		result = bson.M{}
+		if id, ok := document["_id"]; ok && !isExclusion(specification["_id"]) {
+			result["_id"] = id
+		}
+		for key, value := range specification {
+			switch {
+			case isExclusion(value):
+				delete(result, key)
+			case isInclusion(value):
+				if v, ok := getPath(document, key); ok {
+					setPath(result, key, deepCopy(v))
+				}
+			default:
+				v, err := evaluate(document, value, vars)
+				if err != nil {
+					return nil, err
+				}
+				setPath(result, key, v)
+			}
+		}
+		projected = append(projected, result)
+	}
+	return projected, nil
+}
+
+func stageAddFields(documents []bson.M, specification bson.M, vars bson.M) ([]bson.M, error) {
+	for i, document := range documents {
+		result := deepCopy(document).(bson.M)
+		for key, value := range specification {
+			v, err := evaluate(document, value, vars)
+			if err != nil {
+				return nil, err
+			}
+			setPath(result, key, v)
+		}
+		documents[i] = result
+	}
+	return documents, nil
+}
+
+func stageUnset(documents []bson.M, argument interface{}) ([]bson.M, error) {
+	fields := bson.A{argument}
+	if array, ok := argument.(bson.A); ok {
+		fields = array
+	}
+	for _, document := range documents {
+		for _, field := range fields {
+			if path, ok := field.(string); ok {
+				unsetPath(document, path)
+			}
+		}
+	}
+	return documents, nil
+}
+
+func stageLookup(storage *Storage, documents []bson.M, specification bson.M, vars bson.M) ([]bson.M, error) {
+	from, _ := specification["from"].(string)
+	as, _ := specification["as"].(string)
+	if from == "" || as == "" {
+		return nil, fmt.Errorf("les champs from et as sont obligatoires")
+	}
+	localField, _ := specification["localField"].(string)
+	foreignField, _ := specification["foreignField"].(string)
+	let, _ := specification["let"].(bson.M)
+	var subPipeline []bson.M
+	if pipeline, ok := specification["pipeline"]; ok {
+		stages, err := normalizePipeline(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		subPipeline = stages
+	}
+
+	for i, document := range documents {
+		foreignDocuments := storage.documents(from)
+		if localField != "" && foreignField != "" {
+			localValues := candidates(resolvePath(document, strings.Split(localField, ".")))
+			if len(localValues) == 0 {
+				localValues = []interface{}{nil}
+			}
+			var joined []bson.M
+			for _, foreignDocument := range foreignDocuments {
+				foreignValues := resolvePath(foreignDocument, strings.Split(foreignField, "."))
+				for _, localValue := range localValues {
+					if matchEquality(foreignValues, localValue) {
+						joined = append(joined, foreignDocument)
+						break
+					}
+				}
+			}
+			foreignDocuments = joined
+		}
+
+		lookupVars := bson.M{}
+		for name, value := range vars {
+			lookupVars[name] = value
+		}
+		for name, expression := range let {
+			value, err := evaluate(document, expression, vars)
+			if err != nil {
+				return nil, err
+			}
+			lookupVars[name] = value
+		}
+		joined, err := aggregate(storage, foreignDocuments, subPipeline, lookupVars)
+		if err != nil {
+			return nil, err
+		}
+		array := bson.A{}
+		for _, joinedDocument := range joined {
+			array = append(array, joinedDocument)
+		}
+		result := deepCopy(document).(bson.M)
+		setPath(result, as, array)
+		documents[i] = result
+	}
+	return documents, nil
+}
+
+func stageUnwind(documents []bson.M, argument interface{}) ([]bson.M, error) {
+	var path string
+	var preserve bool
+	switch specification := argument.(type) {
+	case string:
+		path = specification
+	case bson.M:
+		path, _ = specification["path"].(string)
+		preserve = truthy(specification["preserveNullAndEmptyArrays"])
+	}
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("le chemin doit commencer par $")
+	}
+	path = path[1:]
+
+	var unwound []bson.M
+	for _, document := range documents {
+		value, exists := getPath(document, path)
+		array, isArray := value.(bson.A)
+		switch {
+		case isArray && len(array) > 0:
+			for _, element := range array {
+				result := deepCopy(document).(bson.M)
+				setPath(result, path, deepCopy(element))
+				unwound = append(unwound, result)
+			}
+		case isArray || !exists || value == nil:
+			if preserve {
+				result := deepCopy(document).(bson.M)
+				if isArray {
+					unsetPath(result, path)
+				}
+				unwound = append(unwound, result)
+			}
+		default:
+			unwound = append(unwound, document)
+		}
+	}
+	return unwound, nil
+}
+
+func stageReplaceRoot(documents []bson.M, newRoot interface{}, vars bson.M) ([]bson.M, error) {
+	replaced := make([]bson.M, 0, len(documents))
+	for _, document := range documents {
+		value, err := evaluate(document, newRoot, vars)
+		if err != nil {
+			return nil, err
+		}
+		root, ok := value.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("newRoot doit être un document, %T obtenu", value)
+		}
+		replaced = append(replaced, root)
+	}
+	return replaced, nil
+}
+
+type group struct {
+	id       interface{}
+	document bson.M
+	counts   map[string]int
+}
+
+func stageGroup(documents []bson.M, specification bson.M, vars bson.M) ([]bson.M, error) {
+	var groups []*group
+	for _, document := range documents {
+		id, err := evaluate(document, specification["_id"], vars)
+		if err != nil {
+			return nil, err
+		}
+		var current *group
+		for _, g := range groups {
+			if equalValues(g.id, id) {
+				current = g
+				break
+			}
+		}
+		if current == nil {
+			current = &group{id: id, document: bson.M{"_id": id}, counts: make(map[string]int)}
+			groups = append(groups, current)
+		}
+		for field, accumulator := range specification {
+			if field == "_id" {
+				continue
+			}
+			if err := accumulate(current, field, accumulator, document, vars); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	grouped := make([]bson.M, 0, len(groups))
+	for _, g := range groups {
+		for field, accumulator := range specification {
+			if operator, ok := accumulator.(bson.M); ok {
+				if _, ok := operator["$avg"]; ok && g.counts[field] > 0 {
+					sum, _ := toFloat(g.document[field])
+					g.document[field] = sum / float64(g.counts[field])
+				}
+			}
+		}
+		grouped = append(grouped, g.document)
+	}
+	return grouped, nil
+}
+
+func accumulate(g *group, field string, accumulator interface{}, document bson.M, vars bson.M) error {
+	specification, ok := accumulator.(bson.M)
+	if !ok || len(specification) != 1 {
+		return fmt.Errorf("accumulateur invalide pour le champ %s", field)
+	}
+	for operator, expression := range specification {
+		value, err := evaluate(document, expression, vars)
+		if err != nil {
+			return err
+		}
+		current, exists := g.document[field]
+		switch operator {
+		case "$push", "$addToSet":
+			array, _ := current.(bson.A)
+			if array == nil {
+				array = bson.A{}
+			}
+			if operator == "$push" || !matchEquality(array, value) {
+				array = append(array, value)
+			}
+			g.document[field] = array
+		case "$sum", "$avg":
+			increment, ok := toFloat(value)
+			if !ok {
+				increment = 0
+			}
+			if !exists {
+				current = int32(0)
+			}
+			sum, _ := toFloat(current)
+			g.document[field] = addNumbers(current, value, sum+increment)
+			if ok {
+				g.counts[field]++
+			}
+		case "$first":
+			if !exists {
+				g.document[field] = value
+			}
+		case "$last":
+			g.document[field] = value
+		case "$min":
+			if !exists || compareValues(value, current) < 0 {
+				g.document[field] = value
+			}
+		case "$max":
+			if !exists || compareValues(value, current) > 0 {
+				g.document[field] = value
+			}
+		default:
+			return fmt.Errorf("accumulateur non supporté : %s", operator)
+		}
+	}
+	return nil
+}
+
+func setPath(document bson.M, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	current := document
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(bson.M)
+		if !ok {
+			next = bson.M{}
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+func unsetPath(document bson.M, path string) {
+	keys := strings.Split(path, ".")
+	current := document
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(bson.M)
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, keys[len(keys)-1])
+}
@@ -0,0 +1,148 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func createSpecBoard(t *testing.T, wekan *libwekan.Wekan) libwekan.Board {
+	spec, err := libwekan.ParseBoardSpec([]byte(testBoardSpec))
+	require.NoError(t, err)
+	plan, err := wekan.Plan(ctx, spec)
+	require.NoError(t, err)
+	boardID, err := wekan.Apply(ctx, plan)
+	require.NoError(t, err)
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	require.NoError(t, err)
+	return board
+}
+
+func TestBoardClone_CloneBoard_remapsIDs(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	source := createSpecBoard(t, &wekan)
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	list, _ := config.ListByTitle(source.ID, "En cours")
+	swimlane, _ := config.SwimlaneByTitle(source.ID, "Suivi")
+	label, _ := config.LabelByName(source.ID, "urgent")
+	card := libwekan.BuildCard(source.ID, list.ID, swimlane.ID, "carte", "", wekan.ActorID())
+	card.LabelIDs = []libwekan.BoardLabelID{label.ID}
+	require.NoError(t, wekan.InsertCard(ctx, card))
+
+	// WHEN
+	clone, err := wekan.CloneBoard(ctx, source.ID, "Copie", "tableau-crp-copie", libwekan.CloneBoardOptions{Cards: true})
+
+	// THEN
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardSlug("tableau-crp-copie"), clone.Board.Slug)
+	ass.NotEqual(source.ID, clone.Board.ID)
+	ass.True(clone.Board.UserIsActiveMember(libwekan.User{ID: wekan.ActorID()}))
+	config, err = wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	clonedList, ok := config.ListByTitle(clone.Board.ID, "En cours")
+	ass.True(ok)
+	ass.Equal(clone.Lists[list.ID], clonedList.ID)
+	_, ok = config.CustomFieldByName(clone.Board.ID, "SIRET")
+	ass.True(ok)
+	clonedLabel, ok := config.LabelByName(clone.Board.ID, "urgent")
+	ass.True(ok)
+	ass.Equal(clone.Labels[label.ID], clonedLabel.ID)
+	ass.NotEqual(label.ID, clonedLabel.ID)
+
+	rules, err := wekan.SelectRulesFromBoardID(ctx, clone.Board.ID)
+	ass.NoError(err)
+	ass.Len(rules, 1)
+	ass.Equal(clonedLabel.ID, rules[0].Trigger.LabelID)
+	ass.Equal(clone.Board.ID, rules[0].Action.BoardID)
+
+	cards, err := wekan.SelectCardsFromBoardID(ctx, clone.Board.ID)
+	ass.NoError(err)
+	ass.Len(cards, 1)
+	ass.Equal(clone.Cards[card.ID], cards[0].ID)
+	ass.Equal(clonedList.ID, cards[0].ListID)
+	ass.Equal([]libwekan.BoardLabelID{clonedLabel.ID}, cards[0].LabelIDs)
+
+	sourceCards, err := wekan.SelectCardsFromBoardID(ctx, source.ID)
+	ass.NoError(err)
+	ass.Len(sourceCards, 1)
+}
+
+func TestBoardClone_CloneBoard_existingSlug(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	source := createSpecBoard(t, &wekan)
+
+	_, err := wekan.CloneBoard(ctx, source.ID, "Copie", source.Slug, libwekan.CloneBoardOptions{})
+	ass.ErrorIs(err, libwekan.ErrAlreadyExists)
+	ass.IsType(libwekan.BoardAlreadyExistsError{}, err)
+
+	_, err = wekan.CloneBoard(ctx, "inexistante", "Copie", "tableau-crp-copie", libwekan.CloneBoardOptions{})
+	ass.IsType(libwekan.BoardNotFoundError{}, err)
+}
+
+func TestBoardClone_CloneBoard_outsideDomain(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	source := createSpecBoard(t, &wekan)
+	boardsCount := storage.Count("boards")
+
+	_, err := wekan.CloneBoard(ctx, source.ID, "Copie", "autre-tableau", libwekan.CloneBoardOptions{})
+
+	ass.ErrorIs(err, libwekan.ErrForbidden)
+	ass.Equal(boardsCount, storage.Count("boards"))
+}
+
+func TestBoardClone_CloneBoard_skipsRulesOfMissingLabels(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	source := createSpecBoard(t, &wekan)
+	sourceRules, err := wekan.SelectRulesFromBoardID(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, sourceRules, 1)
+	// étiquette retirée de la board sans passer par DeleteBoardLabel, la règle la désigne toujours
+	_, err = storage.Collection("boards").UpdateOne(ctx, bson.M{"_id": source.ID},
+		bson.M{"$pull": bson.M{"labels": bson.M{"_id": sourceRules[0].Trigger.LabelID}}})
+	require.NoError(t, err)
+
+	// WHEN
+	clone, err := wekan.CloneBoard(ctx, source.ID, "Copie", "tableau-crp-copie", libwekan.CloneBoardOptions{})
+
+	// THEN
+	require.NoError(t, err)
+	ass.Equal([]libwekan.RuleID{sourceRules[0].ID}, clone.SkippedRules)
+	ass.Empty(clone.Rules)
+	rules, err := wekan.SelectRulesFromBoardID(ctx, clone.Board.ID)
+	ass.NoError(err)
+	ass.Empty(rules)
+}
+
+func TestBoardClone_CloneBoard_skipsListsOfArchivedSwimlanes(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	source := createSpecBoard(t, &wekan)
+	archivedSwimlane := libwekan.BuildSwimlane(source.ID, "swimlane", "Archivée", 1)
+	archivedSwimlane.Archived = true
+	require.NoError(t, wekan.InsertSwimlane(ctx, archivedSwimlane))
+	list := libwekan.BuildList(source.ID, "Liste de la swimlane archivée", 3)
+	list.SwimlaneID = string(archivedSwimlane.ID)
+	require.NoError(t, wekan.InsertList(ctx, list))
+
+	// WHEN
+	clone, err := wekan.CloneBoard(ctx, source.ID, "Copie", "tableau-crp-copie", libwekan.CloneBoardOptions{})
+
+	// THEN
+	require.NoError(t, err)
+	ass.NotContains(clone.Swimlanes, archivedSwimlane.ID)
+	ass.NotContains(clone.Lists, list.ID)
+	lists, err := wekan.SelectListsFromBoardID(ctx, clone.Board.ID)
+	ass.NoError(err)
+	for _, clonedList := range lists {
+		ass.NotEqual(list.Title, clonedList.Title)
+		ass.NotEqual(string(archivedSwimlane.ID), clonedList.SwimlaneID)
+	}
+}
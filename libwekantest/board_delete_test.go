@@ -0,0 +1,114 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBoardDelete_DeleteBoard_cascades(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	clone, err := wekan.CloneBoard(ctx, board.ID, "Copie", "tableau-crp-copie", libwekan.CloneBoardOptions{})
+	require.NoError(t, err)
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	list, _ := config.ListByTitle(board.ID, "A traiter")
+	swimlane, _ := config.SwimlaneByTitle(board.ID, "Suivi")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, "carte", "", wekan.ActorID())
+	require.NoError(t, wekan.InsertCard(ctx, card))
+	require.NoError(t, storage.Insert("card_comments", bson.M{"_id": "comment", "boardId": board.ID, "cardId": card.ID, "text": "commentaire"}))
+	require.NoError(t, storage.Insert("checklists", bson.M{"_id": "checklist", "cardId": card.ID, "title": "checklist"}))
+	require.NoError(t, storage.Insert("checklistItems", bson.M{"_id": "item", "cardId": card.ID, "checklistId": "checklist"}))
+	require.NoError(t, storage.Insert("attachments", bson.M{"_id": "attachment", "meta": bson.M{"boardId": board.ID, "cardId": card.ID}}))
+	field := libwekan.BuildCustomField("propre", "text", board.ID)
+	require.NoError(t, wekan.InsertCustomField(ctx, field))
+	cloneCounts := map[string]int{"swimlanes": storage.Count("swimlanes"), "lists": storage.Count("lists")}
+
+	// WHEN
+	deletion, err := wekan.DeleteBoard(ctx, board.ID, libwekan.DeleteBoardOptions{})
+
+	// THEN
+	require.NoError(t, err)
+	ass.False(deletion.Archived)
+	ass.Equal(board.ID, deletion.Board.ID)
+	ass.Equal(1, deletion.Deleted["boards"])
+	ass.Equal(1, deletion.Deleted["cards"])
+	ass.Equal(3, deletion.Deleted["lists"])
+	ass.Equal(1, deletion.Deleted["rules"])
+	ass.Equal(1, deletion.Deleted["customFields"])
+	for _, collection := range []string{"card_comments", "checklists", "checklistItems", "attachments", "triggers", "actions"} {
+		ass.Equal(1, deletion.Deleted[collection], collection)
+	}
+	ass.Positive(deletion.Deleted["activities"])
+	_, err = wekan.GetBoardFromID(ctx, board.ID)
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	ass.Equal(cloneCounts["lists"]-3, storage.Count("lists"))
+	ass.Equal(cloneCounts["swimlanes"]-1, storage.Count("swimlanes"))
+	ass.Equal(0, storage.Count("cards"))
+
+	// les objets de la copie sont conservés, le champ partagé n'est plus rattaché qu'à elle
+	config, err = wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	sharedField, ok := config.CustomFieldByName(clone.Board.ID, "SIRET")
+	ass.True(ok)
+	ass.Equal([]libwekan.BoardID{clone.Board.ID}, sharedField.BoardIDs)
+	rules, err := wekan.SelectRulesFromBoardID(ctx, clone.Board.ID)
+	ass.NoError(err)
+	ass.Len(rules, 1)
+}
+
+func TestBoardDelete_DeleteBoard_archiveOnlyAndDomain(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-suppression")
+	outside, _, _ := createTestBoard(t, &wekan, "templates")
+
+	deletion, err := wekan.DeleteBoard(ctx, board.ID, libwekan.DeleteBoardOptions{ArchiveOnly: true})
+	ass.NoError(err)
+	ass.True(deletion.Archived)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	ass.NoError(err)
+	ass.True(actualBoard.Archived)
+	// archiver une board déjà archivée n'est pas une erreur
+	deletion, err = wekan.DeleteBoard(ctx, board.ID, libwekan.DeleteBoardOptions{ArchiveOnly: true})
+	ass.NoError(err)
+	ass.True(deletion.Archived)
+
+	_, err = wekan.DeleteBoard(ctx, outside.ID, libwekan.DeleteBoardOptions{})
+	ass.ErrorIs(err, libwekan.ErrForbidden)
+	_, err = wekan.GetBoardFromID(ctx, outside.ID)
+	ass.NoError(err)
+	_, err = wekan.DeleteBoard(ctx, outside.ID, libwekan.DeleteBoardOptions{Force: true})
+	ass.NoError(err)
+	_, err = wekan.GetBoardFromID(ctx, outside.ID)
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+}
+
+func TestBoardDelete_DeleteBoard_unsetsTemplates(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	user := createTestUser(t, &wekan, "templates")
+	other := createTestUser(t, &wekan, "autre")
+	require.NotEmpty(t, user.Profile.TemplatesBoardId)
+
+	// WHEN
+	_, err := wekan.DeleteBoard(ctx, user.Profile.TemplatesBoardId, libwekan.DeleteBoardOptions{Force: true})
+
+	// THEN
+	require.NoError(t, err)
+	actualUser, err := wekan.GetUserFromID(ctx, user.ID)
+	require.NoError(t, err)
+	ass.Empty(actualUser.Profile.TemplatesBoardId)
+	ass.Empty(actualUser.Profile.CardTemplatesSwimlaneId)
+	ass.Empty(actualUser.Profile.ListTemplatesSwimlaneId)
+	ass.Empty(actualUser.Profile.BoardTemplatesSwimlaneId)
+	actualOther, err := wekan.GetUserFromID(ctx, other.ID)
+	require.NoError(t, err)
+	ass.Equal(other.Profile.TemplatesBoardId, actualOther.Profile.TemplatesBoardId)
+	ass.Equal(other.Profile.CardTemplatesSwimlaneId, actualOther.Profile.CardTemplatesSwimlaneId)
+}
@@ -0,0 +1,115 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardLabels_renameAndRecolor(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	label := board.GetLabelByName("urgent")
+	activitiesCount := len(boardActivityTypes(t, wekan, board.ID))
+
+	// WHEN
+	ass.NoError(wekan.RenameBoardLabel(ctx, board.ID, label.ID, "prioritaire"))
+	ass.NoError(wekan.SetBoardLabelColor(ctx, board.ID, label.ID, "crimson"))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardLabel{ID: label.ID, Name: "prioritaire", Color: "crimson"}, actualBoard.GetLabelByID(label.ID))
+	ass.Len(actualBoard.Labels, len(board.Labels))
+	// Wekan n'a pas d'activité pour le renommage ou la couleur d'une étiquette
+	ass.Len(boardActivityTypes(t, wekan, board.ID), activitiesCount)
+
+	ass.ErrorIs(wekan.RenameBoardLabel(ctx, board.ID, label.ID, "prioritaire"), libwekan.ErrNothingDone)
+	ass.ErrorIs(wekan.SetBoardLabelColor(ctx, board.ID, label.ID, "crimson"), libwekan.ErrNothingDone)
+	ass.IsType(libwekan.InvalidValueError{}, wekan.SetBoardLabelColor(ctx, board.ID, label.ID, "fuchsia"))
+	ass.IsType(libwekan.BoardLabelNotFoundError{}, wekan.RenameBoardLabel(ctx, board.ID, "inconnue", "test"))
+}
+
+func TestBoardLabels_renameToExistingName(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	other := libwekan.NewBoardLabel("autre", "blue")
+	require.NoError(t, wekan.InsertBoardLabel(ctx, board, other))
+
+	err := wekan.RenameBoardLabel(ctx, board.ID, other.ID, "urgent")
+	ass.ErrorIs(err, libwekan.ErrAlreadyExists)
+}
+
+func TestBoardLabels_DeleteBoardLabel(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	label := board.GetLabelByName("urgent")
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	list, _ := config.ListByTitle(board.ID, "A traiter")
+	swimlane, _ := config.SwimlaneByTitle(board.ID, "Suivi")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, "carte", "", wekan.ActorID())
+	card.LabelIDs = []libwekan.BoardLabelID{label.ID, board.Labels[0].ID}
+	require.NoError(t, wekan.InsertCard(ctx, card))
+	untouched := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, "autre carte", "", wekan.ActorID())
+	require.NoError(t, wekan.InsertCard(ctx, untouched))
+	rules, err := wekan.SelectRulesFromBoardID(ctx, board.ID)
+	require.NoError(t, err)
+
+	// WHEN
+	deletion, err := wekan.DeleteBoardLabel(ctx, board.ID, label.ID)
+
+	// THEN
+	require.NoError(t, err)
+	ass.Equal(label, deletion.Label)
+	ass.Equal([]libwekan.CardID{card.ID}, deletion.Cards)
+	ass.Equal([]libwekan.RuleID{rules[0].ID}, deletion.Rules)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardLabel{}, actualBoard.GetLabelByID(label.ID))
+	actualCard, err := wekan.GetCardFromID(ctx, card.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.BoardLabelID{board.Labels[0].ID}, actualCard.LabelIDs)
+	actualRules, err := wekan.SelectRulesFromBoardID(ctx, board.ID)
+	ass.NoError(err)
+	ass.Empty(actualRules)
+	activities, err := wekan.SelectActivitiesFromCardID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Equal("removedLabel", activities[len(activities)-1].ActivityType)
+	ass.NotContains(boardActivityTypes(t, wekan, board.ID), "deletedLabel")
+
+	_, err = wekan.DeleteBoardLabel(ctx, board.ID, label.ID)
+	ass.IsType(libwekan.BoardLabelNotFoundError{}, err)
+}
+
+func TestBoardLabels_DeleteBoardLabel_rollsBackWhenActivityFails(t *testing.T) {
+	ass := assert.New(t)
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	failures, activityFailures := 0, 0
+	wekan := libwekan.InitWithStorage(flakyStorage{storage, &failures, &activityFailures}, "signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	label := board.GetLabelByName("urgent")
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	list, _ := config.ListByTitle(board.ID, "A traiter")
+	swimlane, _ := config.SwimlaneByTitle(board.ID, "Suivi")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, "carte", "", wekan.ActorID())
+	card.LabelIDs = []libwekan.BoardLabelID{label.ID}
+	require.NoError(t, wekan.InsertCard(ctx, card))
+
+	// WHEN
+	activityFailures = 1
+	_, err = wekan.DeleteBoardLabel(ctx, board.ID, label.ID)
+
+	// THEN
+	ass.Error(err)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.Equal(label, actualBoard.GetLabelByID(label.ID))
+	actualCard, _ := wekan.GetCardFromID(ctx, card.ID)
+	ass.Equal([]libwekan.BoardLabelID{label.ID}, actualCard.LabelIDs)
+}
@@ -0,0 +1,195 @@
+package libwekantest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBoardMembersSync_SyncBoardMembers(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-sync")
+	admin := createTestUser(t, &wekan, "admin")
+	inactive := createTestUser(t, &wekan, "inactif")
+	leaving := createTestUser(t, &wekan, "sortant")
+	newcomer := createTestUser(t, &wekan, "nouveau")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: admin.ID, IsActive: true, IsAdmin: true}))
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: inactive.ID}))
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: leaving.ID, IsActive: true}))
+	desired := map[libwekan.UserID]libwekan.BoardRole{
+		admin.ID:    libwekan.RoleAdmin,
+		inactive.ID: libwekan.RoleWorker,
+		newcomer.ID: libwekan.RoleCommentOnly,
+	}
+
+	// WHEN
+	sync, err := wekan.SyncBoardMembers(ctx, board.ID, desired)
+
+	// THEN
+	require.NoError(t, err)
+	ass.Equal([]libwekan.UserID{newcomer.ID}, sync.Added)
+	ass.Equal([]libwekan.UserID{inactive.ID}, sync.Activated)
+	ass.Equal([]libwekan.UserID{leaving.ID}, sync.Deactivated)
+	ass.Equal([]libwekan.BoardMemberRoleChange{{UserID: inactive.ID, Old: libwekan.RoleNormal, New: libwekan.RoleWorker}}, sync.RoleChanges)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardMember{UserID: newcomer.ID, IsActive: true, IsCommentOnly: true}, actualBoard.GetMember(newcomer.ID))
+	ass.Equal(libwekan.BoardMember{UserID: inactive.ID, IsActive: true, IsWorker: true}, actualBoard.GetMember(inactive.ID))
+	ass.False(actualBoard.GetMember(leaving.ID).IsActive)
+	activityTypes := boardActivityTypes(t, wekan, board.ID)
+	ass.Contains(activityTypes, "removeBoardMember")
+	ass.Contains(activityTypes, "changedBoardMemberRole")
+
+	sync, err = wekan.SyncBoardMembers(ctx, board.ID, desired)
+	ass.NoError(err)
+	ass.True(sync.Empty())
+}
+
+func TestBoardMembersSync_safeguards(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-sync")
+	admin := createTestUser(t, &wekan, "admin")
+	member := createTestUser(t, &wekan, "membre")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: admin.ID, IsActive: true, IsAdmin: true}))
+
+	_, err := wekan.SyncBoardMembers(ctx, board.ID, map[libwekan.UserID]libwekan.BoardRole{member.ID: libwekan.RoleNormal})
+	ass.IsType(libwekan.LastBoardAdminError{}, err)
+	_, err = wekan.SyncBoardMembers(ctx, board.ID, map[libwekan.UserID]libwekan.BoardRole{"inconnu": libwekan.RoleNormal, admin.ID: libwekan.RoleAdmin})
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	_, err = wekan.SyncBoardMembers(ctx, board.ID, map[libwekan.UserID]libwekan.BoardRole{member.ID: "owner"})
+	ass.IsType(libwekan.InvalidValueError{}, err)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.UserID{admin.ID}, actualBoard.ActiveAdmins())
+
+	// l'utilisateur administrateur de libwekan reste administrateur actif
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: wekan.AdminID(), IsActive: true, IsAdmin: true}))
+	sync, err := wekan.SyncBoardMembers(ctx, board.ID, map[libwekan.UserID]libwekan.BoardRole{member.ID: libwekan.RoleNormal})
+	ass.NoError(err)
+	ass.Equal([]libwekan.UserID{wekan.AdminID()}, sync.Protected)
+	ass.Equal([]libwekan.UserID{admin.ID}, sync.Deactivated)
+	actualBoard, _ = wekan.GetBoardFromID(ctx, board.ID)
+	ass.Equal([]libwekan.UserID{wekan.AdminID()}, actualBoard.ActiveAdmins())
+}
+
+func TestBoardMembersSync_keepsOtherMemberFields(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-sync")
+	member := createTestUser(t, &wekan, "membre")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: member.ID, IsActive: true}))
+	_, err := storage.Collection("boards").UpdateOne(ctx,
+		bson.M{"_id": board.ID},
+		bson.M{"$set": bson.M{"members.$[member].isNormalAssignedOnly": true}},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: bson.A{bson.M{"member.userId": member.ID}}}))
+	require.NoError(t, err)
+	desired := map[libwekan.UserID]libwekan.BoardRole{wekan.AdminID(): libwekan.RoleAdmin, member.ID: libwekan.RoleWorker}
+
+	// WHEN
+	_, err = wekan.SyncBoardMembers(ctx, board.ID, desired)
+
+	// THEN
+	require.NoError(t, err)
+	var document struct {
+		Members []bson.M `bson:"members"`
+	}
+	require.NoError(t, storage.Collection("boards").FindOne(ctx, bson.M{"_id": board.ID}).Decode(&document))
+	ass.Contains(document.Members, bson.M{
+		"userId": string(member.ID), "isActive": true, "isAdmin": false, "isCommentOnly": false,
+		"isNoComments": false, "isWorker": true, "isNormalAssignedOnly": true,
+	})
+}
+
+// interferingStorage modifie la board par ailleurs juste après sa première lecture
+type interferingStorage struct {
+	*Storage
+	done *bool
+}
+
+type interferingCollection struct {
+	libwekan.Collection
+	storage interferingStorage
+	name    string
+}
+
+func (storage interferingStorage) Collection(name string) libwekan.Collection {
+	return interferingCollection{storage.Storage.Collection(name), storage, name}
+}
+
+func (c interferingCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) libwekan.SingleResult {
+	result := c.Collection.FindOne(ctx, filter, opts...)
+	if c.name == "boards" && !*c.storage.done {
+		*c.storage.done = true
+		_, _ = c.storage.Storage.Collection("boards").UpdateOne(ctx, filter,
+			bson.M{"$set": bson.M{"modifiedAt": time.Now().Add(time.Hour)}})
+	}
+	return result
+}
+
+func TestBoardMembersSync_detectsConcurrentModification(t *testing.T) {
+	ass := assert.New(t)
+	source, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &source, "tableau-crp-sync")
+	member := createTestUser(t, &source, "membre")
+	done := true
+	wekan := libwekan.InitWithStorage(interferingStorage{storage, &done}, "signaux.faibles", "^tableau-crp.*")
+	require.NoError(t, wekan.AssertPrivileged(ctx))
+
+	// WHEN
+	done = false
+	_, err := wekan.SyncBoardMembers(ctx, board.ID, map[libwekan.UserID]libwekan.BoardRole{
+		wekan.AdminID(): libwekan.RoleAdmin,
+		member.ID:       libwekan.RoleNormal,
+	})
+
+	// THEN
+	ass.Equal(libwekan.BoardModifiedError{BoardID: board.ID}, err)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.False(actualBoard.UserIsMember(member))
+}
+
+func TestBoardMembersSync_memberUpdatesBumpModifiedAt(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-sync")
+	member := createTestUser(t, &wekan, "membre")
+	past := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	resetModifiedAt := func() {
+		_, err := storage.Collection("boards").UpdateOne(ctx, bson.M{"_id": board.ID}, bson.M{"$set": bson.M{"modifiedAt": past}})
+		require.NoError(t, err)
+	}
+	modifiedAt := func() time.Time {
+		actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+		require.NoError(t, err)
+		return actualBoard.ModifiedAt
+	}
+
+	resetModifiedAt()
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: member.ID, IsActive: true}))
+	ass.True(modifiedAt().After(past))
+	activities := storage.Count("activities")
+
+	// un membre déjà actif n'est pas modifié
+	resetModifiedAt()
+	ass.NoError(wekan.EnableBoardMember(ctx, board.ID, member.ID))
+	ass.True(past.Equal(modifiedAt()))
+	ass.Equal(activities, storage.Count("activities"))
+
+	ass.NoError(wekan.DisableBoardMember(ctx, board.ID, member.ID))
+	ass.True(modifiedAt().After(past))
+	ass.Equal(activities+1, storage.Count("activities"))
+
+	resetModifiedAt()
+	ass.NoError(wekan.EnableBoardMember(ctx, board.ID, member.ID))
+	ass.True(modifiedAt().After(past))
+	ass.Equal(activities+2, storage.Count("activities"))
+}
@@ -0,0 +1,134 @@
+package libwekantest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBoardRoles_SetBoardMemberRole(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-roles")
+	user := createTestUser(t, &wekan, "membre")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: user.ID, IsActive: true}))
+
+	// WHEN
+	ass.NoError(wekan.SetBoardMemberRole(ctx, board.ID, user.ID, libwekan.RoleCommentOnly))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardMember{UserID: user.ID, IsActive: true, IsCommentOnly: true}, actualBoard.GetMember(user.ID))
+	ass.Contains(boardActivityTypes(t, wekan, board.ID), "changedBoardMemberRole")
+
+	ass.NoError(wekan.SetBoardMemberRole(ctx, board.ID, user.ID, libwekan.RoleAdmin))
+	actualBoard, _ = wekan.GetBoardFromID(ctx, board.ID)
+	ass.Equal(libwekan.BoardMember{UserID: user.ID, IsActive: true, IsAdmin: true}, actualBoard.GetMember(user.ID))
+
+	ass.ErrorIs(wekan.SetBoardMemberRole(ctx, board.ID, user.ID, libwekan.RoleAdmin), libwekan.ErrNothingDone)
+	ass.IsType(libwekan.InvalidValueError{}, wekan.SetBoardMemberRole(ctx, board.ID, user.ID, "owner"))
+	ass.IsType(libwekan.UserIsNotMemberError{}, wekan.SetBoardMemberRole(ctx, board.ID, "inconnu", libwekan.RoleWorker))
+}
+
+func TestBoardRoles_SetBoardMemberRole_safeguards(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-roles")
+	admin := createTestUser(t, &wekan, "admin")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: admin.ID, IsActive: true, IsAdmin: true}))
+
+	// le dernier administrateur actif ne peut pas être rétrogradé
+	err := wekan.SetBoardMemberRole(ctx, board.ID, admin.ID, libwekan.RoleNormal)
+	ass.IsType(libwekan.LastBoardAdminError{}, err)
+	ass.ErrorIs(err, libwekan.ErrForbidden)
+
+	// l'utilisateur administrateur de libwekan reste administrateur
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: wekan.AdminID(), IsActive: true, IsAdmin: true}))
+	err = wekan.SetBoardMemberRole(ctx, board.ID, wekan.AdminID(), libwekan.RoleWorker)
+	ass.ErrorIs(err, libwekan.ErrForbidden)
+	ass.ErrorIs(err, libwekan.ProtectedUserError{UserID: wekan.AdminID()})
+
+	ass.NoError(wekan.SetBoardMemberRole(ctx, board.ID, admin.ID, libwekan.RoleWorker))
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.UserID{wekan.AdminID()}, actualBoard.ActiveAdmins())
+}
+
+// demotingStorage rétrograde l'administrateur `other` juste après la première lecture de la board
+type demotingStorage struct {
+	*Storage
+	other libwekan.UserID
+	done  *bool
+}
+
+type demotingCollection struct {
+	libwekan.Collection
+	storage demotingStorage
+	name    string
+}
+
+func (storage demotingStorage) Collection(name string) libwekan.Collection {
+	return demotingCollection{storage.Storage.Collection(name), storage, name}
+}
+
+func (c demotingCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) libwekan.SingleResult {
+	result := c.Collection.FindOne(ctx, filter, opts...)
+	if c.name == "boards" && !*c.storage.done {
+		*c.storage.done = true
+		_, _ = c.storage.Storage.Collection("boards").UpdateOne(ctx, filter,
+			bson.M{"$set": bson.M{"members.$[member].isAdmin": false}},
+			&options.UpdateOptions{ArrayFilters: &options.ArrayFilters{Filters: bson.A{bson.M{"member.userId": c.storage.other}}}})
+	}
+	return result
+}
+
+func TestBoardRoles_SetBoardMemberRole_concurrentDemotion(t *testing.T) {
+	ass := assert.New(t)
+	source, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &source, "tableau-crp-roles")
+	admin := createTestUser(t, &source, "admin")
+	other := createTestUser(t, &source, "autre")
+	require.NoError(t, source.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: admin.ID, IsActive: true, IsAdmin: true}))
+	require.NoError(t, source.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: other.ID, IsActive: true, IsAdmin: true}))
+	done := true
+	wekan := libwekan.InitWithStorage(demotingStorage{storage, other.ID, &done}, "signaux.faibles", "^tableau-crp.*")
+	require.NoError(t, wekan.AssertPrivileged(ctx))
+	activities := storage.Count("activities")
+
+	// WHEN l'autre administrateur est rétrogradé entre la lecture de la board et sa mise à jour
+	done = false
+	err := wekan.SetBoardMemberRole(ctx, board.ID, admin.ID, libwekan.RoleNormal)
+
+	// THEN
+	ass.Equal(libwekan.LastBoardAdminError{BoardID: board.ID, UserID: admin.ID}, err)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.UserID{admin.ID}, actualBoard.ActiveAdmins())
+	ass.Equal(activities, storage.Count("activities"))
+}
+
+func TestBoardRoles_SetBoardMemberRole_rollsBackWhenActivityFails(t *testing.T) {
+	ass := assert.New(t)
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	failures, activityFailures := 0, 0
+	wekan := libwekan.InitWithStorage(flakyStorage{storage, &failures, &activityFailures}, "signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-roles")
+	user := createTestUser(t, &wekan, "membre")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: user.ID, IsActive: true}))
+
+	// WHEN
+	activityFailures = 1
+	err := wekan.SetBoardMemberRole(ctx, board.ID, user.ID, libwekan.RoleWorker)
+
+	// THEN
+	ass.Error(err)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.RoleNormal, actualBoard.GetMember(user.ID).Role())
+}
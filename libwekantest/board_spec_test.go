@@ -0,0 +1,221 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBoardSpec = `
+slug: tableau-crp-spec
+title: Tableau CRP spec
+swimlanes: [Suivi]
+lists: [A traiter, En cours, Terminé]
+labels:
+  - name: urgent
+    color: red
+customFields:
+  - name: SIRET
+    showOnCard: true
+rules:
+  - action: addMember
+    label: urgent
+    username: signaux.faibles
+`
+
+func TestBoardSpec_ParseBoardSpec(t *testing.T) {
+	ass := assert.New(t)
+	spec, err := libwekan.ParseBoardSpec([]byte(testBoardSpec))
+	ass.NoError(err)
+	ass.Equal(libwekan.BoardSlug("tableau-crp-spec"), spec.Slug)
+	ass.Equal([]string{"A traiter", "En cours", "Terminé"}, spec.Lists)
+	ass.Equal(libwekan.LabelSpec{Name: "urgent", Color: "red"}, spec.Labels[0])
+	ass.True(spec.CustomFields[0].ShowOnCard)
+
+	jsonSpec, err := libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec", "title": "Tableau", "lists": ["A traiter"]}`))
+	ass.NoError(err)
+	ass.Equal([]string{"A traiter"}, jsonSpec.Lists)
+
+	_, err = libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec", "title": "Tableau", "lists": ["A", "A"]}`))
+	ass.IsType(libwekan.InvalidBoardSpecError{}, err)
+	_, err = libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec"}`))
+	ass.IsType(libwekan.InvalidBoardSpecError{}, err)
+	_, err = libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec", "title": "Tableau", "labels": [{"name": "urgent"}]}`))
+	ass.IsType(libwekan.InvalidBoardSpecError{}, err)
+	_, err = libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec", "title": "Tableau", "customFields": [{"name": "Siret", "type": "texte"}]}`))
+	ass.IsType(libwekan.InvalidBoardSpecError{}, err)
+	_, err = libwekan.ParseBoardSpec([]byte(`{"slug": "tableau-crp-spec", "title": "Tableau", "customFields": [{"name": "Siret"}, {"name": "Date", "type": "date"}]}`))
+	ass.NoError(err)
+}
+
+func TestBoardSpec_PlanAndApply_createsBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	spec, err := libwekan.ParseBoardSpec([]byte(testBoardSpec))
+	require.NoError(t, err)
+
+	// WHEN
+	plan, err := wekan.Plan(ctx, spec)
+	require.NoError(t, err)
+	boardID, err := wekan.Apply(ctx, plan)
+
+	// THEN
+	ass.NoError(err)
+	ass.Empty(plan.BoardID)
+	ass.Equal(libwekan.StepCreateBoard, plan.Steps[0].Kind)
+	ass.Len(plan.Steps, 8)
+	ass.NotContains(plan.Steps, libwekan.BoardPlanStep{Kind: libwekan.StepAddBoardMember, Name: "signaux.faibles", Index: 0})
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	_, ok := config.ListByTitle(boardID, "Terminé")
+	ass.True(ok)
+	_, ok = config.SwimlaneByTitle(boardID, "Suivi")
+	ass.True(ok)
+	field, ok := config.CustomFieldByName(boardID, "SIRET")
+	ass.True(ok)
+	ass.True(field.ShowOnCard)
+	rules, err := wekan.SelectRulesFromBoardID(ctx, boardID)
+	ass.NoError(err)
+	ass.Len(rules.SelectAddMemberToTaskforceRule(), 1)
+	activities, err := wekan.SelectActivitiesFromBoardID(ctx, boardID)
+	ass.NoError(err)
+	activityTypes := make(map[string]int)
+	for _, activity := range activities {
+		activityTypes[activity.ActivityType]++
+	}
+	ass.Equal(1, activityTypes["createBoard"])
+	ass.Equal(3, activityTypes["createList"])
+	ass.Equal(1, activityTypes["createCustomField"])
+
+	replan, err := wekan.Plan(ctx, spec)
+	ass.NoError(err)
+	ass.True(replan.Empty())
+	ass.Equal(boardID, replan.BoardID)
+}
+
+func TestBoardSpec_Plan_existingBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-spec")
+	spec := libwekan.BoardSpec{
+		Slug:      board.Slug,
+		Title:     board.Title,
+		Swimlanes: []string{swimlane.Title},
+		Lists:     []string{list.Title, "Nouvelle liste"},
+	}
+
+	plan, err := wekan.Plan(ctx, spec)
+
+	ass.NoError(err)
+	ass.Equal(board.ID, plan.BoardID)
+	ass.Equal([]libwekan.BoardPlanStep{{Kind: libwekan.StepCreateList, Name: "Nouvelle liste", Index: 1}}, plan.Steps)
+}
+
+func TestBoardSpec_Plan_outsideDomain(t *testing.T) {
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	_, err := wekan.Plan(ctx, libwekan.BoardSpec{Slug: "autre-tableau", Title: "Autre"})
+	assert.ErrorIs(t, err, libwekan.ErrForbidden)
+}
+
+func TestBoardSpec_PlanAndApply_addsRuleUserAsMember(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	user := createTestUser(t, &wekan, "spec")
+	spec := libwekan.BoardSpec{
+		Slug:   "tableau-crp-spec",
+		Title:  "Tableau",
+		Labels: []libwekan.LabelSpec{{Name: "urgent", Color: "red"}},
+		Rules:  []libwekan.RuleSpec{{Action: "removeMember", Label: "urgent", Username: user.Username}},
+	}
+
+	// WHEN
+	plan, err := wekan.Plan(ctx, spec)
+	require.NoError(t, err)
+	boardID, err := wekan.Apply(ctx, plan)
+
+	// THEN
+	ass.NoError(err)
+	ass.Contains(plan.Steps, libwekan.BoardPlanStep{Kind: libwekan.StepAddBoardMember, Name: string(user.Username), Index: 0})
+	board, err := wekan.GetBoardFromID(ctx, boardID)
+	require.NoError(t, err)
+	ass.True(board.UserIsActiveMember(user))
+	replan, err := wekan.Plan(ctx, spec)
+	ass.NoError(err)
+	ass.True(replan.Empty())
+}
+
+func TestBoardSpec_Plan_reportsTitleAndLabelColorChanges(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-spec")
+	require.NoError(t, wekan.InsertBoardLabel(ctx, board, libwekan.NewBoardLabel("urgent", "red")))
+	spec := libwekan.BoardSpec{
+		Slug:   board.Slug,
+		Title:  "Nouveau titre",
+		Labels: []libwekan.LabelSpec{{Name: "urgent", Color: "blue"}},
+	}
+
+	// WHEN
+	plan, err := wekan.Plan(ctx, spec)
+	require.NoError(t, err)
+	_, err = wekan.Apply(ctx, plan)
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal([]libwekan.BoardPlanStep{
+		{Kind: libwekan.StepRenameBoard, Name: "Nouveau titre", Index: 0},
+		{Kind: libwekan.StepSetLabelColor, Name: "urgent", Index: 0},
+	}, plan.Steps)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(libwekan.BoardTitle("Nouveau titre"), actualBoard.Title)
+	ass.Equal("blue", actualBoard.GetLabelByName("urgent").Color)
+}
+
+func TestBoardSpec_Apply_outsideDomain(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	boardsCount := storage.Count("boards")
+	plan := libwekan.BoardPlan{
+		Spec:  libwekan.BoardSpec{Slug: "autre-tableau", Title: "Autre"},
+		Steps: []libwekan.BoardPlanStep{{Kind: libwekan.StepCreateBoard, Name: "autre-tableau"}},
+	}
+
+	_, err := wekan.Apply(ctx, plan)
+
+	ass.ErrorIs(err, libwekan.ErrForbidden)
+	ass.Equal(boardsCount, storage.Count("boards"))
+}
+
+func TestBoardSpec_Apply_withStepOutsideSpec(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	boardsCount := storage.Count("boards")
+	spec := libwekan.BoardSpec{Slug: "tableau-crp-modifie", Title: "Modifié", Lists: []string{"A traiter"}}
+	for _, step := range []libwekan.BoardPlanStep{
+		{Kind: libwekan.StepCreateList, Name: "Fantôme", Index: 1},
+		{Kind: libwekan.StepCreateSwimlane, Name: "Fantôme", Index: 0},
+		{Kind: libwekan.StepCreateLabel, Name: "Fantôme", Index: -1},
+		{Kind: libwekan.StepCreateRule, Name: "Fantôme", Index: 0},
+	} {
+		plan := libwekan.BoardPlan{
+			Spec:  spec,
+			Steps: []libwekan.BoardPlanStep{{Kind: libwekan.StepCreateBoard, Name: string(spec.Slug)}, step},
+		}
+
+		_, err := wekan.Apply(ctx, plan)
+
+		ass.IsType(libwekan.InvalidBoardSpecError{}, err, step.Kind)
+		ass.Equal(boardsCount, storage.Count("boards"), step.Kind)
+	}
+
+	invalid := libwekan.BoardPlan{
+		Spec:  libwekan.BoardSpec{Slug: "tableau-crp-modifie", Title: "Modifié", Labels: []libwekan.LabelSpec{{Name: "urgent"}}},
+		Steps: []libwekan.BoardPlanStep{{Kind: libwekan.StepCreateBoard}, {Kind: libwekan.StepCreateLabel, Name: "urgent"}},
+	}
+	_, err := wekan.Apply(ctx, invalid)
+	ass.IsType(libwekan.InvalidBoardSpecError{}, err)
+	ass.Equal(boardsCount, storage.Count("boards"))
+}
@@ -0,0 +1,92 @@
+package libwekantest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardStats_BoardStatsAndDomainStats(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board := createSpecBoard(t, &wekan)
+	outside, outsideSwimlane, outsideList := createTestBoard(t, &wekan, "hors-domaine")
+	config, err := wekan.SelectConfig(ctx)
+	require.NoError(t, err)
+	todo, _ := config.ListByTitle(board.ID, "A traiter")
+	doing, _ := config.ListByTitle(board.ID, "En cours")
+	swimlane, _ := config.SwimlaneByTitle(board.ID, "Suivi")
+	label, _ := config.LabelByName(board.ID, "urgent")
+	user := createTestUser(t, &wekan, "membre")
+
+	labelled := libwekan.BuildCard(board.ID, todo.ID, swimlane.ID, "étiquetée", "", wekan.ActorID())
+	labelled.LabelIDs = []libwekan.BoardLabelID{label.ID}
+	labelled.Members = []libwekan.UserID{user.ID}
+	labelled.Assignees = []libwekan.UserID{user.ID, wekan.ActorID()}
+	inactive := libwekan.BuildCard(board.ID, doing.ID, swimlane.ID, "inactive", "", wekan.ActorID())
+	inactive.DateLastActivity = time.Now().Add(-2 * libwekan.DefaultInactivityPeriod)
+	archived := libwekan.BuildCard(board.ID, doing.ID, swimlane.ID, "archivée", "", wekan.ActorID())
+	archived.Archived = true
+	archived.DateLastActivity = time.Now().Add(-2 * libwekan.DefaultInactivityPeriod)
+	outsideCard := libwekan.BuildCard(outside.ID, outsideList.ID, outsideSwimlane.ID, "hors domaine", "", wekan.ActorID())
+	for _, card := range []libwekan.Card{labelled, inactive, archived, outsideCard} {
+		require.NoError(t, wekan.InsertCard(ctx, card))
+	}
+
+	// WHEN
+	stats, err := wekan.BoardStats(ctx, board.ID, libwekan.StatsOptions{})
+
+	// THEN
+	require.NoError(t, err)
+	ass.Equal(libwekan.CardCounts{Cards: 3, Active: 2, Archived: 1, Inactive: 1}, stats.CardCounts)
+	ass.Equal(map[libwekan.ListID]int{todo.ID: 1, doing.ID: 1}, stats.ByList)
+	ass.Equal(map[libwekan.SwimlaneID]int{swimlane.ID: 2}, stats.BySwimlane)
+	ass.Equal(map[libwekan.BoardLabelID]int{label.ID: 1}, stats.ByLabel)
+	ass.Equal(map[libwekan.UserID]int{user.ID: 1}, stats.ByMember)
+	ass.Equal(map[libwekan.UserID]int{user.ID: 1, wekan.ActorID(): 1}, stats.ByAssignee)
+
+	domainStats, err := wekan.DomainStats(ctx, libwekan.StatsOptions{})
+	require.NoError(t, err)
+	ass.Equal(stats.CardCounts, domainStats.CardCounts)
+	ass.Equal(map[libwekan.BoardID]libwekan.CardCounts{board.ID: stats.CardCounts}, domainStats.Boards)
+}
+
+func TestBoardStats_BoardStats_emptyBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-vide")
+
+	stats, err := wekan.BoardStats(ctx, board.ID, libwekan.StatsOptions{})
+	ass.NoError(err)
+	ass.Equal(libwekan.CardCounts{}, stats.CardCounts)
+	ass.Empty(stats.ByList)
+
+	_, err = wekan.BoardStats(ctx, "inconnue", libwekan.StatsOptions{})
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+}
+
+func TestBoardStats_DomainStats_withInactivityPeriodAndUppercaseSlug(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "Tableau-CRP-majuscules")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, "carte", "", wekan.ActorID())
+	card.DateLastActivity = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, wekan.InsertCard(ctx, card))
+
+	// WHEN
+	defaultStats, err := wekan.DomainStats(ctx, libwekan.StatsOptions{})
+	require.NoError(t, err)
+	shortStats, err := wekan.DomainStats(ctx, libwekan.StatsOptions{InactivityPeriod: time.Hour})
+	require.NoError(t, err)
+	boardStats, err := wekan.BoardStats(ctx, board.ID, libwekan.StatsOptions{InactivityPeriod: time.Hour})
+	require.NoError(t, err)
+
+	// THEN
+	ass.Equal(map[libwekan.BoardID]libwekan.CardCounts{board.ID: {Cards: 1, Active: 1}}, defaultStats.Boards)
+	ass.Equal(libwekan.CardCounts{Cards: 1, Active: 1, Inactive: 1}, shortStats.CardCounts)
+	ass.Equal(shortStats.CardCounts, boardStats.CardCounts)
+	ass.WithinDuration(time.Now().Add(-time.Hour), shortStats.InactiveSince, time.Minute)
+}
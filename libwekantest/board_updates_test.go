@@ -0,0 +1,96 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boardActivityTypes(t *testing.T, wekan libwekan.Wekan, boardID libwekan.BoardID) []string {
+	activities, err := wekan.SelectActivitiesFromBoardID(ctx, boardID)
+	require.NoError(t, err)
+	activityTypes := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		activityTypes = append(activityTypes, activity.ActivityType)
+	}
+	return activityTypes
+}
+
+func TestBoardUpdates_updatesFieldsWithActivities(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-updates")
+	activitiesCount := len(boardActivityTypes(t, wekan, board.ID))
+
+	// WHEN
+	ass.NoError(wekan.RenameBoard(ctx, board.ID, "Nouveau titre"))
+	ass.NoError(wekan.ArchiveBoard(ctx, board.ID))
+	ass.NoError(wekan.SetBoardColor(ctx, board.ID, "pomegranate"))
+	ass.NoError(wekan.SetBoardPermission(ctx, board.ID, libwekan.BoardPublic))
+	ass.NoError(wekan.SetBoardFeature(ctx, board.ID, libwekan.FeatureComments, false))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	ass.NoError(err)
+	ass.Equal(libwekan.BoardTitle("Nouveau titre"), actualBoard.Title)
+	ass.Equal(board.Slug, actualBoard.Slug)
+	ass.True(actualBoard.Archived)
+	ass.Equal("pomegranate", actualBoard.Color)
+	ass.Equal(libwekan.BoardPublic, actualBoard.Permission)
+	ass.False(actualBoard.AllowsComments)
+	ass.True(actualBoard.AllowsChecklists)
+	ass.True(actualBoard.ModifiedAt.After(board.ModifiedAt) || actualBoard.ModifiedAt.Equal(board.ModifiedAt))
+	// seul l'archivage a une activité dans Wekan
+	activityTypes := boardActivityTypes(t, wekan, board.ID)
+	ass.Len(activityTypes, activitiesCount+1)
+	ass.Contains(activityTypes, "archivedBoard")
+
+	ass.NoError(wekan.UnarchiveBoard(ctx, board.ID))
+	actualBoard, _ = wekan.GetBoardFromID(ctx, board.ID)
+	ass.False(actualBoard.Archived)
+	ass.Contains(boardActivityTypes(t, wekan, board.ID), "restoredBoard")
+}
+
+func TestBoardUpdates_nothingDone(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-updates")
+	activitiesCount := storage.Count("activities")
+
+	ass.ErrorIs(wekan.UnarchiveBoard(ctx, board.ID), libwekan.ErrNothingDone)
+	ass.ErrorIs(wekan.SetBoardColor(ctx, board.ID, board.Color), libwekan.ErrNothingDone)
+	ass.ErrorIs(wekan.SetBoardFeature(ctx, board.ID, libwekan.FeatureComments, true), libwekan.ErrNothingDone)
+	ass.Equal(activitiesCount, storage.Count("activities"))
+}
+
+func TestBoardUpdates_invalidValues(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-updates")
+
+	ass.Equal(libwekan.InvalidValueError{Field: "title", Value: " "}, wekan.RenameBoard(ctx, board.ID, " "))
+	ass.Equal(libwekan.InvalidValueError{Field: "color", Value: "fuchsia"}, wekan.SetBoardColor(ctx, board.ID, "fuchsia"))
+	ass.Equal(libwekan.InvalidValueError{Field: "permission", Value: "team"}, wekan.SetBoardPermission(ctx, board.ID, "team"))
+	ass.Equal(libwekan.InvalidValueError{Field: "feature", Value: "allowsEverything"}, wekan.SetBoardFeature(ctx, board.ID, "allowsEverything", true))
+	ass.ErrorIs(wekan.ArchiveBoard(ctx, "unknown"), libwekan.ErrNotFound)
+}
+
+func TestBoardUpdates_ArchiveBoard_rollsBackWhenActivityFails(t *testing.T) {
+	ass := assert.New(t)
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	failures, activityFailures := 0, 0
+	wekan := libwekan.InitWithStorage(flakyStorage{storage, &failures, &activityFailures}, "signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-updates")
+
+	// WHEN
+	activityFailures = 1
+	err := wekan.ArchiveBoard(ctx, board.ID)
+
+	// THEN
+	ass.Error(err)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.False(actualBoard.Archived)
+	ass.NotContains(boardActivityTypes(t, wekan, board.ID), "archivedBoard")
+}
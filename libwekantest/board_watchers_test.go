@@ -0,0 +1,139 @@
+package libwekantest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBoardWatchers_WatchAndUnwatchBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-watchers")
+	user := createTestUser(t, &wekan, "membre")
+	require.NoError(t, wekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: user.ID, IsActive: true}))
+
+	// WHEN
+	ass.NoError(wekan.WatchBoard(ctx, board.ID, user.ID, libwekan.WatchLevelWatching))
+	ass.NoError(wekan.WatchBoard(ctx, board.ID, user.ID, libwekan.WatchLevelMuted))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.BoardWatcher{{UserID: user.ID, Level: libwekan.WatchLevelMuted}}, actualBoard.Watchers)
+	ass.ErrorIs(wekan.WatchBoard(ctx, board.ID, user.ID, libwekan.WatchLevelMuted), libwekan.ErrNothingDone)
+	ass.IsType(libwekan.InvalidValueError{}, wekan.WatchBoard(ctx, board.ID, user.ID, "loud"))
+	ass.IsType(libwekan.UserIsNotMemberError{}, wekan.WatchBoard(ctx, board.ID, "inconnu", libwekan.WatchLevelWatching))
+
+	ass.NoError(wekan.UnwatchBoard(ctx, board.ID, user.ID))
+	actualBoard, _ = wekan.GetBoardFromID(ctx, board.ID)
+	ass.Equal(libwekan.BoardWatcher{}, actualBoard.GetWatcher(user.ID))
+	ass.ErrorIs(wekan.UnwatchBoard(ctx, board.ID, user.ID), libwekan.ErrNothingDone)
+}
+
+// watchingStorage abonne l'utilisateur à la board par ailleurs juste après sa première lecture
+type watchingStorage struct {
+	*Storage
+	watcher libwekan.BoardWatcher
+	done    *bool
+}
+
+type watchingCollection struct {
+	libwekan.Collection
+	storage watchingStorage
+	name    string
+}
+
+func (storage watchingStorage) Collection(name string) libwekan.Collection {
+	return watchingCollection{storage.Storage.Collection(name), storage, name}
+}
+
+func (c watchingCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) libwekan.SingleResult {
+	result := c.Collection.FindOne(ctx, filter, opts...)
+	if c.name == "boards" && !*c.storage.done {
+		*c.storage.done = true
+		_, _ = c.storage.Storage.Collection("boards").UpdateOne(ctx, filter,
+			bson.M{"$push": bson.M{"watchers": c.storage.watcher}})
+	}
+	return result
+}
+
+func TestBoardWatchers_WatchBoard_withConcurrentWatch(t *testing.T) {
+	ass := assert.New(t)
+	source, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &source, "tableau-crp-watchers")
+	user := createTestUser(t, &source, "membre")
+	require.NoError(t, source.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: user.ID, IsActive: true}))
+	done := true
+	watcher := libwekan.BoardWatcher{UserID: user.ID, Level: libwekan.WatchLevelTracking}
+	wekan := libwekan.InitWithStorage(watchingStorage{storage, watcher, &done}, "signaux.faibles", "^tableau-crp.*")
+	require.NoError(t, wekan.AssertPrivileged(ctx))
+
+	// WHEN
+	done = false
+	err := wekan.WatchBoard(ctx, board.ID, user.ID, libwekan.WatchLevelWatching)
+
+	// THEN
+	ass.ErrorIs(err, libwekan.ErrNothingDone)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.BoardWatcher{watcher}, actualBoard.Watchers)
+}
+
+func TestBoardWatchers_StarAndUnstarBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-stars")
+	user := createTestUser(t, &wekan, "fan")
+	other := createTestUser(t, &wekan, "autre")
+
+	// WHEN
+	ass.NoError(wekan.StarBoard(ctx, board.ID, user.ID))
+	ass.NoError(wekan.StarBoard(ctx, board.ID, other.ID))
+	ass.ErrorIs(wekan.StarBoard(ctx, board.ID, user.ID), libwekan.ErrNothingDone)
+	ass.NoError(wekan.UnstarBoard(ctx, board.ID, other.ID))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.Equal(1, actualBoard.Stars)
+	actualUser, err := wekan.GetUserFromID(ctx, user.ID)
+	require.NoError(t, err)
+	ass.Equal([]libwekan.BoardID{board.ID}, actualUser.Profile.StarredBoards)
+	actualOther, _ := wekan.GetUserFromID(ctx, other.ID)
+	ass.Empty(actualOther.Profile.StarredBoards)
+	ass.ErrorIs(wekan.UnstarBoard(ctx, board.ID, other.ID), libwekan.ErrNothingDone)
+	ass.ErrorIs(wekan.StarBoard(ctx, board.ID, "inconnu"), libwekan.ErrNotFound)
+}
+
+func TestBoardWatchers_InviteToBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-invitations")
+	user := createTestUser(t, &wekan, "invité")
+
+	// WHEN
+	ass.NoError(wekan.InviteToBoard(ctx, board.ID, user.ID))
+
+	// THEN
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	require.NoError(t, err)
+	ass.True(actualBoard.UserIsActiveMember(user))
+	actualUser, err := wekan.GetUserFromID(ctx, user.ID)
+	require.NoError(t, err)
+	ass.Contains(actualUser.Profile.InvitedBoards, board.ID)
+	ass.ErrorIs(wekan.InviteToBoard(ctx, board.ID, user.ID), libwekan.ErrNothingDone)
+
+	// la suppression de la board la retire des profils
+	require.NoError(t, wekan.StarBoard(ctx, board.ID, user.ID))
+	_, err = wekan.DeleteBoard(ctx, board.ID, libwekan.DeleteBoardOptions{})
+	require.NoError(t, err)
+	actualUser, _ = wekan.GetUserFromID(ctx, user.ID)
+	ass.NotContains(actualUser.Profile.InvitedBoards, board.ID)
+	ass.NotContains(actualUser.Profile.StarredBoards, board.ID)
+}
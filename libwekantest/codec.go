@@ -0,0 +1,329 @@
+package libwekantest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// les documents sont stockés sous forme de bson.M après un aller-retour bson
+// afin que les noms de champs et les types correspondent à ce que stockerait mongodb
+func normalize(value interface{}) (interface{}, error) {
+	raw, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		return nil, err
+	}
+	var wrapper bson.M
+	if err := bson.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper["v"], nil
+}
+
+func normalizeDocument(value interface{}) (bson.M, error) {
+	if value == nil {
+		return bson.M{}, nil
+	}
+	normalized, err := normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	document, ok := normalized.(bson.M)
+	if !ok {
+		return nil, fmt.Errorf("un document est attendu, %T reçu", value)
+	}
+	return document, nil
+}
+
+func normalizePipeline(pipeline interface{}) ([]bson.M, error) {
+	normalized, err := normalize(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := normalized.(bson.A)
+	if !ok {
+		return nil, fmt.Errorf("un pipeline est attendu, %T reçu", pipeline)
+	}
+	stages := make([]bson.M, len(array))
+	for i, element := range array {
+		stage, ok := element.(bson.M)
+		if !ok || len(stage) != 1 {
+			return nil, fmt.Errorf("étape de pipeline invalide : %v", element)
+		}
+		stages[i] = stage
+	}
+	return stages, nil
+}
+
+func normalizeArrayFilters(opts []*options.UpdateOptions) ([]bson.M, error) {
+	var arrayFilters []bson.M
+	for _, opt := range opts {
+		if opt == nil || opt.ArrayFilters == nil {
+			continue
+		}
+		for _, filter := range opt.ArrayFilters.Filters {
+			normalized, err := normalizeDocument(filter)
+			if err != nil {
+				return nil, err
+			}
+			arrayFilters = append(arrayFilters, normalized)
+		}
+	}
+	return arrayFilters, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.M:
+		document := make(bson.M, len(v))
+		for key, element := range v {
+			document[key] = deepCopy(element)
+		}
+		return document
+	case bson.A:
+		array := make(bson.A, len(v))
+		for i, element := range v {
+			array[i] = deepCopy(element)
+		}
+		return array
+	default:
+		return v
+	}
+}
+
+func decode(document bson.M, value interface{}) error {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, value)
+}
+
+type singleResult struct {
+	document bson.M
+	err      error
+}
+
+func (result singleResult) Decode(value interface{}) error {
+	if result.err != nil {
+		return result.err
+	}
+	return decode(result.document, value)
+}
+
+type cursor struct {
+	documents []bson.M
+	position  int
+}
+
+func (cur *cursor) Next(context.Context) bool {
+	if cur.position+1 >= len(cur.documents) {
+		return false
+	}
+	cur.position++
+	return true
+}
+
+func (cur *cursor) Decode(value interface{}) error {
+	if cur.position < 0 || cur.position >= len(cur.documents) {
+		return mongo.ErrNoDocuments
+	}
+	return decode(cur.documents[cur.position], value)
+}
+
+func (cur *cursor) All(_ context.Context, results interface{}) error {
+	resultsValue := reflect.ValueOf(results)
+	if resultsValue.Kind() != reflect.Ptr || resultsValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results doit être un pointeur vers une slice, %T reçu", results)
+	}
+	sliceValue := resultsValue.Elem()
+	elementType := sliceValue.Type().Elem()
+	sliceValue = sliceValue.Slice(0, 0)
+	for _, document := range cur.documents[cur.position+1:] {
+		element := reflect.New(elementType)
+		if err := decode(document, element.Interface()); err != nil {
+			return err
+		}
+		sliceValue = reflect.Append(sliceValue, element.Elem())
+	}
+	resultsValue.Elem().Set(sliceValue)
+	cur.position = len(cur.documents)
+	return nil
+}
+
+func (cur *cursor) Close(context.Context) error {
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func equalValues(a interface{}, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// typeOrder reproduit l'ordre de comparaison des types bson utilisé par mongodb
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Undefined, primitive.Null:
+		return 1
+	case int32, int64, int, float64:
+		return 2
+	case string, primitive.Symbol:
+		return 3
+	case bson.M:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	case primitive.Regex:
+		return 11
+	}
+	return 12
+}
+
+// compareValues retourne -1, 0 ou 1 suivant l'ordre de tri mongodb
+func compareValues(a interface{}, b interface{}) int {
+	orderA, orderB := typeOrder(a), typeOrder(b)
+	if orderA != orderB {
+		return compareInts(orderA, orderB)
+	}
+	switch va := a.(type) {
+	case string:
+		vb := b.(string)
+		if va < vb {
+			return -1
+		}
+		if va > vb {
+			return 1
+		}
+		return 0
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		}
+		if !va {
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		return compareInts(int(va), int(b.(primitive.DateTime)))
+	case primitive.ObjectID:
+		return compareInts(int(va.Timestamp().UnixNano()), int(b.(primitive.ObjectID).Timestamp().UnixNano()))
+	case bson.A:
+		vb := b.(bson.A)
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			if c := compareValues(va[i], vb[i]); c != 0 {
+				return c
+			}
+		}
+		return compareInts(len(va), len(vb))
+	}
+	if fa, ok := toFloat(a); ok {
+		fb, _ := toFloat(b)
+		if fa < fb {
+			return -1
+		}
+		if fa > fb {
+			return 1
+		}
+		return 0
+	}
+	return 0
+}
+
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type sortKey struct {
+	path      string
+	direction int
+}
+
+func sortKeys(specification interface{}) ([]sortKey, error) {
+	var keys []sortKey
+	switch spec := specification.(type) {
+	case bson.D:
+		for _, element := range spec {
+			direction, _ := toFloat(element.Value)
+			keys = append(keys, sortKey{element.Key, int(direction)})
+		}
+	case bson.M:
+		// l'ordre des clés d'une map n'est pas défini, on trie par nom pour rester déterministe
+		for key, value := range spec {
+			direction, _ := toFloat(value)
+			keys = append(keys, sortKey{key, int(direction)})
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].path < keys[j].path })
+	default:
+		normalized, err := normalizeDocument(specification)
+		if err != nil {
+			return nil, err
+		}
+		return sortKeys(normalized)
+	}
+	return keys, nil
+}
+
+func sortDocuments(documents []bson.M, specification interface{}) ([]bson.M, error) {
+	keys, err := sortKeys(specification)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(documents, func(i, j int) bool {
+		for _, key := range keys {
+			a, _ := getPath(documents[i], key.path)
+			b, _ := getPath(documents[j], key.path)
+			if c := compareValues(a, b); c != 0 {
+				return c*key.direction < 0
+			}
+		}
+		return false
+	})
+	return documents, nil
+}
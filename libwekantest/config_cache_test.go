@@ -0,0 +1,216 @@
+package libwekantest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// standaloneStorage simule un serveur mongodb sans change streams
+type standaloneStorage struct {
+	*Storage
+}
+
+func (standaloneStorage) Watch(context.Context, []string) (libwekan.ChangeStream, error) {
+	return nil, errors.New("The $changeStream stage is only supported on replica sets")
+}
+
+func runConfigCache(t *testing.T, cache *libwekan.ConfigCache) {
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- cache.Run(runCtx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+}
+
+func TestConfigCache_Run_followsChangeStream(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-cache")
+
+	cache, err := wekan.NewConfigCache(ctx, time.Hour)
+	require.NoError(t, err)
+	before := cache.Snapshot()
+	ass.Contains(before.Boards, board.ID)
+	runConfigCache(t, cache)
+
+	otherBoard, _, _ := createTestBoard(t, &wekan, "tableau-crp-cache-bis")
+	ass.Eventually(func() bool {
+		_, ok := cache.Snapshot().Boards[otherBoard.ID]
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	ass.NotContains(before.Boards, otherBoard.ID)
+	ass.NoError(cache.LastError())
+}
+
+func TestConfigCache_Run_pollsWithoutChangeStream(t *testing.T) {
+	ass := assert.New(t)
+	storage := NewStorage()
+	admin := libwekan.BuildUser("signaux.faibles", "", "signaux.faibles").Admin(true)
+	require.NoError(t, storage.Insert("users", admin))
+	wekan := libwekan.InitWithStorage(standaloneStorage{storage}, "signaux.faibles", "^tableau-crp.*")
+	createTestBoard(t, &wekan, "tableau-crp-cache")
+
+	cache, err := wekan.NewConfigCache(ctx, 5*time.Millisecond)
+	require.NoError(t, err)
+	version := cache.Version()
+	runConfigCache(t, cache)
+
+	user := createTestUser(t, &wekan, "cache")
+	ass.Eventually(func() bool {
+		_, ok := cache.Snapshot().Users[user.ID]
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	ass.Greater(cache.Version(), version)
+}
+
+func TestConfigCache_Refresh_keepsSnapshotOnError(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-cache")
+	cache, err := wekan.NewConfigCache(ctx, 0)
+	require.NoError(t, err)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	ass.Error(cache.Refresh(cancelled))
+	ass.Error(cache.LastError())
+	ass.Contains(cache.Snapshot().Boards, board.ID)
+	ass.Equal(uint64(1), cache.Version())
+}
+
+// interruptedStorage fournit un premier change stream déjà interrompu, les suivants fonctionnent normalement
+type interruptedStorage struct {
+	*Storage
+	watches *atomic.Int32
+}
+
+type interruptedStream struct{}
+
+func (interruptedStream) Next(context.Context) bool   { return false }
+func (interruptedStream) Err() error                  { return errors.New("connection reset") }
+func (interruptedStream) Close(context.Context) error { return nil }
+
+func (storage interruptedStorage) Watch(ctx context.Context, collections []string) (libwekan.ChangeStream, error) {
+	if storage.watches.Add(1) == 1 {
+		return interruptedStream{}, nil
+	}
+	return storage.Storage.Watch(ctx, collections)
+}
+
+func TestConfigCache_Run_reopensInterruptedChangeStream(t *testing.T) {
+	ass := assert.New(t)
+	storage := NewStorage()
+	admin := libwekan.BuildUser("signaux.faibles", "", "signaux.faibles").Admin(true)
+	require.NoError(t, storage.Insert("users", admin))
+	watches := &atomic.Int32{}
+	wekan := libwekan.InitWithStorage(interruptedStorage{storage, watches}, "signaux.faibles", "^tableau-crp.*")
+
+	// la scrutation, avec un intervalle d'une heure, ne peut pas expliquer la prise en compte des modifications
+	cache, err := wekan.NewConfigCache(ctx, time.Hour)
+	require.NoError(t, err)
+	runConfigCache(t, cache)
+
+	ass.Eventually(func() bool { return watches.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-reopen")
+	ass.Eventually(func() bool {
+		_, ok := cache.Snapshot().Boards[board.ID]
+		return ok
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestConfigCache_Run_coalescesChanges(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	cache, err := wekan.NewConfigCache(ctx, time.Hour)
+	require.NoError(t, err)
+	runConfigCache(t, cache)
+	// rafraîchissement qui suit l'ouverture du flux
+	ass.Eventually(func() bool { return cache.Version() == 2 }, time.Second, 5*time.Millisecond)
+
+	var last libwekan.Board
+	for i := 0; i < 10; i++ {
+		last, _, _ = createTestBoard(t, &wekan, fmt.Sprintf("tableau-crp-rafale-%d", i))
+	}
+
+	ass.Eventually(func() bool {
+		_, ok := cache.Snapshot().Boards[last.ID]
+		return ok
+	}, 2*time.Second, 5*time.Millisecond)
+	ass.LessOrEqual(cache.Version(), uint64(4))
+}
+
+// slowStorage retient la prochaine agrégation sur les boards jusqu'à la fermeture de release,
+// son résultat est lu avant l'attente et reflète donc l'état de la base au moment de l'appel
+type slowStorage struct {
+	*Storage
+	slow    *atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+}
+
+type slowCollection struct {
+	libwekan.Collection
+	storage slowStorage
+}
+
+func (storage slowStorage) Collection(name string) libwekan.Collection {
+	if name != "boards" {
+		return storage.Storage.Collection(name)
+	}
+	return slowCollection{storage.Storage.Collection(name), storage}
+}
+
+func (c slowCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (libwekan.Cursor, error) {
+	cursor, err := c.Collection.Aggregate(ctx, pipeline, opts...)
+	if c.storage.slow.CompareAndSwap(true, false) {
+		close(c.storage.entered)
+		<-c.storage.release
+	}
+	return cursor, err
+}
+
+func TestConfigCache_Refresh_doesNotBlockReaders(t *testing.T) {
+	ass := assert.New(t)
+	storage := NewStorage()
+	admin := libwekan.BuildUser("signaux.faibles", "", "signaux.faibles").Admin(true)
+	require.NoError(t, storage.Insert("users", admin))
+	slow := slowStorage{storage, &atomic.Bool{}, make(chan struct{}), make(chan struct{})}
+	wekan := libwekan.InitWithStorage(slow, "signaux.faibles", "^tableau-crp.*")
+	cache, err := wekan.NewConfigCache(ctx, time.Hour)
+	require.NoError(t, err)
+
+	// WHEN
+	slow.slow.Store(true)
+	done := make(chan error)
+	go func() { done <- cache.Refresh(ctx) }()
+	<-slow.entered
+
+	// THEN
+	read := make(chan error)
+	go func() { read <- cache.LastError() }()
+	select {
+	case err := <-read:
+		ass.NoError(err)
+	case <-time.After(time.Second):
+		ass.Fail("LastError attend la fin du rafraîchissement")
+	}
+
+	// un rafraîchissement lancé plus tard et terminé plus tôt n'est pas écrasé par le précédent
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-lent")
+	require.NoError(t, cache.Refresh(ctx))
+	close(slow.release)
+	ass.NoError(<-done)
+	ass.Contains(cache.Snapshot().Boards, board.ID)
+	ass.Equal(uint64(2), cache.Version())
+}
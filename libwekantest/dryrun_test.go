@@ -0,0 +1,73 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun_AddMemberToBoard_isNotWritten(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	activitiesCount := storage.Count("activities")
+
+	// WHEN
+	dryRunWekan, journal := wekan.DryRun()
+	err := dryRunWekan.AddMemberToBoard(ctx, board.ID, libwekan.BoardMember{UserID: user.ID, IsActive: true})
+
+	// THEN
+	ass.NoError(err)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.False(actualBoard.UserIsMember(user))
+	ass.Equal(activitiesCount, storage.Count("activities"))
+
+	mutations := journal.Mutations()
+	ass.Len(mutations, 2)
+	ass.Equal("updateOne", mutations[0].Operation)
+	ass.Equal("boards", mutations[0].Collection)
+	ass.Equal("insertOne", mutations[1].Operation)
+	ass.Equal("activities", mutations[1].Collection)
+	activities := journal.Activities()
+	ass.Len(activities, 1)
+	ass.Equal("addBoardMember", activities[0].ActivityType)
+	ass.Equal(user.ID, activities[0].MemberID)
+}
+
+func TestDryRun_AddLabelToCard_withUnknownCard(t *testing.T) {
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	dryRunWekan, journal := wekan.DryRun()
+
+	err := dryRunWekan.AddLabelToCard(ctx, "unknown", "label")
+
+	assert.IsType(t, libwekan.CardNotFoundError{}, err)
+	assert.Empty(t, journal.Mutations())
+}
+
+func TestDryRun_DeleteBoard_plansDeleteMany(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-test")
+	listsCount := storage.Count("lists")
+
+	// WHEN
+	dryRunWekan, journal := wekan.DryRun()
+	deletion, err := dryRunWekan.DeleteBoard(ctx, board.ID, libwekan.DeleteBoardOptions{})
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal(1, deletion.Deleted["boards"])
+	ass.Equal(1, deletion.Deleted["lists"])
+	ass.Equal(listsCount, storage.Count("lists"))
+	_, err = wekan.GetBoardFromID(ctx, board.ID)
+	ass.NoError(err)
+	var operations []string
+	for _, mutation := range journal.Mutations() {
+		if mutation.Collection == "lists" {
+			operations = append(operations, mutation.Operation)
+		}
+	}
+	ass.Equal([]string{"deleteMany"}, operations)
+}
@@ -0,0 +1,45 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_getters_returnTypedNotFoundErrors(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+
+	_, err := wekan.GetListFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	ass.Equal(libwekan.ListNotFoundError{ListID: "unknown"}, err)
+
+	_, err = wekan.GetSwimlaneFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	ass.Equal(libwekan.SwimlaneNotFoundError{SwimlaneID: "unknown"}, err)
+
+	_, err = wekan.GetBoardFromSlug(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	var boardError libwekan.BoardNotFoundError
+	ass.ErrorAs(err, &boardError)
+	ass.Equal("slug", boardError.Field)
+
+	_, err = wekan.GetCardFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	_, err = wekan.GetUserFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	_, err = wekan.SelectRuleFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+	_, err = wekan.GetActivityFromID(ctx, "unknown")
+	ass.ErrorIs(err, libwekan.ErrNotFound)
+}
+
+func TestErrors_DisableBoardMember_withAdmin(t *testing.T) {
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-errors")
+
+	err := wekan.DisableBoardMember(ctx, board.ID, wekan.AdminID())
+
+	assert.ErrorIs(t, err, libwekan.ErrForbidden)
+}
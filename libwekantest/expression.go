@@ -0,0 +1,251 @@
+package libwekantest
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// evaluate calcule une expression d'agrégation (`$champ`, `$$variable`, opérateurs) dans le contexte du document
+func evaluate(document bson.M, expression interface{}, vars bson.M) (interface{}, error) {
+	switch e := expression.(type) {
+	case string:
+		return evaluateReference(document, e, vars), nil
+	case bson.A:
+		array := make(bson.A, len(e))
+		for i, element := range e {
+			value, err := evaluate(document, element, vars)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = value
+		}
+		return array, nil
+	case bson.M:
+		if isOperatorDocument(e) && len(e) == 1 {
+			for operator, argument := range e {
+				return evaluateOperator(document, operator, argument, vars)
+			}
+		}
+		object := bson.M{}
+		for key, element := range e {
+			value, err := evaluate(document, element, vars)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+		return object, nil
+	}
+	return expression, nil
+}
+
+func evaluateReference(document bson.M, reference string, vars bson.M) interface{} {
+	switch {
+	case strings.HasPrefix(reference, "$$"):
+		name, path, _ := strings.Cut(reference[2:], ".")
+		var root interface{}
+		switch name {
+		case "ROOT", "CURRENT":
+			root = document
+		default:
+			root = vars[name]
+		}
+		if path == "" {
+			return root
+		}
+		return fieldPathValue(root, strings.Split(path, "."))
+	case strings.HasPrefix(reference, "$"):
+		return fieldPathValue(document, strings.Split(reference[1:], "."))
+	}
+	return reference
+}
+
+// fieldPathValue suit la sémantique des chemins d'expression : un tableau intermédiaire produit le tableau des valeurs
+func fieldPathValue(value interface{}, keys []string) interface{} {
+	if len(keys) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case bson.M:
+		return fieldPathValue(v[keys[0]], keys[1:])
+	case bson.A:
+		array := bson.A{}
+		for _, element := range v {
+			if _, ok := element.(bson.M); ok {
+				if result := fieldPathValue(element, keys); result != nil {
+					array = append(array, result)
+				}
+			}
+		}
+		return array
+	}
+	return nil
+}
+
+func evaluateArguments(document bson.M, argument interface{}, vars bson.M) (bson.A, error) {
+	arguments, ok := argument.(bson.A)
+	if !ok {
+		arguments = bson.A{argument}
+	}
+	values := make(bson.A, len(arguments))
+	for i, element := range arguments {
+		value, err := evaluate(document, element, vars)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func evaluateOperator(document bson.M, operator string, argument interface{}, vars bson.M) (interface{}, error) {
+	if operator == "$literal" {
+		return argument, nil
+	}
+	if operator == "$cond" {
+		return evaluateCond(document, argument, vars)
+	}
+	arguments, err := evaluateArguments(document, argument, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+		if len(arguments) != 2 {
+			return nil, fmt.Errorf("%s attend deux arguments", operator)
+		}
+		c := compareValues(arguments[0], arguments[1])
+		switch operator {
+		case "$eq":
+			return c == 0, nil
+		case "$ne":
+			return c != 0, nil
+		case "$gt":
+			return c > 0, nil
+		case "$gte":
+			return c >= 0, nil
+		case "$lt":
+			return c < 0, nil
+		}
+		return c <= 0, nil
+	case "$in":
+		if len(arguments) != 2 {
+			return nil, fmt.Errorf("$in attend deux arguments")
+		}
+		array, ok := arguments[1].(bson.A)
+		if !ok {
+			return nil, fmt.Errorf("$in attend un tableau en second argument")
+		}
+		for _, element := range array {
+			if equalValues(element, arguments[0]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$and":
+		for _, value := range arguments {
+			if !truthy(value) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "$or":
+		for _, value := range arguments {
+			if truthy(value) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$not":
+		return !truthy(arguments[0]), nil
+	case "$ifNull":
+		for _, value := range arguments {
+			if value != nil {
+				return value, nil
+			}
+		}
+		return nil, nil
+	case "$size":
+		array, ok := arguments[0].(bson.A)
+		if !ok {
+			return nil, fmt.Errorf("$size attend un tableau")
+		}
+		return int32(len(array)), nil
+	case "$arrayToObject":
+		return arrayToObject(arguments[0])
+	case "$concat":
+		var builder strings.Builder
+		for _, value := range arguments {
+			s, ok := value.(string)
+			if !ok {
+				return nil, nil
+			}
+			builder.WriteString(s)
+		}
+		return builder.String(), nil
+	case "$toString":
+		if arguments[0] == nil {
+			return nil, nil
+		}
+		return fmt.Sprint(arguments[0]), nil
+	}
+	return nil, fmt.Errorf("opérateur d'expression non supporté : %s", operator)
+}
+
+func evaluateCond(document bson.M, argument interface{}, vars bson.M) (interface{}, error) {
+	var ifExpression, thenExpression, elseExpression interface{}
+	switch c := argument.(type) {
+	case bson.A:
+		if len(c) != 3 {
+			return nil, fmt.Errorf("$cond attend trois arguments")
+		}
+		ifExpression, thenExpression, elseExpression = c[0], c[1], c[2]
+	case bson.M:
+		ifExpression, thenExpression, elseExpression = c["if"], c["then"], c["else"]
+	default:
+		return nil, fmt.Errorf("$cond attend un tableau ou un document")
+	}
+	condition, err := evaluate(document, ifExpression, vars)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(condition) {
+		return evaluate(document, thenExpression, vars)
+	}
+	return evaluate(document, elseExpression, vars)
+}
+
+func arrayToObject(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	array, ok := value.(bson.A)
+	if !ok {
+		return nil, fmt.Errorf("$arrayToObject attend un tableau")
+	}
+	object := bson.M{}
+	for _, element := range array {
+		switch pair := element.(type) {
+		case bson.M:
+			key, ok := pair["k"].(string)
+			if !ok {
+				return nil, fmt.Errorf("$arrayToObject attend des clés de type string")
+			}
+			object[key] = pair["v"]
+		case bson.A:
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("$arrayToObject attend des paires [clé, valeur]")
+			}
+			key, ok := pair[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("$arrayToObject attend des clés de type string")
+			}
+			object[key] = pair[1]
+		default:
+			return nil, fmt.Errorf("$arrayToObject attend des paires clé/valeur")
+		}
+	}
+	return object, nil
+}
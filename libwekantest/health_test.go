@@ -0,0 +1,53 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_withHealthyInstance(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	insertMigrations(storage, "add-swimlanes", "mutate-boardIds-in-customfields", "add-templates", "add-assignee")
+	createTestBoard(t, &wekan, "tableau-crp-health")
+	createTestBoard(t, &wekan, "tableau-autre-health")
+
+	// WHEN
+	report, err := wekan.Health(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.True(report.Healthy())
+	ass.True(report.Reachable)
+	ass.True(report.AdminIsAdmin)
+	ass.Equal(1, report.DomainBoards)
+	ass.Equal("v3", report.SchemaVersion)
+	ass.NotEmpty(report.MissingIndexes)
+	_, err = wekan.EnsureIndexes(ctx)
+	ass.NoError(err)
+	report, _ = wekan.Health(ctx)
+	ass.Empty(report.MissingIndexes)
+}
+
+func TestHealth_withNonAdminUser(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	insertMigrations(storage, "add-swimlanes", "mutate-boardIds-in-customfields", "add-templates", "add-assignee")
+	ass.NoError(storage.Insert("users", libwekan.BuildUser("simple.user", "", "simple.user")))
+	wekan := libwekan.InitWithStorage(storage, "simple.user", "^tableau-crp.*")
+
+	// WHEN
+	report, err := wekan.Health(ctx)
+
+	// THEN
+	ass.IsType(libwekan.UnhealthyError{}, err)
+	ass.True(report.AdminExists)
+	ass.False(report.AdminIsAdmin)
+	ass.Len(report.Problems, 1)
+	ass.Regexp(`^l'utilisateur n'est pas admin: id = \w+ \(l'utilisateur n'a pas le rôle administrateur\)$`, report.Problems[0])
+	ass.IsType(libwekan.NotPrivilegedError{}, wekan.AssertPrivileged(ctx))
+}
@@ -0,0 +1,61 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIndexes_EnsureIndexes(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	_, err := storage.Collection("boards").CreateIndex(ctx, mongo.IndexModel{Keys: bson.D{{Key: "slug", Value: 1}, {Key: "archived", Value: 1}}})
+	ass.NoError(err)
+	before, err := wekan.CheckIndexes(ctx)
+	ass.NoError(err)
+	ass.Len(before.Missing(), len(wekan.RecommendedIndexes())-1)
+	ass.Equal(before.Recommended[0].CoveredBy, "slug_1_archived_1")
+
+	// WHEN
+	report, err := wekan.EnsureIndexes(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.Empty(report.Missing())
+	ass.False(report.Recommended[0].Created)
+	ass.True(report.Recommended[1].Created)
+	ass.Contains(report.Existing["cards"], "members_1")
+	after, err := wekan.CheckIndexes(ctx)
+	ass.NoError(err)
+	ass.Empty(after.Missing())
+}
+
+func TestIndexes_EnsureIndexes_withReversedSingleFieldIndex(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	_, err := storage.Collection("cards").CreateIndex(ctx, mongo.IndexModel{Keys: bson.D{{Key: "members", Value: -1}}})
+	ass.NoError(err)
+
+	report, err := wekan.EnsureIndexes(ctx)
+
+	ass.NoError(err)
+	ass.Contains(report.Existing["cards"], "members_-1")
+	ass.NotContains(report.Existing["cards"], "members_1")
+}
+
+func TestIndexes_EnsureIndexes_withDryRun(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	dryRunWekan, journal := wekan.DryRun()
+
+	_, err := dryRunWekan.EnsureIndexes(ctx)
+
+	ass.NoError(err)
+	ass.Len(journal.Mutations(), len(wekan.RecommendedIndexes()))
+	ass.Equal("createIndex", journal.Mutations()[0].Operation)
+	report, _ := wekan.CheckIndexes(ctx)
+	ass.Len(report.Missing(), len(wekan.RecommendedIndexes()))
+}
@@ -0,0 +1,80 @@
+package libwekantest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogging_SelectConfig(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	wekan := New("signaux.faibles", "^tableau-crp.*", libwekan.WithLogger(logger))
+	createTestBoard(t, &wekan, "tableau-crp-logging")
+	buffer.Reset()
+
+	// WHEN
+	_, err := wekan.SelectConfig(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.Contains(buffer.String(), "level=DEBUG msg=libwekan operation=aggregate collection=boards filter=$match|$project|$lookup")
+	ass.NotContains(buffer.String(), "level=ERROR")
+}
+
+func TestLogging_SelectConfig_withError(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	wekan := New("signaux.faibles", "^tableau-crp.*", libwekan.WithLogger(logger))
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// WHEN
+	_, err := wekan.SelectConfig(canceledCtx)
+
+	// THEN
+	ass.ErrorIs(err, context.Canceled)
+	ass.Contains(buffer.String(), "level=ERROR msg=libwekan operation=aggregate collection=boards")
+	ass.Contains(buffer.String(), "error=\"context canceled\"")
+}
+
+func TestLogging_SelectConfig_emptyDomain(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	wekan := New("signaux.faibles", "^tableau-crp.*", libwekan.WithLogger(logger))
+
+	// WHEN
+	config, err := wekan.SelectConfig(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.Empty(config.Boards)
+	ass.NotContains(buffer.String(), "level=ERROR")
+}
+
+func TestLogging_GetBoardFromID_notFound(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, nil))
+	levels := libwekan.LogLevels{Operation: slog.LevelInfo, Error: slog.LevelError}
+	wekan := New("signaux.faibles", "^tableau-crp.*", libwekan.WithLogger(logger), libwekan.WithLogLevels(levels))
+
+	// WHEN
+	_, err := wekan.GetBoardFromID(ctx, "unknown")
+
+	// THEN
+	ass.IsType(libwekan.BoardNotFoundError{}, err)
+	ass.Contains(buffer.String(), "level=INFO msg=libwekan operation=findOne collection=boards filter=_id")
+	ass.Contains(buffer.String(), "found=false")
+}
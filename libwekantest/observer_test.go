@@ -0,0 +1,53 @@
+package libwekantest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	operations []libwekan.Operation
+}
+
+func (observer *recordingObserver) OperationStarted(ctx context.Context, _ libwekan.Operation) context.Context {
+	return ctx
+}
+
+func (observer *recordingObserver) OperationEnded(_ context.Context, operation libwekan.Operation) {
+	observer.operations = append(observer.operations, operation)
+}
+
+func TestObserver_InsertCardAndAddLabelToCard(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	observer := &recordingObserver{}
+	wekan := New("signaux.faibles", "^tableau-crp.*", libwekan.WithObserver(observer))
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-observer")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), wekan.AdminID())
+	observer.operations = nil
+
+	// WHEN
+	ass.NoError(wekan.InsertCard(ctx, card))
+	err := wekan.AddLabelToCard(ctx, "unknown", board.Labels[0].ID)
+
+	// THEN
+	ass.IsType(libwekan.CardNotFoundError{}, err)
+	names := make(map[string]libwekan.Operation)
+	for _, operation := range observer.operations {
+		names[operation.Name] = operation
+	}
+	insertCard, ok := names["InsertCard"]
+	ass.True(ok)
+	ass.Equal(string(card.ID), insertCard.Targets["cardID"])
+	ass.Empty(insertCard.ErrorType)
+	ass.Contains(names, "GetListFromID")
+	addLabel, ok := names["AddLabelToCard"]
+	ass.True(ok)
+	ass.Equal("CardNotFoundError", addLabel.ErrorType)
+	ass.Equal(string(board.Labels[0].ID), addLabel.Targets["labelID"])
+	last := observer.operations[len(observer.operations)-1]
+	ass.Equal("AddLabelToCard", last.Name, "l'opération englobante se termine en dernier")
+}
@@ -0,0 +1,335 @@
+package libwekantest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getPath retourne la valeur située au chemin `a.b.c` du document, sans parcourir les tableaux
+func getPath(document interface{}, path string) (interface{}, bool) {
+	current := document
+	for _, key := range strings.Split(path, ".") {
+		switch value := current.(type) {
+		case bson.M:
+			next, ok := value[key]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case bson.A:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// resolvePath retourne toutes les valeurs atteintes par le chemin en parcourant les tableaux intermédiaires,
+// à la manière des filtres mongodb (ex: `members.userId`)
+func resolvePath(value interface{}, keys []string) []interface{} {
+	if len(keys) == 0 {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case bson.M:
+		next, ok := v[keys[0]]
+		if !ok {
+			return nil
+		}
+		return resolvePath(next, keys[1:])
+	case bson.A:
+		if index, err := strconv.Atoi(keys[0]); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil
+			}
+			return resolvePath(v[index], keys[1:])
+		}
+		var values []interface{}
+		for _, element := range v {
+			if _, ok := element.(bson.M); ok {
+				values = append(values, resolvePath(element, keys)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// candidates ajoute aux valeurs trouvées les éléments des tableaux, une condition est satisfaite
+// si le tableau ou l'un de ses éléments la satisfait
+func candidates(values []interface{}) []interface{} {
+	var all []interface{}
+	for _, value := range values {
+		all = append(all, value)
+		if array, ok := value.(bson.A); ok {
+			all = append(all, array...)
+		}
+	}
+	return all
+}
+
+func isOperatorDocument(value interface{}) bool {
+	document, ok := value.(bson.M)
+	if !ok || len(document) == 0 {
+		return false
+	}
+	for key := range document {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return true
+}
+
+// matchDocument teste si le document satisfait le filtre, vars contient les variables `$$var` des $lookup
+func matchDocument(document bson.M, filter bson.M, vars bson.M) (bool, error) {
+	for key, condition := range filter {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogical(document, key, condition, vars)
+		case "$expr":
+			var result interface{}
+			result, err = evaluate(document, condition, vars)
+			ok = truthy(result)
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("opérateur de requête non supporté : %s", key)
+			}
+			ok, err = matchValues(resolvePath(document, strings.Split(key, ".")), condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(document bson.M, operator string, condition interface{}, vars bson.M) (bool, error) {
+	filters, ok := condition.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("%s attend un tableau", operator)
+	}
+	for _, element := range filters {
+		filter, ok := element.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("%s attend un tableau de documents", operator)
+		}
+		matched, err := matchDocument(document, filter, vars)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case operator == "$and" && !matched:
+			return false, nil
+		case operator == "$or" && matched:
+			return true, nil
+		case operator == "$nor" && matched:
+			return false, nil
+		}
+	}
+	return operator != "$or", nil
+}
+
+// matchValues teste une condition sur les valeurs trouvées à un chemin donné
+func matchValues(values []interface{}, condition interface{}) (bool, error) {
+	if !isOperatorDocument(condition) {
+		return matchEquality(values, condition), nil
+	}
+	operators := condition.(bson.M)
+	for operator, argument := range operators {
+		ok, err := matchOperator(values, operator, argument, operators)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchEquality(values []interface{}, condition interface{}) bool {
+	if regex, ok := condition.(primitive.Regex); ok {
+		return matchRegex(values, regex.Pattern, regex.Options)
+	}
+	if condition == nil && len(values) == 0 {
+		return true
+	}
+	for _, value := range candidates(values) {
+		if equalValues(value, condition) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRegex(values []interface{}, pattern string, options string) bool {
+	if options != "" {
+		pattern = "(?" + strings.ReplaceAll(options, "x", "") + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	for _, value := range candidates(values) {
+		if s, ok := value.(string); ok && re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOperator(values []interface{}, operator string, argument interface{}, operators bson.M) (bool, error) {
+	switch operator {
+	case "$eq":
+		return matchEquality(values, argument), nil
+	case "$ne":
+		return !matchEquality(values, argument), nil
+	case "$in", "$nin":
+		array, ok := argument.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("%s attend un tableau", operator)
+		}
+		found := false
+		for _, element := range array {
+			if matchEquality(values, element) {
+				found = true
+				break
+			}
+		}
+		return found == (operator == "$in"), nil
+	case "$exists":
+		return (len(values) > 0) == truthy(argument), nil
+	case "$regex":
+		if regex, ok := argument.(primitive.Regex); ok {
+			return matchRegex(values, regex.Pattern, regex.Options), nil
+		}
+		options, _ := operators["$options"].(string)
+		pattern, ok := argument.(string)
+		if !ok {
+			return false, fmt.Errorf("$regex attend une chaîne")
+		}
+		return matchRegex(values, pattern, options), nil
+	case "$options":
+		return true, nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, value := range candidates(values) {
+			if typeOrder(value) != typeOrder(argument) {
+				continue
+			}
+			c := compareValues(value, argument)
+			if (operator == "$gt" && c > 0) || (operator == "$gte" && c >= 0) ||
+				(operator == "$lt" && c < 0) || (operator == "$lte" && c <= 0) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$type":
+		for _, value := range values {
+			if matchType(value, argument) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$size":
+		size, _ := toFloat(argument)
+		for _, value := range values {
+			if array, ok := value.(bson.A); ok && len(array) == int(size) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$elemMatch":
+		filter, ok := argument.(bson.M)
+		if !ok {
+			return false, fmt.Errorf("$elemMatch attend un document")
+		}
+		for _, value := range values {
+			array, _ := value.(bson.A)
+			for _, element := range array {
+				if matched, err := matchElement(element, filter); err != nil || matched {
+					return matched, err
+				}
+			}
+		}
+		return false, nil
+	case "$not":
+		matched, err := matchValues(values, argument)
+		return !matched, err
+	}
+	return false, fmt.Errorf("opérateur de requête non supporté : %s", operator)
+}
+
+// matchElement teste un élément de tableau, soit avec des opérateurs soit comme un document
+func matchElement(element interface{}, filter bson.M) (bool, error) {
+	if isOperatorDocument(filter) {
+		return matchValues([]interface{}{element}, filter)
+	}
+	document, ok := element.(bson.M)
+	if !ok {
+		return false, nil
+	}
+	return matchDocument(document, filter, nil)
+}
+
+func matchType(value interface{}, bsonType interface{}) bool {
+	names := map[string]string{
+		"1": "double", "2": "string", "3": "object", "4": "array", "7": "objectId",
+		"8": "bool", "9": "date", "10": "null", "11": "regex", "16": "int", "18": "long",
+	}
+	expected := fmt.Sprint(bsonType)
+	if name, ok := names[expected]; ok {
+		expected = name
+	}
+	return expected == typeName(value)
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case bson.M:
+		return "object"
+	case bson.A:
+		return "array"
+	case primitive.ObjectID:
+		return "objectId"
+	case bool:
+		return "bool"
+	case primitive.DateTime:
+		return "date"
+	case nil, primitive.Null:
+		return "null"
+	case primitive.Regex:
+		return "regex"
+	case int32:
+		return "int"
+	case int64:
+		return "long"
+	}
+	return "unknown"
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return false
+	case bool:
+		return v
+	}
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_matchDocument(t *testing.T) {
+	document := bson.M{
+		"_id":      "boardID",
+		"slug":     "tableau-crp-bfc",
+		"archived": false,
+		"sort":     int32(3),
+		"members": bson.A{
+			bson.M{"userId": "toto", "isActive": true},
+			bson.M{"userId": "tata", "isActive": false},
+		},
+		"labelIds": bson.A{"a", "b"},
+	}
+	testCases := []struct {
+		filter   bson.M
+		expected bool
+	}{
+		{bson.M{"_id": "boardID"}, true},
+		{bson.M{"_id": "other"}, false},
+		{bson.M{"members.userId": "tata"}, true},
+		{bson.M{"members.userId": "titi"}, false},
+		{bson.M{"labelIds": "b"}, true},
+		{bson.M{"labelIds": bson.M{"$in": bson.A{"c", "a"}}}, true},
+		{bson.M{"labelIds": bson.M{"$type": 4}}, true},
+		{bson.M{"slug": primitive.Regex{Pattern: "^TABLEAU-CRP", Options: "i"}}, true},
+		{bson.M{"slug": bson.M{"$regex": "^tableau-pas"}}, false},
+		{bson.M{"sort": bson.M{"$gte": 3.0}}, true},
+		{bson.M{"missing": bson.M{"$exists": false}}, true},
+		{bson.M{"missing": nil}, true},
+		{bson.M{"$or": bson.A{bson.M{"_id": "other"}, bson.M{"archived": false}}}, true},
+		{bson.M{"members": bson.M{"$elemMatch": bson.M{"userId": "tata", "isActive": true}}}, false},
+		{bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$boardId"}}}, true},
+	}
+	for _, testCase := range testCases {
+		actual, err := matchDocument(document, testCase.filter, bson.M{"boardId": "boardID"})
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expected, actual, "%v", testCase.filter)
+	}
+}
+
+func Test_applyUpdate_withArrayFilters(t *testing.T) {
+	ass := assert.New(t)
+	document := bson.M{
+		"_id": "boardID",
+		"members": bson.A{
+			bson.M{"userId": "toto", "isActive": false},
+			bson.M{"userId": "tata", "isActive": false},
+		},
+		"labelIds": bson.A{"a", "b"},
+	}
+	update := bson.M{
+		"$set":      bson.M{"members.$[member].isActive": true},
+		"$pull":     bson.M{"labelIds": "a"},
+		"$addToSet": bson.M{"stars": "toto"},
+	}
+
+	updated, err := applyUpdate(document, update, []bson.M{{"member.userId": "tata"}})
+
+	ass.NoError(err)
+	ass.Equal(false, updated["members"].(bson.A)[0].(bson.M)["isActive"])
+	ass.Equal(true, updated["members"].(bson.A)[1].(bson.M)["isActive"])
+	ass.Equal(bson.A{"b"}, updated["labelIds"])
+	ass.Equal(bson.A{"toto"}, updated["stars"])
+	ass.Equal(bson.A{"a", "b"}, document["labelIds"], "le document d'origine ne doit pas être modifié")
+}
@@ -0,0 +1,129 @@
+package libwekantest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var networkError = mongo.CommandError{Labels: []string{"NetworkError"}, Message: "connection reset"}
+
+// flakyStorage fait échouer les `failures` premières lectures par une erreur réseau,
+// et les `activityFailures` premières insertions d'activité lorsque le champ est renseigné
+type flakyStorage struct {
+	*Storage
+	failures         *int
+	activityFailures *int
+}
+
+type flakyCollection struct {
+	libwekan.Collection
+	name             string
+	failures         *int
+	activityFailures *int
+}
+
+type failedSingleResult struct{}
+
+func (failedSingleResult) Decode(interface{}) error {
+	return networkError
+}
+
+func (storage flakyStorage) Collection(name string) libwekan.Collection {
+	return flakyCollection{storage.Storage.Collection(name), name, storage.failures, storage.activityFailures}
+}
+
+func (c flakyCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if c.name == "activities" && c.activityFailures != nil && *c.activityFailures > 0 {
+		*c.activityFailures--
+		return nil, networkError
+	}
+	return c.Collection.InsertOne(ctx, document, opts...)
+}
+
+func (c flakyCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) libwekan.SingleResult {
+	if *c.failures > 0 {
+		*c.failures--
+		return failedSingleResult{}
+	}
+	return c.Collection.FindOne(ctx, filter, opts...)
+}
+
+func newFlakyWekan(failures *int) libwekan.Wekan {
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	policy := libwekan.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: libwekan.RetryNetworkErrors}
+	return libwekan.InitWithStorage(flakyStorage{storage, failures, nil}, "signaux.faibles", "^tableau-crp.*", libwekan.WithRetryPolicy(policy))
+}
+
+func TestRetry_GetUserFromUsername_succeedsAfterTransientErrors(t *testing.T) {
+	failures := 2
+	wekan := newFlakyWekan(&failures)
+
+	user, err := wekan.GetUserFromUsername(ctx, "signaux.faibles")
+
+	assert.NoError(t, err)
+	assert.Equal(t, libwekan.Username("signaux.faibles"), user.Username)
+}
+
+func TestRetry_GetUserFromUsername_reportsAttempts(t *testing.T) {
+	ass := assert.New(t)
+	failures := 5
+	wekan := newFlakyWekan(&failures)
+
+	_, err := wekan.GetUserFromUsername(ctx, "signaux.faibles")
+
+	ass.Error(err)
+	ass.True(errors.As(err, &libwekan.RetryExhaustedError{}))
+	ass.Contains(err.Error(), "échec après 3 tentatives")
+	ass.Equal(2, failures)
+}
+
+func TestRetry_EnsureUserIsActiveBoardMember_isRetriedAsAWhole(t *testing.T) {
+	ass := assert.New(t)
+	failures := 0
+	wekan := newFlakyWekan(&failures)
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-retry")
+	user := createTestUser(t, &wekan, "retry")
+
+	// WHEN
+	failures = 1
+	modified, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+
+	// THEN
+	ass.NoError(err)
+	ass.True(modified)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.True(actualBoard.UserIsActiveMember(user))
+}
+
+func TestRetry_EnsureUserIsActiveBoardMember_rollsBackWhenActivityFails(t *testing.T) {
+	ass := assert.New(t)
+	_, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	failures, activityFailures := 0, 0
+	policy := libwekan.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Retryable: libwekan.RetryNetworkErrors}
+	wekan := libwekan.InitWithStorage(flakyStorage{storage, &failures, &activityFailures}, "signaux.faibles", "^tableau-crp.*", libwekan.WithRetryPolicy(policy))
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-retry-activity")
+	user := createTestUser(t, &wekan, "retryActivity")
+	activities := storage.Count("activities")
+
+	// WHEN
+	activityFailures = 1
+	_, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+
+	// THEN l'ajout du membre est annulé avec l'activité, l'opération peut être relancée
+	ass.Error(err)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.False(actualBoard.UserIsMember(user))
+	ass.Equal(activities, storage.Count("activities"))
+
+	modified, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	ass.NoError(err)
+	ass.True(modified)
+	ass.Equal(activities+1, storage.Count("activities"))
+}
@@ -0,0 +1,63 @@
+package libwekantest
+
+import (
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func insertMigrations(storage *Storage, names ...string) {
+	for _, name := range names {
+		_ = storage.Insert("meteor-migrations", bson.M{"_id": name, "name": name})
+	}
+}
+
+func TestSchema_DetectSchema_withCardComments(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	insertMigrations(storage, "add-swimlanes", "mutate-boardIds-in-customfields", "add-templates", "add-assignee",
+		"migrate-attachments-collectionFS-to-ostrioFiles")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-schema")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), wekan.AdminID())
+	ass.NoError(wekan.InsertCard(ctx, card))
+	comment := libwekan.Comment{ID: "commentID", BoardID: board.ID, CardID: card.ID, Text: t.Name(), UserID: wekan.AdminID()}
+	ass.NoError(storage.Insert("card_comments", comment))
+
+	// WHEN
+	schema, err := wekan.DetectSchema(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal(libwekan.SchemaV6, schema.Version)
+	cardWithComments, err := wekan.GetCardWithCommentsFromID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Len(cardWithComments.Comments, 1)
+}
+
+func TestSchema_DetectSchema_unsupported(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+
+	_, err := wekan.DetectSchema(ctx)
+	ass.IsType(libwekan.UnsupportedSchemaError{}, err)
+
+	insertMigrations(storage, "add-swimlanes")
+	_, err = wekan.DetectSchema(ctx)
+	ass.IsType(libwekan.UnsupportedSchemaError{}, err)
+	ass.Contains(err.Error(), "add-assignee")
+}
+
+func TestSchema_DetectSchema_withSettingsOnly(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	ass.NoError(storage.Insert("settings", bson.M{"_id": "settings", "disableRegistration": true}))
+
+	_, err := wekan.DetectSchema(ctx)
+
+	ass.IsType(libwekan.UnsupportedSchemaError{}, err)
+	ass.Contains(err.Error(), "aucune migration enregistrée")
+	ass.NotContains(err.Error(), "add-assignee")
+}
@@ -0,0 +1,378 @@
+// Package libwekantest fournit une implémentation en mémoire de libwekan.Storage
+// destinée aux tests unitaires des briques logicielles qui utilisent libwekan.
+//
+// L'implémentation interprète le sous-ensemble du langage de requête mongodb utilisé par libwekan
+// (filtres, opérateurs de mise à jour, étapes d'agrégation) sans nécessiter de serveur.
+package libwekantest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/signaux-faibles/libwekan"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Storage est une implémentation en mémoire de libwekan.Storage, sûre en accès concurrent
+type Storage struct {
+	mu          sync.RWMutex
+	collections map[string][]bson.M
+	indexes     map[string][]bson.M
+	watchMu     sync.Mutex
+	watchers    map[*changeStream]struct{}
+}
+
+type collection struct {
+	storage *Storage
+	name    string
+}
+
+// NewStorage retourne un objet Storage vide
+func NewStorage() *Storage {
+	return &Storage{
+		collections: make(map[string][]bson.M),
+		indexes:     make(map[string][]bson.M),
+		watchers:    make(map[*changeStream]struct{}),
+	}
+}
+
+// New retourne un objet libwekan.Wekan adossé à un stockage en mémoire
+// qui contient l'utilisateur administrateur `adminUsername`
+func New(adminUsername libwekan.Username, slugDomainRegexp string, opts ...libwekan.Option) libwekan.Wekan {
+	wekan, _ := NewWithStorage(adminUsername, slugDomainRegexp, opts...)
+	return wekan
+}
+
+// NewWithStorage fonctionne comme New et retourne en plus le stockage pour permettre l'insertion de données de test
+func NewWithStorage(adminUsername libwekan.Username, slugDomainRegexp string, opts ...libwekan.Option) (libwekan.Wekan, *Storage) {
+	storage := NewStorage()
+	admin := libwekan.BuildUser(string(adminUsername), "", string(adminUsername)).Admin(true)
+	// l'insertion d'un document valide dans un stockage vide ne peut pas échouer
+	_ = storage.Insert("users", admin)
+	return libwekan.InitWithStorage(storage, adminUsername, slugDomainRegexp, opts...), storage
+}
+
+// Insert ajoute des documents bruts dans une collection, sans activité ni contrôle
+func (storage *Storage) Insert(collectionName string, documents ...interface{}) error {
+	for _, document := range documents {
+		if _, err := storage.Collection(collectionName).InsertOne(context.Background(), document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count retourne le nombre de documents présents dans une collection
+func (storage *Storage) Count(collectionName string) int {
+	storage.mu.RLock()
+	defer storage.mu.RUnlock()
+	return len(storage.collections[collectionName])
+}
+
+func (storage *Storage) Collection(name string) libwekan.Collection {
+	return collection{storage, name}
+}
+
+func (storage *Storage) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+type transactionKey struct{}
+
+// WithTransaction restaure l'état des collections lorsque fn retourne une erreur.
+// Les écritures concurrentes effectuées hors de la transaction pendant son exécution sont alors également annulées.
+func (storage *Storage) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) != nil {
+		return fn(ctx)
+	}
+	storage.mu.RLock()
+	snapshot := make(map[string][]bson.M, len(storage.collections))
+	for name := range storage.collections {
+		snapshot[name] = storage.documents(name)
+	}
+	storage.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, transactionKey{}, true)); err != nil {
+		storage.mu.Lock()
+		storage.collections = snapshot
+		storage.mu.Unlock()
+		storage.notify("")
+		return err
+	}
+	return nil
+}
+
+// documents retourne une copie des documents de la collection, le verrou doit être détenu
+func (storage *Storage) documents(name string) []bson.M {
+	documents := make([]bson.M, len(storage.collections[name]))
+	for i, document := range storage.collections[name] {
+		documents[i] = deepCopy(document).(bson.M)
+	}
+	return documents
+}
+
+func (c collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) libwekan.SingleResult {
+	findOptions := options.Find().SetLimit(1)
+	for _, opt := range opts {
+		if opt != nil && opt.Sort != nil {
+			findOptions.SetSort(opt.Sort)
+		}
+	}
+	cur, err := c.Find(ctx, filter, findOptions)
+	if err != nil {
+		return singleResult{err: err}
+	}
+	documents := cur.(*cursor).documents
+	if len(documents) == 0 {
+		return singleResult{err: mongo.ErrNoDocuments}
+	}
+	return singleResult{document: documents[0]}
+}
+
+func (c collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (libwekan.Cursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query, err := normalizeDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	c.storage.mu.RLock()
+	defer c.storage.mu.RUnlock()
+
+	var documents []bson.M
+	for _, document := range c.storage.collections[c.name] {
+		ok, err := matchDocument(document, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			documents = append(documents, deepCopy(document).(bson.M))
+		}
+	}
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if opt.Sort != nil {
+			if documents, err = sortDocuments(documents, opt.Sort); err != nil {
+				return nil, err
+			}
+		}
+		if opt.Skip != nil {
+			documents = documents[minInt(int(*opt.Skip), len(documents)):]
+		}
+		if opt.Limit != nil && *opt.Limit > 0 {
+			documents = documents[:minInt(int(*opt.Limit), len(documents))]
+		}
+	}
+	return &cursor{documents: documents, position: -1}, nil
+}
+
+func (c collection) Aggregate(ctx context.Context, pipeline interface{}, _ ...*options.AggregateOptions) (libwekan.Cursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	stages, err := normalizePipeline(pipeline)
+	if err != nil {
+		return nil, err
+	}
+	c.storage.mu.RLock()
+	defer c.storage.mu.RUnlock()
+
+	documents, err := aggregate(c.storage, c.storage.documents(c.name), stages, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor{documents: documents, position: -1}, nil
+}
+
+func (c collection) InsertOne(ctx context.Context, document interface{}, _ ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	insertable, err := normalizeDocument(document)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := insertable["_id"]; !ok {
+		insertable["_id"] = primitive.NewObjectID()
+	}
+
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	for _, existing := range c.storage.collections[c.name] {
+		if equalValues(existing["_id"], insertable["_id"]) {
+			return nil, duplicateKeyError(c.name)
+		}
+	}
+	c.storage.collections[c.name] = append(c.storage.collections[c.name], insertable)
+	c.storage.notify(c.name)
+	return &mongo.InsertOneResult{InsertedID: insertable["_id"]}, nil
+}
+
+func (c collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.update(ctx, filter, update, false, opts)
+}
+
+func (c collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.update(ctx, filter, update, true, opts)
+}
+
+// update modifie le premier document sélectionné par le filtre, ou tous lorsque many est vrai
+func (c collection) update(ctx context.Context, filter interface{}, update interface{}, many bool, opts []*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query, err := normalizeDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	modifier, err := normalizeDocument(update)
+	if err != nil {
+		return nil, err
+	}
+	arrayFilters, err := normalizeArrayFilters(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	documents := c.storage.collections[c.name]
+	updates := make(map[int]bson.M)
+	result := &mongo.UpdateResult{}
+	for i, document := range documents {
+		ok, err := matchDocument(document, query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		updated, err := applyUpdate(document, modifier, arrayFilters)
+		if err != nil {
+			return nil, err
+		}
+		result.MatchedCount++
+		if !equalValues(document, updated) {
+			updates[i] = updated
+			result.ModifiedCount++
+		}
+		if !many {
+			break
+		}
+	}
+	// les documents ne sont remplacés qu'une fois toutes les mises à jour calculées sans erreur
+	for i, updated := range updates {
+		documents[i] = updated
+	}
+	if result.ModifiedCount > 0 {
+		c.storage.notify(c.name)
+	}
+	return result, nil
+}
+
+func (c collection) DeleteOne(ctx context.Context, filter interface{}, _ ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return c.delete(ctx, filter, false)
+}
+
+func (c collection) DeleteMany(ctx context.Context, filter interface{}, _ ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return c.delete(ctx, filter, true)
+}
+
+// delete supprime le premier document sélectionné par le filtre, ou tous lorsque many est vrai
+func (c collection) delete(ctx context.Context, filter interface{}, many bool) (*mongo.DeleteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	query, err := normalizeDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	documents := c.storage.collections[c.name]
+	kept := make([]bson.M, 0, len(documents))
+	var deleted int64
+	for _, document := range documents {
+		if deleted == 0 || many {
+			ok, err := matchDocument(document, query, nil)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, document)
+	}
+	if deleted > 0 {
+		c.storage.collections[c.name] = kept
+		c.storage.notify(c.name)
+	}
+	return &mongo.DeleteResult{DeletedCount: deleted}, nil
+}
+
+// ListIndexes retourne l'index `_id_` des collections existantes suivi des index créés par CreateIndex
+func (c collection) ListIndexes(ctx context.Context) (libwekan.Cursor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.storage.mu.RLock()
+	defer c.storage.mu.RUnlock()
+	_, exists := c.storage.collections[c.name]
+	if !exists && len(c.storage.indexes[c.name]) == 0 {
+		return &cursor{position: -1}, nil
+	}
+	indexes := []bson.M{{"name": "_id_", "key": bson.D{{Key: "_id", Value: int32(1)}}}}
+	indexes = append(indexes, c.storage.indexes[c.name]...)
+	return &cursor{documents: indexes, position: -1}, nil
+}
+
+// CreateIndex mémorise l'index sans l'utiliser, les requêtes en mémoire parcourant toujours la collection
+func (c collection) CreateIndex(ctx context.Context, model mongo.IndexModel) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	keys, ok := model.Keys.(bson.D)
+	if !ok {
+		return "", fmt.Errorf("les clés d'index doivent être fournies sous forme de bson.D")
+	}
+	var parts []string
+	for _, element := range keys {
+		direction, _ := toFloat(element.Value)
+		parts = append(parts, fmt.Sprintf("%s_%d", element.Key, int(direction)))
+	}
+	name := strings.Join(parts, "_")
+	if model.Options != nil && model.Options.Name != nil {
+		name = *model.Options.Name
+	}
+	c.storage.mu.Lock()
+	defer c.storage.mu.Unlock()
+	for _, index := range c.storage.indexes[c.name] {
+		if index["name"] == name {
+			return name, nil
+		}
+	}
+	c.storage.indexes[c.name] = append(c.storage.indexes[c.name], bson.M{"name": name, "key": keys})
+	return name, nil
+}
+
+func duplicateKeyError(collectionName string) error {
+	return mongo.WriteException{
+		WriteErrors: mongo.WriteErrors{{
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: " + collectionName,
+			Raw:     bson.Raw{},
+		}},
+	}
+}
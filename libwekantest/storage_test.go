@@ -0,0 +1,337 @@
+package libwekantest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signaux-faibles/libwekan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ctx = context.Background()
+
+// createTestBoard insère une board avec une swimlane et une liste dans le stockage en mémoire
+func createTestBoard(t *testing.T, wekan *libwekan.Wekan, slug string) (libwekan.Board, libwekan.Swimlane, libwekan.List) {
+	board := libwekan.BuildBoard(t.Name(), slug, "board")
+	require.NoError(t, wekan.InsertBoard(ctx, board))
+	swimlane := libwekan.BuildSwimlane(board.ID, "swimlane", t.Name(), 0)
+	require.NoError(t, wekan.InsertSwimlane(ctx, swimlane))
+	list := libwekan.BuildList(board.ID, t.Name(), 0)
+	require.NoError(t, wekan.InsertList(ctx, list))
+	return board, swimlane, list
+}
+
+// createTestUser insère un utilisateur et ses templates dans le stockage en mémoire
+func createTestUser(t *testing.T, wekan *libwekan.Wekan, name string) libwekan.User {
+	user := libwekan.BuildUser(t.Name()+name, name, t.Name()+name)
+	require.NoError(t, wekan.InsertUser(ctx, user))
+	actualUser, err := wekan.GetUserFromID(ctx, user.ID)
+	require.NoError(t, err)
+	return actualUser
+}
+
+func TestStorage_New_isPrivileged(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+
+	ass.NoError(wekan.Ping(ctx))
+	ass.NoError(wekan.AssertPrivileged(ctx))
+	ass.NotEmpty(wekan.AdminID())
+}
+
+func TestStorage_InsertBoard_withGetBoard(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+
+	// GIVEN
+	board := libwekan.BuildBoard("la board à toto", "la-board-a-toto", "board")
+
+	// WHEN
+	err := wekan.InsertBoard(ctx, board)
+
+	// THEN
+	ass.NoError(err)
+	actualBoard, err := wekan.GetBoardFromID(ctx, board.ID)
+	ass.NoError(err)
+	ass.Equal(board, actualBoard)
+	actualBoard, err = wekan.GetBoardFromSlug(ctx, board.Slug)
+	ass.NoError(err)
+	ass.Equal(board, actualBoard)
+	_, err = wekan.GetBoardFromID(ctx, "unknown")
+	ass.IsType(libwekan.BoardNotFoundError{}, err)
+}
+
+func TestStorage_InsertUser_isActiveMemberOfTemplates(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+
+	// WHEN
+	user := createTestUser(t, &wekan, "")
+
+	// THEN
+	templates, err := wekan.GetBoardFromID(ctx, user.Profile.TemplatesBoardId)
+	ass.NoError(err)
+	ass.True(templates.UserIsActiveMember(user))
+	ass.Equal(3, storage.Count("swimlanes"))
+	activities, err := wekan.SelectActivitiesFromBoardID(ctx, templates.ID)
+	ass.NoError(err)
+	ass.NotEmpty(activities)
+}
+
+func TestStorage_EnsureUserIsInactiveBoardMember(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	_, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	ass.NoError(err)
+
+	// WHEN
+	modified, err := wekan.EnsureUserIsInactiveBoardMember(ctx, board.ID, user.ID)
+
+	// THEN
+	ass.NoError(err)
+	ass.True(modified)
+	actualBoard, _ := wekan.GetBoardFromID(ctx, board.ID)
+	ass.True(actualBoard.UserIsMember(user))
+	ass.False(actualBoard.UserIsActiveMember(user))
+	activities, _ := wekan.SelectActivitiesFromBoardID(ctx, board.ID)
+	activityTypes := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		activityTypes = append(activityTypes, activity.ActivityType)
+	}
+	ass.Contains(activityTypes, "addBoardMember")
+	ass.Contains(activityTypes, "removeBoardMember")
+}
+
+func TestStorage_InsertCard_withActivityAndComments(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), user.ID)
+
+	// WHEN
+	err := wekan.InsertCard(ctx, card)
+	ass.NoError(err)
+	comment := libwekan.Comment{ID: "commentID", BoardID: board.ID, CardID: card.ID, Text: t.Name(), UserID: user.ID}
+	ass.NoError(storage.Insert("card_comments", comment))
+
+	// THEN
+	actualCard, err := wekan.GetCardFromID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Equal(card, actualCard)
+	activities, err := wekan.SelectActivitiesFromCardID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Len(activities, 1)
+	ass.Equal("createCard", activities[0].ActivityType)
+	cardWithComments, err := wekan.GetCardWithCommentsFromID(ctx, card.ID)
+	ass.NoError(err)
+	ass.Equal(card.ID, cardWithComments.Card.ID)
+	ass.Len(cardWithComments.Comments, 1)
+	ass.Equal(comment.Text, cardWithComments.Comments[0].Text)
+}
+
+func TestStorage_CardLabelsAndMembers(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	_, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	ass.NoError(err)
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), user.ID)
+	ass.NoError(wekan.InsertCard(ctx, card))
+
+	// WHEN
+	ass.NoError(wekan.AddLabelToCard(ctx, card.ID, board.Labels[0].ID))
+	ass.IsType(libwekan.NothingDoneError{}, wekan.AddLabelToCard(ctx, card.ID, board.Labels[0].ID))
+	ass.NoError(wekan.AddMemberToCard(ctx, card, user, user))
+	ass.NoError(wekan.AddAssigneeToCard(ctx, card, user, user))
+
+	// THEN
+	actualCard, _ := wekan.GetCardFromID(ctx, card.ID)
+	ass.Equal([]libwekan.BoardLabelID{board.Labels[0].ID}, actualCard.LabelIDs)
+	ass.Equal([]libwekan.UserID{user.ID}, actualCard.Members)
+	ass.Equal([]libwekan.UserID{user.ID}, actualCard.Assignees)
+	cards, err := wekan.SelectCardsFromMemberID(ctx, user.ID)
+	ass.NoError(err)
+	ass.Len(cards, 1)
+
+	ass.NoError(wekan.RemoveMemberFromCard(ctx, actualCard, user, user))
+	ass.NoError(wekan.RemoveAssigneeFromCard(ctx, actualCard, user, user))
+	actualCard, _ = wekan.GetCardFromID(ctx, card.ID)
+	ass.Empty(actualCard.Members)
+	ass.Empty(actualCard.Assignees)
+}
+
+func TestStorage_SelectConfig(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-test")
+	otherBoard, _, _ := createTestBoard(t, &wekan, "tableau-pascrp-test")
+
+	// WHEN
+	config, err := wekan.SelectConfig(ctx)
+
+	// THEN
+	ass.NoError(err)
+	ass.Len(config.Boards, 1)
+	configBoard, ok := config.Boards[board.ID]
+	ass.True(ok)
+	ass.Equal(board.Slug, configBoard.Board.Slug)
+	ass.Contains(configBoard.Swimlanes, swimlane.ID)
+	ass.Contains(configBoard.Lists, list.ID)
+	ass.NotContains(config.Boards, otherBoard.ID)
+	ass.Contains(config.Users, wekan.AdminID())
+}
+
+func TestStorage_SelectCardsFromCustomTextField(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board, swimlane, list := createTestBoard(t, &wekan, "tableau-crp-test")
+	customField := bson.M{"_id": "siretID", "name": "SIRET", "type": "text", "boardIds": bson.A{board.ID}}
+	ass.NoError(storage.Insert("customFields", customField))
+	card := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), wekan.AdminID())
+	card.CustomFields = []libwekan.CardCustomField{{ID: "siretID", Value: "12345678901234"}}
+	ass.NoError(wekan.InsertCard(ctx, card))
+	otherCard := libwekan.BuildCard(board.ID, list.ID, swimlane.ID, t.Name(), t.Name(), wekan.AdminID())
+	ass.NoError(wekan.InsertCard(ctx, otherCard))
+
+	// WHEN
+	cards, err := wekan.SelectCardsFromCustomTextField(ctx, "SIRET", "12345678901234")
+
+	// THEN
+	ass.NoError(err)
+	ass.Len(cards, 1)
+	ass.Equal(card.ID, cards[0].ID)
+}
+
+func TestStorage_Rules(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	_, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	ass.NoError(err)
+	board, _ = wekan.GetBoardFromID(ctx, board.ID)
+
+	// WHEN
+	created, err := wekan.EnsureRuleAddTaskforceMemberExists(ctx, user, board, board.Labels[0])
+	ass.NoError(err)
+	ass.True(created)
+	created, err = wekan.EnsureRuleAddTaskforceMemberExists(ctx, user, board, board.Labels[0])
+	ass.NoError(err)
+	ass.False(created)
+
+	// THEN
+	rules, err := wekan.SelectRulesFromBoardID(ctx, board.ID)
+	ass.NoError(err)
+	ass.Len(rules, 1)
+	ass.Equal(user.Username, rules[0].Action.Username)
+	ass.Equal(board.Labels[0].ID, rules[0].Trigger.LabelID)
+	ass.NoError(wekan.RemoveRuleWithID(ctx, rules[0].ID))
+	rules, err = wekan.SelectRulesFromBoardID(ctx, board.ID)
+	ass.NoError(err)
+	ass.Empty(rules)
+}
+
+func TestStorage_DisableUser(t *testing.T) {
+	ass := assert.New(t)
+	wekan := New("signaux.faibles", "^tableau-crp.*")
+	board, _, _ := createTestBoard(t, &wekan, "tableau-crp-test")
+	user := createTestUser(t, &wekan, "")
+	_, err := wekan.EnsureUserIsActiveBoardMember(ctx, board.ID, user.ID)
+	ass.NoError(err)
+
+	// WHEN
+	err = wekan.DisableUser(ctx, user)
+
+	// THEN
+	ass.NoError(err)
+	actualUser, _ := wekan.GetUserFromID(ctx, user.ID)
+	ass.True(actualUser.LoginDisabled)
+	boards, err := wekan.SelectBoardsFromMemberID(ctx, user.ID)
+	ass.NoError(err)
+	ass.Len(boards, 2)
+	for _, board := range boards {
+		ass.False(board.UserIsActiveMember(user))
+	}
+}
+
+func TestStorage_InsertOne_withDuplicateID(t *testing.T) {
+	storage := NewStorage()
+	board := libwekan.BuildBoard(t.Name(), t.Name(), "board")
+	assert.NoError(t, storage.Insert("boards", board))
+	assert.Error(t, storage.Insert("boards", board))
+}
+
+func TestStorage_InsertUser_isRolledBackOnFailure(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	// GIVEN
+	user := libwekan.BuildUser(t.Name(), t.Name(), t.Name())
+	conflictingUser := libwekan.BuildUser(t.Name()+"conflict", t.Name(), t.Name())
+	conflictingUser.ID = user.ID
+	ass.NoError(storage.Insert("users", conflictingUser))
+
+	// WHEN
+	err := wekan.InsertUser(ctx, user)
+
+	// THEN
+	ass.IsType(libwekan.UnexpectedMongoError{}, err)
+	ass.Equal(0, storage.Count("swimlanes"))
+	ass.Equal(0, storage.Count("boards"))
+	ass.Equal(0, storage.Count("activities"))
+	ass.Equal(2, storage.Count("users"))
+}
+
+func TestStorage_WithTransaction_nested(t *testing.T) {
+	ass := assert.New(t)
+	wekan, storage := NewWithStorage("signaux.faibles", "^tableau-crp.*")
+	board := libwekan.BuildBoard(t.Name(), t.Name(), "board")
+
+	// WHEN
+	err := wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := wekan.InsertBoard(ctx, board); err != nil {
+			return err
+		}
+		return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+			return wekan.InsertList(ctx, libwekan.BuildList(board.ID, t.Name(), 0))
+		})
+	})
+
+	// THEN
+	ass.NoError(err)
+	ass.Equal(1, storage.Count("boards"))
+	ass.Equal(1, storage.Count("lists"))
+}
+
+func TestStorage_UpdateManyAndDeleteMany(t *testing.T) {
+	ass := assert.New(t)
+	storage := NewStorage()
+	require.NoError(t, storage.Insert("lists",
+		bson.M{"_id": "a", "boardId": "board", "title": "a"},
+		bson.M{"_id": "b", "boardId": "board", "title": "b"},
+		bson.M{"_id": "c", "boardId": "other", "title": "c"},
+	))
+	collection := storage.Collection("lists")
+
+	updated, err := collection.UpdateMany(ctx, bson.M{"boardId": "board"}, bson.M{"$set": bson.M{"archived": true}})
+	require.NoError(t, err)
+	ass.Equal(int64(2), updated.MatchedCount)
+	ass.Equal(int64(2), updated.ModifiedCount)
+	updated, err = collection.UpdateOne(ctx, bson.M{"boardId": "board"}, bson.M{"$set": bson.M{"archived": true}})
+	require.NoError(t, err)
+	ass.Equal(int64(1), updated.MatchedCount)
+	ass.Equal(int64(0), updated.ModifiedCount)
+
+	deleted, err := collection.DeleteMany(ctx, bson.M{"archived": true})
+	require.NoError(t, err)
+	ass.Equal(int64(2), deleted.DeletedCount)
+	ass.Equal(1, storage.Count("lists"))
+	deleted, err = collection.DeleteOne(ctx, bson.M{"archived": true})
+	require.NoError(t, err)
+	ass.Equal(int64(0), deleted.DeletedCount)
+}
@@ -0,0 +1,241 @@
+package libwekantest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fieldOperation calcule la nouvelle valeur d'un champ, remove indique que le champ doit être supprimé
+type fieldOperation func(current interface{}, exists bool) (value interface{}, remove bool, err error)
+
+// applyUpdate applique les opérateurs de mise à jour sur une copie du document
+func applyUpdate(document bson.M, update bson.M, arrayFilters []bson.M) (bson.M, error) {
+	filters := indexArrayFilters(arrayFilters)
+	updated := deepCopy(document).(bson.M)
+	for operator, argument := range update {
+		fields, ok := argument.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("%s attend un document", operator)
+		}
+		for path, value := range fields {
+			if path == "_id" && operator != "$setOnInsert" {
+				return nil, fmt.Errorf("le champ _id ne peut pas être modifié")
+			}
+			operation, create, err := newFieldOperation(operator, value)
+			if err != nil {
+				return nil, err
+			}
+			if err := updatePath(updated, strings.Split(path, "."), filters, create, operation); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return updated, nil
+}
+
+// indexArrayFilters regroupe les conditions des arrayFilters par identifiant (`member.userId` => member)
+func indexArrayFilters(arrayFilters []bson.M) map[string]bson.M {
+	filters := make(map[string]bson.M)
+	for _, arrayFilter := range arrayFilters {
+		for key, condition := range arrayFilter {
+			identifier, subPath, _ := strings.Cut(key, ".")
+			if filters[identifier] == nil {
+				filters[identifier] = bson.M{}
+			}
+			filters[identifier][subPath] = condition
+		}
+	}
+	return filters
+}
+
+func matchArrayFilter(element interface{}, filter bson.M) (bool, error) {
+	for subPath, condition := range filter {
+		values := []interface{}{element}
+		if subPath != "" {
+			values = resolvePath(element, strings.Split(subPath, "."))
+		}
+		ok, err := matchValues(values, condition)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func newFieldOperation(operator string, argument interface{}) (fieldOperation, bool, error) {
+	switch operator {
+	case "$set", "$setOnInsert":
+		return func(interface{}, bool) (interface{}, bool, error) {
+			return deepCopy(argument), false, nil
+		}, true, nil
+	case "$unset":
+		return func(interface{}, bool) (interface{}, bool, error) {
+			return nil, true, nil
+		}, false, nil
+	case "$currentDate":
+		return func(interface{}, bool) (interface{}, bool, error) {
+			return primitive.NewDateTimeFromTime(time.Now()), false, nil
+		}, true, nil
+	case "$inc":
+		increment, ok := toFloat(argument)
+		if !ok {
+			return nil, false, fmt.Errorf("$inc attend une valeur numérique")
+		}
+		return func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists {
+				return argument, false, nil
+			}
+			value, ok := toFloat(current)
+			if !ok {
+				return nil, false, fmt.Errorf("$inc ne s'applique qu'aux valeurs numériques")
+			}
+			return addNumbers(current, argument, value+increment), false, nil
+		}, true, nil
+	case "$push", "$addToSet":
+		elements := bson.A{argument}
+		if modifiers, ok := argument.(bson.M); ok {
+			if each, ok := modifiers["$each"].(bson.A); ok {
+				elements = each
+			}
+		}
+		return func(current interface{}, exists bool) (interface{}, bool, error) {
+			array, ok := current.(bson.A)
+			if exists && current != nil && !ok {
+				return nil, false, fmt.Errorf("%s ne s'applique qu'aux tableaux", operator)
+			}
+			for _, element := range elements {
+				if operator == "$addToSet" && matchEquality(array, element) {
+					continue
+				}
+				array = append(array, deepCopy(element))
+			}
+			if array == nil {
+				array = bson.A{}
+			}
+			return array, false, nil
+		}, true, nil
+	case "$pull":
+		return func(current interface{}, exists bool) (interface{}, bool, error) {
+			array, ok := current.(bson.A)
+			if !ok {
+				return current, !exists, nil
+			}
+			kept := bson.A{}
+			for _, element := range array {
+				pulled, err := matchPull(element, argument)
+				if err != nil {
+					return nil, false, err
+				}
+				if !pulled {
+					kept = append(kept, element)
+				}
+			}
+			return kept, false, nil
+		}, false, nil
+	}
+	return nil, false, fmt.Errorf("opérateur de mise à jour non supporté : %s", operator)
+}
+
+func matchPull(element interface{}, condition interface{}) (bool, error) {
+	if filter, ok := condition.(bson.M); ok {
+		return matchElement(element, filter)
+	}
+	return equalValues(element, condition), nil
+}
+
+func addNumbers(a interface{}, b interface{}, sum float64) interface{} {
+	_, aFloat := a.(float64)
+	_, bFloat := b.(float64)
+	if aFloat || bFloat {
+		return sum
+	}
+	return int64(sum)
+}
+
+// updatePath applique l'opération au chemin donné, en gérant les opérateurs positionnels `$[]` et `$[identifiant]`
+func updatePath(container interface{}, keys []string, filters map[string]bson.M, create bool, operation fieldOperation) error {
+	key := keys[0]
+	switch parent := container.(type) {
+	case bson.M:
+		child, exists := parent[key]
+		if len(keys) == 1 {
+			value, remove, err := operation(child, exists)
+			if err != nil {
+				return err
+			}
+			if remove {
+				delete(parent, key)
+			} else if exists || create {
+				parent[key] = value
+			}
+			return nil
+		}
+		if !exists || child == nil {
+			if !create {
+				return nil
+			}
+			child = bson.M{}
+			parent[key] = child
+		}
+		return updatePath(child, keys[1:], filters, create, operation)
+	case bson.A:
+		indexes, err := arrayIndexes(parent, key, filters)
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if len(keys) == 1 {
+				value, _, err := operation(parent[index], true)
+				if err != nil {
+					return err
+				}
+				parent[index] = value
+				continue
+			}
+			if err := updatePath(parent[index], keys[1:], filters, create, operation); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("impossible de modifier le champ %s d'une valeur scalaire", key)
+}
+
+func arrayIndexes(array bson.A, key string, filters map[string]bson.M) ([]int, error) {
+	var indexes []int
+	switch {
+	case key == "$[]":
+		for i := range array {
+			indexes = append(indexes, i)
+		}
+	case strings.HasPrefix(key, "$[") && strings.HasSuffix(key, "]"):
+		identifier := key[2 : len(key)-1]
+		filter, ok := filters[identifier]
+		if !ok {
+			return nil, fmt.Errorf("aucun arrayFilter ne correspond à l'identifiant %s", identifier)
+		}
+		for i, element := range array {
+			matched, err := matchArrayFilter(element, filter)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				indexes = append(indexes, i)
+			}
+		}
+	default:
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("opérateur positionnel non supporté : %s", key)
+		}
+		if index >= 0 && index < len(array) {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes, nil
+}
@@ -0,0 +1,80 @@
+package libwekantest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/signaux-faibles/libwekan"
+)
+
+// changeStream reçoit une notification par écriture effective sur l'une des collections surveillées,
+// les notifications non consommées sont fusionnées
+type changeStream struct {
+	storage     *Storage
+	collections map[string]bool
+	events      chan struct{}
+	closeOnce   sync.Once
+	closed      chan struct{}
+	err         error
+}
+
+// Watch retourne un flux notifié à chaque insertion, modification ou suppression dans l'une des collections
+func (storage *Storage) Watch(ctx context.Context, collections []string) (libwekan.ChangeStream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	stream := &changeStream{
+		storage:     storage,
+		collections: make(map[string]bool, len(collections)),
+		events:      make(chan struct{}, 1),
+		closed:      make(chan struct{}),
+	}
+	for _, name := range collections {
+		stream.collections[name] = true
+	}
+	storage.watchMu.Lock()
+	defer storage.watchMu.Unlock()
+	storage.watchers[stream] = struct{}{}
+	return stream, nil
+}
+
+// notify signale une modification de la collection aux flux concernés, "" concerne tous les flux
+func (storage *Storage) notify(collectionName string) {
+	storage.watchMu.Lock()
+	defer storage.watchMu.Unlock()
+	for stream := range storage.watchers {
+		if collectionName != "" && !stream.collections[collectionName] {
+			continue
+		}
+		select {
+		case stream.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (stream *changeStream) Next(ctx context.Context) bool {
+	select {
+	case <-stream.events:
+		return true
+	case <-stream.closed:
+		return false
+	case <-ctx.Done():
+		stream.err = ctx.Err()
+		return false
+	}
+}
+
+func (stream *changeStream) Err() error {
+	return stream.err
+}
+
+func (stream *changeStream) Close(context.Context) error {
+	stream.closeOnce.Do(func() {
+		stream.storage.watchMu.Lock()
+		delete(stream.storage.watchers, stream)
+		stream.storage.watchMu.Unlock()
+		close(stream.closed)
+	})
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // ListID porte bien son nom
@@ -55,7 +56,9 @@ func (listID ListID) GetDocument(ctx context.Context, wekan *Wekan) (List, error
 	return wekan.GetListFromID(ctx, listID)
 }
 
-func (wekan *Wekan) InsertList(ctx context.Context, list List) error {
+func (wekan *Wekan) InsertList(ctx context.Context, list List) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertList", Targets{"listID": string(list.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -63,24 +66,31 @@ func (wekan *Wekan) InsertList(ctx context.Context, list List) error {
 		return err
 	}
 
-	_, err := wekan.db.Collection("lists").InsertOne(ctx, list)
+	_, err = wekan.db.Collection("lists").InsertOne(ctx, list)
 	if err != nil {
 		return UnexpectedMongoError{err}
 	}
 	return nil
 }
 
-func (wekan *Wekan) GetListFromID(ctx context.Context, listID ListID) (List, error) {
+func (wekan *Wekan) GetListFromID(ctx context.Context, listID ListID) (_ List, err error) {
+	ctx, end := wekan.observe(ctx, "GetListFromID", Targets{"listID": string(listID)})
+	defer end(&err)
 	var list List
-	err := wekan.db.Collection("lists").FindOne(ctx, bson.M{"_id": listID}).Decode(&list)
+	err = wekan.db.Collection("lists").FindOne(ctx, bson.M{"_id": listID}).Decode(&list)
+	if err == mongo.ErrNoDocuments {
+		return List{}, ListNotFoundError{listID}
+	}
 	if err != nil {
 		return List{}, UnexpectedMongoError{err}
 	}
 	return list, nil
 }
 
-func (wekan *Wekan) SelectListsFromBoardID(ctx context.Context, boardID BoardID) ([]List, error) {
-	err := boardID.Check(ctx, wekan)
+func (wekan *Wekan) SelectListsFromBoardID(ctx context.Context, boardID BoardID) (_ []List, err error) {
+	ctx, end := wekan.observe(ctx, "SelectListsFromBoardID", Targets{"boardID": string(boardID)})
+	defer end(&err)
+	err = boardID.Check(ctx, wekan)
 	if err != nil {
 		return nil, err
 	}
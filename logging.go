@@ -0,0 +1,284 @@
+package libwekan
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Logger reçoit les traces des opérations effectuées sur la base, *slog.Logger satisfait cette interface
+type Logger interface {
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// LogLevels fixe les niveaux de trace des opérations réussies et des opérations en erreur
+type LogLevels struct {
+	Operation slog.Level
+	Error     slog.Level
+}
+
+// DefaultLogLevels trace les opérations en debug et les erreurs en error
+var DefaultLogLevels = LogLevels{
+	Operation: slog.LevelDebug,
+	Error:     slog.LevelError,
+}
+
+type loggingStorage struct {
+	storage Storage
+	logger  Logger
+	levels  LogLevels
+}
+
+type loggingCollection struct {
+	collection Collection
+	name       string
+	logger     Logger
+	levels     LogLevels
+}
+
+type loggingSingleResult struct {
+	result     SingleResult
+	collection loggingCollection
+	filter     interface{}
+	duration   time.Duration
+	ctx        context.Context
+}
+
+type loggingCursor struct {
+	cursor     Cursor
+	collection loggingCollection
+	operation  string
+}
+
+func newLoggingStorage(storage Storage, logger Logger, levels LogLevels) Storage {
+	if logger == nil {
+		return storage
+	}
+	return loggingStorage{storage, logger, levels}
+}
+
+func (storage loggingStorage) Collection(name string) Collection {
+	return loggingCollection{storage.storage.Collection(name), name, storage.logger, storage.levels}
+}
+
+func (storage loggingStorage) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := storage.storage.Ping(ctx)
+	logOperation(ctx, storage.logger, storage.levels, "ping", "", nil, time.Since(start), err)
+	return err
+}
+
+func (storage loggingStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	err := storage.storage.WithTransaction(ctx, fn)
+	logOperation(ctx, storage.logger, storage.levels, "transaction", "", nil, time.Since(start), err)
+	return err
+}
+
+func (storage loggingStorage) Watch(ctx context.Context, collections []string) (ChangeStream, error) {
+	start := time.Now()
+	stream, err := storage.storage.Watch(ctx, collections)
+	logOperation(ctx, storage.logger, storage.levels, "watch", strings.Join(collections, ","), nil, time.Since(start), err)
+	return stream, err
+}
+
+func (c loggingCollection) log(ctx context.Context, operation string, filter interface{}, duration time.Duration, err error, attrs ...slog.Attr) {
+	logOperation(ctx, c.logger, c.levels, operation, c.name, filter, duration, err, attrs...)
+}
+
+func (c loggingCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) SingleResult {
+	start := time.Now()
+	result := c.collection.FindOne(ctx, filter, opts...)
+	// le driver ne signale l'erreur qu'au décodage, la trace est donc émise par Decode
+	return loggingSingleResult{result, c, filter, time.Since(start), ctx}
+}
+
+func (c loggingCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Cursor, error) {
+	start := time.Now()
+	cur, err := c.collection.Find(ctx, filter, opts...)
+	c.log(ctx, "find", filter, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return loggingCursor{cur, c, "find"}, nil
+}
+
+func (c loggingCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Cursor, error) {
+	start := time.Now()
+	cur, err := c.collection.Aggregate(ctx, pipeline, opts...)
+	c.log(ctx, "aggregate", pipeline, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return loggingCursor{cur, c, "aggregate"}, nil
+}
+
+func (c loggingCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	start := time.Now()
+	result, err := c.collection.InsertOne(ctx, document, opts...)
+	c.log(ctx, "insertOne", nil, time.Since(start), err)
+	return result, err
+}
+
+func (c loggingCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	start := time.Now()
+	result, err := c.collection.UpdateOne(ctx, filter, update, opts...)
+	var attrs []slog.Attr
+	if result != nil {
+		attrs = append(attrs, slog.Int64("matched", result.MatchedCount), slog.Int64("modified", result.ModifiedCount))
+	}
+	c.log(ctx, "updateOne", filter, time.Since(start), err, attrs...)
+	return result, err
+}
+
+func (c loggingCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	start := time.Now()
+	result, err := c.collection.UpdateMany(ctx, filter, update, opts...)
+	var attrs []slog.Attr
+	if result != nil {
+		attrs = append(attrs, slog.Int64("matched", result.MatchedCount), slog.Int64("modified", result.ModifiedCount))
+	}
+	c.log(ctx, "updateMany", filter, time.Since(start), err, attrs...)
+	return result, err
+}
+
+func (c loggingCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	start := time.Now()
+	result, err := c.collection.DeleteOne(ctx, filter, opts...)
+	var attrs []slog.Attr
+	if result != nil {
+		attrs = append(attrs, slog.Int64("deleted", result.DeletedCount))
+	}
+	c.log(ctx, "deleteOne", filter, time.Since(start), err, attrs...)
+	return result, err
+}
+
+func (c loggingCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	start := time.Now()
+	result, err := c.collection.DeleteMany(ctx, filter, opts...)
+	var attrs []slog.Attr
+	if result != nil {
+		attrs = append(attrs, slog.Int64("deleted", result.DeletedCount))
+	}
+	c.log(ctx, "deleteMany", filter, time.Since(start), err, attrs...)
+	return result, err
+}
+
+func (c loggingCollection) ListIndexes(ctx context.Context) (Cursor, error) {
+	start := time.Now()
+	cur, err := c.collection.ListIndexes(ctx)
+	c.log(ctx, "listIndexes", nil, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return loggingCursor{cur, c, "listIndexes"}, nil
+}
+
+func (c loggingCollection) CreateIndex(ctx context.Context, model mongo.IndexModel) (string, error) {
+	start := time.Now()
+	name, err := c.collection.CreateIndex(ctx, model)
+	c.log(ctx, "createIndex", model.Keys, time.Since(start), err)
+	return name, err
+}
+
+func (r loggingSingleResult) Decode(v interface{}) error {
+	err := r.result.Decode(v)
+	if err == mongo.ErrNoDocuments {
+		// l'absence de document est un résultat normal, l'appelant décide s'il s'agit d'une erreur
+		r.collection.log(r.ctx, "findOne", r.filter, r.duration, nil, slog.Bool("found", false))
+		return err
+	}
+	r.collection.log(r.ctx, "findOne", r.filter, r.duration, err)
+	return err
+}
+
+func (c loggingCursor) Next(ctx context.Context) bool {
+	return c.cursor.Next(ctx)
+}
+
+func (c loggingCursor) Decode(val interface{}) error {
+	err := c.cursor.Decode(val)
+	if err != nil {
+		c.collection.log(context.Background(), c.operation+".decode", nil, 0, err)
+	}
+	return err
+}
+
+func (c loggingCursor) All(ctx context.Context, results interface{}) error {
+	err := c.cursor.All(ctx, results)
+	if err != nil {
+		c.collection.log(ctx, c.operation+".decode", nil, 0, err)
+	}
+	return err
+}
+
+func (c loggingCursor) Close(ctx context.Context) error {
+	return c.cursor.Close(ctx)
+}
+
+func logOperation(ctx context.Context, logger Logger, levels LogLevels, operation string, collection string, filter interface{}, duration time.Duration, err error, attrs ...slog.Attr) {
+	level := levels.Operation
+	allAttrs := []slog.Attr{slog.String("operation", operation)}
+	if collection != "" {
+		allAttrs = append(allAttrs, slog.String("collection", collection))
+	}
+	if filter != nil {
+		allAttrs = append(allAttrs, slog.String("filter", summarizeFilter(filter)))
+	}
+	// le décodage d'un curseur n'est pas chronométré
+	if duration > 0 {
+		allAttrs = append(allAttrs, slog.Duration("duration", duration))
+	}
+	allAttrs = append(allAttrs, attrs...)
+	if err != nil {
+		level = levels.Error
+		allAttrs = append(allAttrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, "libwekan", allAttrs...)
+}
+
+// summarizeFilter résume un filtre ou un pipeline par ses seules clés, les valeurs pouvant contenir des données personnelles
+func summarizeFilter(filter interface{}) string {
+	switch f := filter.(type) {
+	case bson.M:
+		keys := make([]string, 0, len(f))
+		for key := range f {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ",")
+	case bson.D:
+		keys := make([]string, 0, len(f))
+		for _, element := range f {
+			keys = append(keys, element.Key)
+		}
+		return strings.Join(keys, ",")
+	case Pipeline:
+		return summarizeStages(f)
+	case bson.A:
+		return summarizeStages(f)
+	case []interface{}:
+		return summarizeStages(f)
+	case []bson.M:
+		stages := make([]interface{}, 0, len(f))
+		for _, stage := range f {
+			stages = append(stages, stage)
+		}
+		return summarizeStages(stages)
+	}
+	return ""
+}
+
+func summarizeStages(stages []interface{}) string {
+	summaries := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		summaries = append(summaries, summarizeFilter(stage))
+	}
+	return strings.Join(summaries, "|")
+}
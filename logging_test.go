@@ -0,0 +1,43 @@
+package libwekan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLogging_summarizeFilter(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal("_id,slug", summarizeFilter(bson.M{"slug": "secret", "_id": "boardID"}))
+	ass.Equal("$match|$lookup", summarizeFilter(Pipeline{bson.M{"$match": bson.M{}}, bson.M{"$lookup": bson.M{}}}))
+	ass.Equal("", summarizeFilter("unknown"))
+}
+
+func TestLogging_logOperation(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	levels := LogLevels{Operation: slog.LevelInfo, Error: slog.LevelWarn}
+
+	// WHEN
+	logOperation(context.Background(), logger, levels, "findOne", "boards", bson.M{"_id": "secret"}, time.Millisecond, nil)
+	logOperation(context.Background(), logger, levels, "updateOne", "cards", nil, time.Millisecond, errors.New("boom"))
+
+	// THEN
+	output := buffer.String()
+	ass.Contains(output, "level=INFO msg=libwekan operation=findOne collection=boards filter=_id duration=1ms")
+	ass.Contains(output, "level=WARN msg=libwekan operation=updateOne collection=cards duration=1ms error=boom")
+	ass.NotContains(output, "secret")
+}
+
+func TestLogging_newLoggingStorage_withoutLogger(t *testing.T) {
+	storage := mongoStorage{}
+	assert.Equal(t, Storage(storage), newLoggingStorage(storage, nil, DefaultLogLevels))
+}
@@ -0,0 +1,73 @@
+package libwekan
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Targets identifie les documents visés par une opération (`boardID`, `cardID`, `userID`, …)
+type Targets map[string]string
+
+// Operation décrit un appel à une méthode publique de l'objet Wekan
+type Operation struct {
+	Name     string
+	Targets  Targets
+	Start    time.Time
+	Duration time.Duration
+	// Err et ErrorType ne sont renseignés qu'à la fin de l'opération, ErrorType est le nom du type de l'erreur d'origine
+	Err       error
+	ErrorType string
+}
+
+// Observer est notifié autour de chaque opération publique, par exemple pour alimenter des métriques ou des traces.
+// Les opérations composées (ex : EnsureUserIsActiveBoardMember) donnent lieu à des notifications imbriquées.
+type Observer interface {
+	// OperationStarted est appelée au début de l'opération, le contexte retourné est utilisé par l'opération
+	OperationStarted(ctx context.Context, operation Operation) context.Context
+	// OperationEnded est appelée à la fin de l'opération avec le contexte retourné par OperationStarted
+	OperationEnded(ctx context.Context, operation Operation)
+}
+
+// WithObserver notifie l'observer fourni autour de chaque opération publique
+func WithObserver(observer Observer) Option {
+	return func(o *initOptions) {
+		o.observer = observer
+	}
+}
+
+// observe signale le début d'une opération à l'observer,
+// la fonction retournée doit être différée avec l'adresse de l'erreur retournée par l'opération
+func (wekan *Wekan) observe(ctx context.Context, name string, targets Targets) (context.Context, func(*error)) {
+	if wekan.observer == nil {
+		return ctx, func(*error) {}
+	}
+	operation := Operation{
+		Name:    name,
+		Targets: targets,
+		Start:   time.Now(),
+	}
+	ctx = wekan.observer.OperationStarted(ctx, operation)
+	return ctx, func(err *error) {
+		operation.Duration = time.Since(operation.Start)
+		if err != nil && *err != nil {
+			operation.Err = *err
+			operation.ErrorType = errorType(*err)
+		}
+		wekan.observer.OperationEnded(ctx, operation)
+	}
+}
+
+func errorType(err error) string {
+	cause := errors.Cause(err)
+	t := reflect.TypeOf(cause)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
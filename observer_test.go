@@ -0,0 +1,64 @@
+package libwekan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey string
+
+type recordingObserver struct {
+	started []Operation
+	ended   []Operation
+	values  []interface{}
+}
+
+func (observer *recordingObserver) OperationStarted(ctx context.Context, operation Operation) context.Context {
+	observer.started = append(observer.started, operation)
+	return context.WithValue(ctx, contextKey("span"), operation.Name)
+}
+
+func (observer *recordingObserver) OperationEnded(ctx context.Context, operation Operation) {
+	observer.ended = append(observer.ended, operation)
+	observer.values = append(observer.values, ctx.Value(contextKey("span")))
+}
+
+func TestObserver_observe(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	observer := &recordingObserver{}
+	unitWekan := Wekan{observer: observer}
+	err := errors.Wrap(CardNotFoundError{"cardID"}, "contexte")
+
+	// WHEN
+	ctx, end := unitWekan.observe(context.Background(), "AddLabelToCard", Targets{"cardID": "cardID"})
+	end(&err)
+
+	// THEN
+	ass.Equal("AddLabelToCard", ctx.Value(contextKey("span")))
+	ass.Len(observer.started, 1)
+	ass.Len(observer.ended, 1)
+	ass.Equal(Targets{"cardID": "cardID"}, observer.ended[0].Targets)
+	ass.Equal("CardNotFoundError", observer.ended[0].ErrorType)
+	ass.Equal(err, observer.ended[0].Err)
+	ass.Equal("AddLabelToCard", observer.values[0])
+}
+
+func TestObserver_observe_withoutObserver(t *testing.T) {
+	ctx := context.Background()
+	unitWekan := Wekan{}
+	actualCtx, end := unitWekan.observe(ctx, "GetCardFromID", nil)
+	var err error
+	end(&err)
+	assert.Equal(t, ctx, actualCtx)
+}
+
+func TestObserver_errorType(t *testing.T) {
+	ass := assert.New(t)
+	ass.Equal("UnexpectedMongoError", errorType(UnexpectedMongoError{errors.New("boom")}))
+	ass.Equal("ForbiddenOperationError", errorType(&ForbiddenOperationError{}))
+	ass.Equal("fundamental", errorType(errors.New("boom")))
+}
@@ -0,0 +1,271 @@
+package libwekan
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// RetryableErrors désigne les classes d'erreurs mongodb pour lesquelles une opération est rejouée
+type RetryableErrors uint
+
+const (
+	// RetryNetworkErrors rejoue les opérations interrompues par une erreur réseau
+	RetryNetworkErrors RetryableErrors = 1 << iota
+	// RetryTimeouts rejoue les opérations ayant dépassé le délai du driver, jamais celles dont le contexte a expiré
+	RetryTimeouts
+	// RetryElections rejoue les opérations refusées pendant une élection de primaire
+	RetryElections
+)
+
+// codes d'erreurs mongodb renvoyés lorsque le serveur n'est pas (ou plus) primaire
+var electionErrorCodes = []int{91, 189, 10107, 11600, 11602, 13435, 13436}
+
+// RetryPolicy paramètre le rejeu des lectures et des opérations Ensure*, qui sont idempotentes.
+// Une opération Ensure* est exécutée et rejouée dans son ensemble au sein d'une transaction ;
+// sur un serveur standalone, une tentative n'est plus rejouée dès qu'une de ses écritures a abouti.
+// Les écritures simples (Insert*, Add*, …) ne sont jamais rejouées.
+type RetryPolicy struct {
+	// MaxAttempts est le nombre maximal de tentatives, une valeur inférieure à 2 désactive le rejeu
+	MaxAttempts int
+	// InitialBackoff est l'attente avant la deuxième tentative, elle double ensuite à chaque tentative
+	InitialBackoff time.Duration
+	// MaxBackoff plafonne l'attente entre deux tentatives
+	MaxBackoff time.Duration
+	Retryable  RetryableErrors
+}
+
+// DefaultRetryPolicy effectue jusqu'à 3 tentatives pour les erreurs réseau, les timeouts et les élections
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Retryable:      RetryNetworkErrors | RetryTimeouts | RetryElections,
+}
+
+// WithRetryPolicy active le rejeu des opérations idempotentes, aucun rejeu n'est effectué par défaut
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *initOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+type retryingKey struct{}
+
+// retryAttempt est transmis dans le contexte d'une tentative, written indique qu'une écriture a abouti hors transaction
+type retryAttempt struct {
+	written bool
+}
+
+func (policy RetryPolicy) enabled() bool {
+	return policy.MaxAttempts > 1
+}
+
+func (policy RetryPolicy) isRetryable(err error) bool {
+	if policy.Retryable&RetryNetworkErrors != 0 && mongo.IsNetworkError(err) {
+		return true
+	}
+	if policy.Retryable&RetryTimeouts != 0 && mongo.IsTimeout(err) && !errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if policy.Retryable&RetryElections != 0 {
+		var serverError mongo.ServerError
+		if errors.As(err, &serverError) {
+			for _, code := range electionErrorCodes {
+				if serverError.HasErrorCode(code) {
+					return true
+				}
+			}
+			if serverError.HasErrorLabel("RetryableWriteError") || serverError.HasErrorLabel("TransientTransactionError") {
+				return true
+			}
+		}
+		var selectionError topology.ServerSelectionError
+		if errors.As(err, &selectionError) {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := policy.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff >= policy.MaxBackoff {
+			return policy.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// run exécute fn jusqu'à son succès, une erreur non rejouable ou l'épuisement des tentatives,
+// dans ce dernier cas l'erreur est enveloppée dans une RetryExhaustedError portant le nombre de tentatives,
+// une erreur survenue après un premier rejeu est enveloppée dans une RetriedError.
+// Un appel imbriqué dans une opération déjà rejouée ou dans une transaction n'est pas rejoué,
+// pas plus qu'une tentative dont une écriture hors transaction a abouti.
+func (policy RetryPolicy) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !policy.enabled() || ctx.Value(retryingKey{}) != nil || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	for attempt := 1; ; attempt++ {
+		state := &retryAttempt{}
+		err := fn(context.WithValue(ctx, retryingKey{}, state))
+		if err == nil {
+			return nil
+		}
+		if !policy.isRetryable(err) || state.written {
+			if attempt > 1 {
+				return RetriedError{attempt, err}
+			}
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			return RetryExhaustedError{attempt, err}
+		}
+		select {
+		case <-ctx.Done():
+			return RetryExhaustedError{attempt, err}
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+}
+
+// retryEnsure exécute le corps d'une opération Ensure* dans une transaction rejouée dans son ensemble,
+// l'écriture d'un document et celle de l'activité correspondante aboutissent ou échouent ensemble
+func retryEnsure[T any](ctx context.Context, wekan *Wekan, fn func(ctx context.Context) (T, error)) (T, error) {
+	return retryValue(ctx, wekan.retryPolicy, func(ctx context.Context) (T, error) {
+		var value T
+		err := wekan.WithTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			value, err = fn(ctx)
+			return err
+		})
+		return value, err
+	})
+}
+
+// markWritten signale à la tentative en cours qu'une écriture a abouti hors transaction mongodb
+func markWritten(ctx context.Context, err error) {
+	if state, ok := ctx.Value(retryingKey{}).(*retryAttempt); ok && err == nil && mongo.SessionFromContext(ctx) == nil {
+		state.written = true
+	}
+}
+
+func retryValue[T any](ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := policy.run(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = fn(ctx)
+		return err
+	})
+	return value, err
+}
+
+// retryingStorage rejoue les lectures (FindOne, Find, Aggregate) selon la politique de rejeu
+type retryingStorage struct {
+	storage Storage
+	policy  RetryPolicy
+}
+
+type retryingCollection struct {
+	collection Collection
+	policy     RetryPolicy
+}
+
+type retryingSingleResult struct {
+	collection retryingCollection
+	ctx        context.Context
+	filter     interface{}
+	opts       []*options.FindOneOptions
+}
+
+func newRetryingStorage(storage Storage, policy RetryPolicy) Storage {
+	if !policy.enabled() {
+		return storage
+	}
+	return retryingStorage{storage, policy}
+}
+
+func (storage retryingStorage) Collection(name string) Collection {
+	return retryingCollection{storage.storage.Collection(name), storage.policy}
+}
+
+func (storage retryingStorage) Ping(ctx context.Context) error {
+	return storage.policy.run(ctx, storage.storage.Ping)
+}
+
+func (storage retryingStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return storage.storage.WithTransaction(ctx, fn)
+}
+
+func (storage retryingStorage) Watch(ctx context.Context, collections []string) (ChangeStream, error) {
+	return retryValue(ctx, storage.policy, func(ctx context.Context) (ChangeStream, error) {
+		return storage.storage.Watch(ctx, collections)
+	})
+}
+
+// FindOne est différé jusqu'au décodage, seul moment où l'erreur éventuelle est connue
+func (c retryingCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) SingleResult {
+	return retryingSingleResult{c, ctx, filter, opts}
+}
+
+func (c retryingCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Cursor, error) {
+	return retryValue(ctx, c.policy, func(ctx context.Context) (Cursor, error) {
+		return c.collection.Find(ctx, filter, opts...)
+	})
+}
+
+func (c retryingCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Cursor, error) {
+	return retryValue(ctx, c.policy, func(ctx context.Context) (Cursor, error) {
+		return c.collection.Aggregate(ctx, pipeline, opts...)
+	})
+}
+
+func (c retryingCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	result, err := c.collection.InsertOne(ctx, document, opts...)
+	markWritten(ctx, err)
+	return result, err
+}
+
+func (c retryingCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	result, err := c.collection.UpdateOne(ctx, filter, update, opts...)
+	markWritten(ctx, err)
+	return result, err
+}
+
+func (c retryingCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	result, err := c.collection.UpdateMany(ctx, filter, update, opts...)
+	markWritten(ctx, err)
+	return result, err
+}
+
+func (c retryingCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	result, err := c.collection.DeleteOne(ctx, filter, opts...)
+	markWritten(ctx, err)
+	return result, err
+}
+
+func (c retryingCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	result, err := c.collection.DeleteMany(ctx, filter, opts...)
+	markWritten(ctx, err)
+	return result, err
+}
+
+func (c retryingCollection) ListIndexes(ctx context.Context) (Cursor, error) {
+	return retryValue(ctx, c.policy, c.collection.ListIndexes)
+}
+
+func (c retryingCollection) CreateIndex(ctx context.Context, model mongo.IndexModel) (string, error) {
+	return c.collection.CreateIndex(ctx, model)
+}
+
+func (r retryingSingleResult) Decode(v interface{}) error {
+	return r.collection.policy.run(r.ctx, func(ctx context.Context) error {
+		return r.collection.collection.FindOne(ctx, r.filter, r.opts...).Decode(v)
+	})
+}
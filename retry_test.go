@@ -0,0 +1,130 @@
+package libwekan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+	Retryable:      RetryNetworkErrors | RetryElections,
+}
+
+func TestRetryPolicy_isRetryable(t *testing.T) {
+	ass := assert.New(t)
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+	electionError := mongo.CommandError{Code: 10107, Name: "NotWritablePrimary"}
+
+	ass.True(testRetryPolicy.isRetryable(networkError))
+	ass.True(testRetryPolicy.isRetryable(UnexpectedMongoError{electionError}))
+	ass.False(testRetryPolicy.isRetryable(mongo.ErrNoDocuments))
+	ass.False(testRetryPolicy.isRetryable(context.DeadlineExceeded))
+	ass.False(RetryPolicy{Retryable: RetryTimeouts}.isRetryable(networkError))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	ass := assert.New(t)
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	ass.Equal(100*time.Millisecond, policy.backoff(1))
+	ass.Equal(200*time.Millisecond, policy.backoff(2))
+	ass.Equal(300*time.Millisecond, policy.backoff(3))
+}
+
+func TestRetryPolicy_run_withExhaustedAttempts(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	attempts := 0
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+
+	// WHEN
+	err := testRetryPolicy.run(context.Background(), func(context.Context) error {
+		attempts++
+		return UnexpectedMongoError{networkError}
+	})
+
+	// THEN
+	ass.Equal(3, attempts)
+	var retryError RetryExhaustedError
+	ass.True(errors.As(err, &retryError))
+	ass.Equal(3, retryError.Attempts)
+	ass.Contains(err.Error(), "échec après 3 tentatives")
+	ass.True(errors.As(err, &UnexpectedMongoError{}))
+}
+
+func TestRetryPolicy_run_withNonRetryableError(t *testing.T) {
+	ass := assert.New(t)
+	attempts := 0
+	err := testRetryPolicy.run(context.Background(), func(context.Context) error {
+		attempts++
+		return CardNotFoundError{"cardID"}
+	})
+	ass.Equal(1, attempts)
+	ass.IsType(CardNotFoundError{}, err)
+}
+
+func TestRetryPolicy_run_isNotNested(t *testing.T) {
+	ass := assert.New(t)
+	attempts := 0
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+	err := testRetryPolicy.run(context.Background(), func(ctx context.Context) error {
+		return testRetryPolicy.run(ctx, func(context.Context) error {
+			attempts++
+			return networkError
+		})
+	})
+	ass.Equal(3, attempts)
+	ass.IsType(RetryExhaustedError{}, err)
+}
+
+func TestRetryPolicy_run_stopsAfterWriteOutsideTransaction(t *testing.T) {
+	ass := assert.New(t)
+	attempts := 0
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+	err := testRetryPolicy.run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		markWritten(ctx, nil)
+		return networkError
+	})
+	ass.Equal(1, attempts)
+	ass.Equal(networkError, err)
+}
+
+func TestRetryPolicy_run_keepsAttemptsOfNonRetryableError(t *testing.T) {
+	ass := assert.New(t)
+	attempts := 0
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+	err := testRetryPolicy.run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return networkError
+		}
+		return CardNotFoundError{"cardID"}
+	})
+	ass.Equal(2, attempts)
+	ass.Equal(RetriedError{2, CardNotFoundError{"cardID"}}, err)
+	ass.ErrorIs(err, ErrNotFound)
+	ass.Equal(CodeCardNotFound, ErrorCodeOf(err))
+	ass.Contains(err.Error(), "échec à la tentative 2")
+}
+
+func TestRetryPolicy_run_keepsAttemptsAfterWrite(t *testing.T) {
+	ass := assert.New(t)
+	attempts := 0
+	networkError := mongo.CommandError{Labels: []string{"NetworkError"}}
+	err := testRetryPolicy.run(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if attempts == 2 {
+			markWritten(ctx, nil)
+		}
+		return networkError
+	})
+	ass.Equal(2, attempts)
+	ass.Equal(RetriedError{2, networkError}, err)
+}
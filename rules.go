@@ -176,7 +176,9 @@ func (rules Rules) SelectAddMemberToTaskforceRule() Rules {
 	})
 }
 
-func (wekan *Wekan) InsertRule(ctx context.Context, rule Rule) error {
+func (wekan *Wekan) InsertRule(ctx context.Context, rule Rule) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertRule", Targets{"ruleID": string(rule.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -184,48 +186,51 @@ func (wekan *Wekan) InsertRule(ctx context.Context, rule Rule) error {
 	if rule == (Rule{}) {
 		return InsertEmptyRuleError{}
 	}
-	err := wekan.InsertAction(ctx, rule.Action)
-	if err != nil {
-		return err
-	}
-
-	err = wekan.InsertTrigger(ctx, rule.Trigger)
-	if err != nil {
-		return err
-	}
-
-	_, err = wekan.db.Collection("rules").InsertOne(ctx, rule)
-	if err != nil {
-		return UnexpectedMongoError{err}
-	}
-	return nil
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := wekan.InsertAction(ctx, rule.Action); err != nil {
+			return err
+		}
+		if err := wekan.InsertTrigger(ctx, rule.Trigger); err != nil {
+			return err
+		}
+		if _, err := wekan.db.Collection("rules").InsertOne(ctx, rule); err != nil {
+			return UnexpectedMongoError{err}
+		}
+		return nil
+	})
 }
 
-func (wekan *Wekan) InsertAction(ctx context.Context, action Action) error {
+func (wekan *Wekan) InsertAction(ctx context.Context, action Action) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertAction", Targets{"actionID": string(action.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
 
-	_, err := wekan.db.Collection("actions").InsertOne(ctx, action)
+	_, err = wekan.db.Collection("actions").InsertOne(ctx, action)
 	if err != nil {
 		return UnexpectedMongoError{err}
 	}
 	return nil
 }
 
-func (wekan *Wekan) InsertTrigger(ctx context.Context, trigger Trigger) error {
+func (wekan *Wekan) InsertTrigger(ctx context.Context, trigger Trigger) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertTrigger", Targets{"triggerID": string(trigger.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
 
-	_, err := wekan.db.Collection("triggers").InsertOne(ctx, trigger)
+	_, err = wekan.db.Collection("triggers").InsertOne(ctx, trigger)
 	if err != nil {
 		return UnexpectedMongoError{err}
 	}
 	return nil
 }
 
-func (wekan *Wekan) SelectRulesFromBoardID(ctx context.Context, boardID BoardID) (Rules, error) {
+func (wekan *Wekan) SelectRulesFromBoardID(ctx context.Context, boardID BoardID) (_ Rules, err error) {
+	ctx, end := wekan.observe(ctx, "SelectRulesFromBoardID", Targets{"boardID": string(boardID)})
+	defer end(&err)
 	var malformedRules, rules Rules
 	cur, err := wekan.db.Collection("rules").Find(ctx, bson.M{"boardId": boardID})
 	if err != nil {
@@ -244,9 +249,11 @@ func (wekan *Wekan) SelectRulesFromBoardID(ctx context.Context, boardID BoardID)
 	return rules, nil
 }
 
-func (wekan *Wekan) SelectRuleFromID(ctx context.Context, ruleID RuleID) (Rule, error) {
+func (wekan *Wekan) SelectRuleFromID(ctx context.Context, ruleID RuleID) (_ Rule, err error) {
+	ctx, end := wekan.observe(ctx, "SelectRuleFromID", Targets{"ruleID": string(ruleID)})
+	defer end(&err)
 	var rule Rule
-	err := wekan.db.Collection("rules").FindOne(ctx, bson.M{"_id": ruleID}).Decode(&rule)
+	err = wekan.db.Collection("rules").FindOne(ctx, bson.M{"_id": ruleID}).Decode(&rule)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return Rule{}, RuleNotFoundError{ruleID}
@@ -272,7 +279,9 @@ func (wekan *Wekan) SelectRuleFromID(ctx context.Context, ruleID RuleID) (Rule,
 	return rule, nil
 }
 
-func (wekan *Wekan) RemoveRuleWithID(ctx context.Context, ruleID RuleID) error {
+func (wekan *Wekan) RemoveRuleWithID(ctx context.Context, ruleID RuleID) (err error) {
+	ctx, end := wekan.observe(ctx, "RemoveRuleWithID", Targets{"ruleID": string(ruleID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -295,7 +304,15 @@ func (wekan *Wekan) RemoveRuleWithID(ctx context.Context, ruleID RuleID) error {
 	return nil
 }
 
-func (wekan *Wekan) EnsureRuleAddTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (bool, error) {
+func (wekan *Wekan) EnsureRuleAddTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureRuleAddTaskforceMemberExists", Targets{"userID": string(user.ID), "boardID": string(board.ID), "boardLabelID": string(boardLabel.ID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureRuleAddTaskforceMemberExists(ctx, user, board, boardLabel)
+	})
+}
+
+func (wekan *Wekan) ensureRuleAddTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (bool, error) {
 	boardRules, err := wekan.SelectRulesFromBoardID(ctx, board.ID)
 	if err != nil {
 		return false, err
@@ -309,7 +326,15 @@ func (wekan *Wekan) EnsureRuleAddTaskforceMemberExists(ctx context.Context, user
 	return false, nil
 }
 
-func (wekan *Wekan) EnsureRuleRemoveTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (bool, error) {
+func (wekan *Wekan) EnsureRuleRemoveTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureRuleRemoveTaskforceMemberExists", Targets{"userID": string(user.ID), "boardID": string(board.ID), "boardLabelID": string(boardLabel.ID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureRuleRemoveTaskforceMemberExists(ctx, user, board, boardLabel)
+	})
+}
+
+func (wekan *Wekan) ensureRuleRemoveTaskforceMemberExists(ctx context.Context, user User, board Board, boardLabel BoardLabel) (bool, error) {
 	boardRules, err := wekan.SelectRulesFromBoardID(ctx, board.ID)
 	if err != nil {
 		return false, err
@@ -0,0 +1,157 @@
+package libwekan
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SchemaVersion identifie une génération du modèle de données de Wekan.
+// Wekan ne stocke pas son numéro de version, la génération est déduite des migrations appliquées.
+type SchemaVersion int
+
+const (
+	SchemaUnknown SchemaVersion = iota
+	// SchemaV3 : templates et assignees (Wekan 3.x à 5.x), pièces jointes dans CollectionFS
+	SchemaV3
+	// SchemaV6 : pièces jointes migrées vers ostrio-files (Wekan 6.x et suivantes)
+	SchemaV6
+)
+
+func (version SchemaVersion) String() string {
+	switch version {
+	case SchemaV3:
+		return "v3"
+	case SchemaV6:
+		return "v6"
+	}
+	return "inconnue"
+}
+
+// migrations indispensables au fonctionnement de libwekan
+var requiredMigrations = []string{
+	"add-swimlanes",
+	"mutate-boardIds-in-customfields",
+	"add-templates",
+	"add-assignee",
+}
+
+type meteorMigration struct {
+	Name string `bson:"name"`
+}
+
+const ostrioFilesMigration = "migrate-attachments-collectionFS-to-ostrioFiles"
+
+// SchemaMapping indique les collections et champs à utiliser pour une génération de schéma.
+// Seuls les commentaires et les pièces jointes diffèrent entre les générations supportées : les autres champs,
+// dont `lists.swimlaneId` et les dates des cartes, ont le même nom dans toutes ces générations
+// et un document qui ne les porte pas encore est décodé avec leur valeur zéro.
+type SchemaMapping struct {
+	CommentsCollection    string
+	AttachmentsCollection string
+	// AttachmentsBoardField est le champ des pièces jointes qui contient l'identifiant de la board
+	AttachmentsBoardField string
+}
+
+// Schema décrit le modèle de données détecté dans la base
+type Schema struct {
+	Version    SchemaVersion
+	Migrations []string
+	Mapping    SchemaMapping
+}
+
+var schemaMappings = map[SchemaVersion]SchemaMapping{
+	SchemaV3: {
+		CommentsCollection:    "card_comments",
+		AttachmentsCollection: "cfs.attachments.filerecord",
+		AttachmentsBoardField: "boardId",
+	},
+	SchemaV6: {
+		CommentsCollection:    "card_comments",
+		AttachmentsCollection: "attachments",
+		AttachmentsBoardField: "meta.boardId",
+	},
+}
+
+// defaultSchemaMapping est la correspondance de la génération la plus récente, utilisée tant que DetectSchema n'a pas été appelée
+var defaultSchemaMapping = schemaMappings[SchemaV6]
+
+// DetectSchema identifie la génération du modèle de données à partir des collections `meteor-migrations` et `settings`,
+// la correspondance des champs de la génération détectée est ensuite utilisée par l'objet Wekan.
+// Une UnsupportedSchemaError est retournée lorsque la base n'est pas une base Wekan ou que sa version n'est pas supportée.
+func (wekan *Wekan) DetectSchema(ctx context.Context) (_ Schema, err error) {
+	ctx, end := wekan.observe(ctx, "DetectSchema", nil)
+	defer end(&err)
+	schema, err := wekan.detectSchema(ctx)
+	if err != nil {
+		return Schema{}, err
+	}
+	wekan.schema = &schema
+	return schema, nil
+}
+
+func (wekan *Wekan) detectSchema(ctx context.Context) (Schema, error) {
+	migrations, err := wekan.selectMigrationNames(ctx)
+	if err != nil {
+		return Schema{}, err
+	}
+	if len(migrations) == 0 {
+		var settings bson.M
+		err := wekan.db.Collection("settings").FindOne(ctx, bson.M{}).Decode(&settings)
+		if err == mongo.ErrNoDocuments {
+			return Schema{}, UnsupportedSchemaError{Reason: "aucune migration ni paramétrage, la base n'est pas une base Wekan"}
+		}
+		if err != nil {
+			return Schema{}, UnexpectedMongoError{err}
+		}
+		return Schema{}, UnsupportedSchemaError{Reason: "aucune migration enregistrée, la version de Wekan ne peut pas être déterminée"}
+	}
+	return schemaFromMigrations(migrations)
+}
+
+func (wekan *Wekan) selectMigrationNames(ctx context.Context) ([]string, error) {
+	cur, err := wekan.db.Collection("meteor-migrations").Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return nil, UnexpectedMongoError{err}
+	}
+	var migrations []meteorMigration
+	if err := cur.All(ctx, &migrations); err != nil {
+		return nil, UnexpectedMongoDecodeError{err}
+	}
+	return mapSlice(migrations, func(migration meteorMigration) string { return migration.Name }), nil
+}
+
+func schemaFromMigrations(migrations []string) (Schema, error) {
+	applied := make(map[string]bool)
+	for _, migration := range migrations {
+		applied[migration] = true
+	}
+	var missing []string
+	for _, migration := range requiredMigrations {
+		if !applied[migration] {
+			missing = append(missing, migration)
+		}
+	}
+	if len(missing) > 0 {
+		return Schema{}, UnsupportedSchemaError{Reason: "migrations manquantes", MissingMigrations: missing}
+	}
+	version := SchemaV3
+	if applied[ostrioFilesMigration] {
+		version = SchemaV6
+	}
+	return Schema{
+		Version:    version,
+		Migrations: migrations,
+		Mapping:    schemaMappings[version],
+	}, nil
+}
+
+// schemaMapping retourne la correspondance des champs du schéma détecté, ou la correspondance historique
+func (wekan *Wekan) schemaMapping() SchemaMapping {
+	if wekan.schema == nil {
+		return defaultSchemaMapping
+	}
+	return wekan.schema.Mapping
+}
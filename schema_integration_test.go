@@ -0,0 +1,22 @@
+//go:build integration
+
+// nolint:errcheck
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_DetectSchema(t *testing.T) {
+	ass := assert.New(t)
+	schemaWekan := wekan
+
+	schema, err := schemaWekan.DetectSchema(ctx)
+
+	ass.NoError(err)
+	ass.Equal(SchemaV6, schema.Version)
+	ass.Contains(schema.Migrations, "add-templates")
+	ass.Equal("card_comments", schemaWekan.schemaMapping().CommentsCollection)
+}
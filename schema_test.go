@@ -0,0 +1,35 @@
+package libwekan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema_schemaFromMigrations(t *testing.T) {
+	ass := assert.New(t)
+	// GIVEN
+	migrations := append([]string{"board-background-color"}, requiredMigrations...)
+
+	// WHEN
+	v3, errV3 := schemaFromMigrations(migrations)
+	v6, errV6 := schemaFromMigrations(append(migrations, ostrioFilesMigration))
+	_, errMissing := schemaFromMigrations([]string{"add-swimlanes"})
+
+	// THEN
+	ass.NoError(errV3)
+	ass.Equal(SchemaV3, v3.Version)
+	ass.Equal("cfs.attachments.filerecord", v3.Mapping.AttachmentsCollection)
+	ass.NoError(errV6)
+	ass.Equal(SchemaV6, v6.Version)
+	ass.Equal("card_comments", v6.Mapping.CommentsCollection)
+	ass.Equal("meta.boardId", v6.Mapping.AttachmentsBoardField)
+	ass.IsType(UnsupportedSchemaError{}, errMissing)
+	ass.Contains(errMissing.Error(), "add-templates")
+}
+
+func TestSchema_schemaMapping_withoutDetection(t *testing.T) {
+	unitWekan := Wekan{}
+	assert.Equal(t, defaultSchemaMapping, unitWekan.schemaMapping())
+	assert.Equal(t, "card_comments", unitWekan.schemaMapping().CommentsCollection)
+}
@@ -0,0 +1,194 @@
+package libwekan
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Storage représente la couche de stockage utilisée par l'objet Wekan
+// l'implémentation par défaut s'appuie sur mongodb, voir le package libwekantest pour une implémentation en mémoire
+type Storage interface {
+	Collection(name string) Collection
+	Ping(ctx context.Context) error
+	// WithTransaction exécute fn de façon atomique lorsque le stockage le permet,
+	// le contexte transmis à fn doit être utilisé pour toutes les opérations de la transaction
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Watch ouvre un flux signalant chaque modification des collections,
+	// une erreur est retournée lorsque le serveur ne supporte pas les change streams (serveur standalone)
+	Watch(ctx context.Context, collections []string) (ChangeStream, error)
+}
+
+// Collection représente le sous-ensemble des opérations de collection mongodb utilisées par libwekan
+type Collection interface {
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) SingleResult
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Cursor, error)
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Cursor, error)
+	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	// ListIndexes retourne les index de la collection sous la forme de documents {name, key}, aucun si la collection n'existe pas
+	ListIndexes(ctx context.Context) (Cursor, error)
+	CreateIndex(ctx context.Context, model mongo.IndexModel) (string, error)
+}
+
+// SingleResult est le résultat d'une opération FindOne, l'absence de document est signalée par mongo.ErrNoDocuments
+type SingleResult interface {
+	Decode(v interface{}) error
+}
+
+// Cursor permet d'itérer sur les résultats d'une opération Find ou Aggregate
+type Cursor interface {
+	Next(ctx context.Context) bool
+	Decode(val interface{}) error
+	All(ctx context.Context, results interface{}) error
+	Close(ctx context.Context) error
+}
+
+// ChangeStream signale les modifications des collections surveillées, Next bloque jusqu'à la prochaine modification
+type ChangeStream interface {
+	Next(ctx context.Context) bool
+	Err() error
+	Close(ctx context.Context) error
+}
+
+type mongoStorage struct {
+	client       *mongo.Client
+	db           *mongo.Database
+	transactions *transactionSupport
+}
+
+// transactionSupport mémorise si le serveur accepte les transactions (replica set ou mongos)
+type transactionSupport struct {
+	mu        sync.Mutex
+	known     bool
+	supported bool
+}
+
+type mongoCollection struct {
+	collection *mongo.Collection
+}
+
+func newMongoStorage(client *mongo.Client, databaseName string) mongoStorage {
+	return mongoStorage{
+		client:       client,
+		db:           client.Database(databaseName),
+		transactions: &transactionSupport{},
+	}
+}
+
+func (storage mongoStorage) Collection(name string) Collection {
+	return mongoCollection{storage.db.Collection(name)}
+}
+
+func (storage mongoStorage) Ping(ctx context.Context) error {
+	return storage.client.Ping(ctx, nil)
+}
+
+func (storage mongoStorage) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// transaction imbriquée : la transaction englobante garantit déjà l'atomicité
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	supported, err := storage.supportsTransactions(ctx)
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	// un serveur standalone n'accepte pas les transactions, les opérations sont alors exécutées séquentiellement
+	if !supported {
+		return fn(ctx)
+	}
+	session, err := storage.client.StartSession()
+	if err != nil {
+		return UnexpectedMongoError{err}
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionContext)
+	})
+	return err
+}
+
+func (storage mongoStorage) Watch(ctx context.Context, collections []string) (ChangeStream, error) {
+	pipeline := []bson.M{{"$match": bson.M{"ns.coll": bson.M{"$in": collections}}}}
+	stream, err := storage.db.Watch(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (storage mongoStorage) supportsTransactions(ctx context.Context) (bool, error) {
+	storage.transactions.mu.Lock()
+	defer storage.transactions.mu.Unlock()
+	if storage.transactions.known {
+		return storage.transactions.supported, nil
+	}
+	var isMaster struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := storage.client.Database("admin").RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&isMaster); err != nil {
+		return false, err
+	}
+	storage.transactions.known = true
+	storage.transactions.supported = isMaster.SetName != "" || isMaster.Msg == "isdbgrid"
+	return storage.transactions.supported, nil
+}
+
+func (c mongoCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) SingleResult {
+	return c.collection.FindOne(ctx, filter, opts...)
+}
+
+func (c mongoCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (Cursor, error) {
+	cur, err := c.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (c mongoCollection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (Cursor, error) {
+	cur, err := c.collection.Aggregate(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (c mongoCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	return c.collection.InsertOne(ctx, document, opts...)
+}
+
+func (c mongoCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.collection.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (c mongoCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return c.collection.UpdateMany(ctx, filter, update, opts...)
+}
+
+func (c mongoCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return c.collection.DeleteOne(ctx, filter, opts...)
+}
+
+func (c mongoCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return c.collection.DeleteMany(ctx, filter, opts...)
+}
+
+func (c mongoCollection) ListIndexes(ctx context.Context) (Cursor, error) {
+	cur, err := c.collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (c mongoCollection) CreateIndex(ctx context.Context, model mongo.IndexModel) (string, error) {
+	return c.collection.Indexes().CreateOne(ctx, model)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type SwimlaneID string
@@ -46,20 +47,22 @@ func buildBoardTemplateSwimlane(boardId BoardID) Swimlane {
 	return BuildSwimlane(boardId, "template-container", "Board Templates", 3)
 }
 
-func (wekan *Wekan) InsertSwimlane(ctx context.Context, swimlane Swimlane) error {
+func (wekan *Wekan) InsertSwimlane(ctx context.Context, swimlane Swimlane) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertSwimlane", Targets{"swimlaneID": string(swimlane.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
 
-	if _, err := wekan.insertActivity(ctx, newActivityCreateSwimlane(wekan.adminUserID, swimlane.BoardID, swimlane.ID)); err != nil {
+	if _, err := wekan.insertActivity(ctx, newActivityCreateSwimlane(wekan.ActorID(), swimlane.BoardID, swimlane.ID)); err != nil {
 		return err
 	}
 
-	_, err := wekan.db.Collection("swimlanes").InsertOne(ctx, swimlane)
+	_, err = wekan.db.Collection("swimlanes").InsertOne(ctx, swimlane)
 	if err != nil {
 		return UnexpectedMongoError{err}
 	}
-	_, err = wekan.insertActivity(ctx, newActivityCreateSwimlane(wekan.adminUserID, swimlane.BoardID, swimlane.ID))
+	_, err = wekan.insertActivity(ctx, newActivityCreateSwimlane(wekan.ActorID(), swimlane.BoardID, swimlane.ID))
 	if err != nil {
 		return UnexpectedMongoError{err}
 	}
@@ -75,15 +78,22 @@ func (swimlaneID SwimlaneID) GetDocument(ctx context.Context, wekan *Wekan) (Swi
 	return wekan.GetSwimlaneFromID(ctx, swimlaneID)
 }
 
-func (wekan *Wekan) GetSwimlaneFromID(ctx context.Context, swimlaneID SwimlaneID) (Swimlane, error) {
+func (wekan *Wekan) GetSwimlaneFromID(ctx context.Context, swimlaneID SwimlaneID) (_ Swimlane, err error) {
+	ctx, end := wekan.observe(ctx, "GetSwimlaneFromID", Targets{"swimlaneID": string(swimlaneID)})
+	defer end(&err)
 	var swimlane Swimlane
 	if err := wekan.db.Collection("swimlanes").FindOne(ctx, bson.M{"_id": swimlaneID}).Decode(&swimlane); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Swimlane{}, SwimlaneNotFoundError{swimlaneID}
+		}
 		return Swimlane{}, UnexpectedMongoError{err}
 	}
 	return swimlane, nil
 }
 
-func (wekan *Wekan) GetSwimlanesFromBoardID(ctx context.Context, boardID BoardID) ([]Swimlane, error) {
+func (wekan *Wekan) GetSwimlanesFromBoardID(ctx context.Context, boardID BoardID) (_ []Swimlane, err error) {
+	ctx, end := wekan.observe(ctx, "GetSwimlanesFromBoardID", Targets{"boardID": string(boardID)})
+	defer end(&err)
 	var swimlanes []Swimlane
 	cur, err := wekan.db.Collection("swimlanes").Find(ctx, bson.M{"boardId": boardID})
 	if err != nil {
@@ -81,8 +81,8 @@ type UserProfile struct {
 	CardTemplatesSwimlaneId  SwimlaneID                `bson:"cardTemplatesSwimlaneId" json:"cardTemplatesSwimlaneId,omitempty"`
 	ListTemplatesSwimlaneId  SwimlaneID                `bson:"listTemplatesSwimlaneId" json:"listTemplatesSwimlaneId,omitempty"`
 	BoardTemplatesSwimlaneId SwimlaneID                `bson:"boardTemplatesSwimlaneId" json:"boardTemplatesSwimlaneId,omitempty"`
-	InvitedBoards            []string                  `bson:"invitedBoards" json:"invitedBoards,omitempty"`
-	StarredBoards            []string                  `bson:"starredBoards" json:"starredBoards,omitempty"`
+	InvitedBoards            []BoardID                 `bson:"invitedBoards" json:"invitedBoards,omitempty"`
+	StarredBoards            []BoardID                 `bson:"starredBoards" json:"starredBoards,omitempty"`
 	Language                 string                    `bson:"language" json:"language,omitempty"`
 	CardMaximized            bool                      `bson:"cardMaximized" json:"cardMaximized,omitempty"`
 	EmailBuffer              []string                  `bson:"emailBuffer" json:"emailBuffer,omitempty"`
@@ -119,7 +119,9 @@ func (user User) GetID() UserID {
 }
 
 // ListUsers returns all wekan users
-func (wekan *Wekan) ListUsers(ctx context.Context) ([]User, error) {
+func (wekan *Wekan) ListUsers(ctx context.Context) (_ []User, err error) {
+	ctx, end := wekan.observe(ctx, "ListUsers", nil)
+	defer end(&err)
 	cursor, err := wekan.db.Collection("users").Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
@@ -130,14 +132,16 @@ func (wekan *Wekan) ListUsers(ctx context.Context) ([]User, error) {
 }
 
 // GetUserFromUsername retourne l'objet utilisateur correspond au champ .username
-func (wekan *Wekan) GetUserFromUsername(ctx context.Context, username Username) (User, error) {
+func (wekan *Wekan) GetUserFromUsername(ctx context.Context, username Username) (_ User, err error) {
+	ctx, end := wekan.observe(ctx, "GetUserFromUsername", Targets{"username": string(username)})
+	defer end(&err)
 	var user User
-	err := wekan.db.Collection("users").FindOne(ctx, bson.M{
+	err = wekan.db.Collection("users").FindOne(ctx, bson.M{
 		"username": username,
 	}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return User{}, UserNotFoundError{key: string("username = " + username), err: err}
+			return User{}, UserNotFoundError{Key: string("username = " + username), err: err}
 		}
 		return User{}, UnexpectedMongoError{err}
 	}
@@ -145,14 +149,16 @@ func (wekan *Wekan) GetUserFromUsername(ctx context.Context, username Username)
 }
 
 // GetUserFromID retourne l'objet utilisateur correspond au champ ._id
-func (wekan *Wekan) GetUserFromID(ctx context.Context, id UserID) (User, error) {
+func (wekan *Wekan) GetUserFromID(ctx context.Context, id UserID) (_ User, err error) {
+	ctx, end := wekan.observe(ctx, "GetUserFromID", Targets{"userID": string(id)})
+	defer end(&err)
 	var user User
-	err := wekan.db.Collection("users").FindOne(ctx, bson.M{
+	err = wekan.db.Collection("users").FindOne(ctx, bson.M{
 		"_id": id,
 	}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return User{}, UserNotFoundError{key: string("id = " + id)}
+			return User{}, UserNotFoundError{Key: string("id = " + id)}
 		}
 		return User{}, UnexpectedMongoError{err}
 	}
@@ -160,7 +166,9 @@ func (wekan *Wekan) GetUserFromID(ctx context.Context, id UserID) (User, error)
 }
 
 // GetUsersFromUsernames retourne les objets users correspondant aux usernames en une seule requête
-func (wekan *Wekan) GetUsersFromUsernames(ctx context.Context, usernames []Username) ([]User, error) {
+func (wekan *Wekan) GetUsersFromUsernames(ctx context.Context, usernames []Username) (_ []User, err error) {
+	ctx, end := wekan.observe(ctx, "GetUsersFromUsernames", nil)
+	defer end(&err)
 	usernameSet := uniq(usernames)
 	cur, err := wekan.db.Collection("users").Find(ctx, bson.M{
 		"username": bson.M{"$in": usernameSet},
@@ -182,13 +190,15 @@ func (wekan *Wekan) GetUsersFromUsernames(ctx context.Context, usernames []Usern
 		usernameSetString := mapSlice(usernameSet, Username.String)
 		_, missing, _ := intersect(usernameSetString, selectedUsernamesString)
 		sort.Strings(missing)
-		return Users{}, UserNotFoundError{key: fmt.Sprintf("usernames in (%s)", strings.Join(missing, ", "))}
+		return Users{}, UserNotFoundError{Key: fmt.Sprintf("usernames in (%s)", strings.Join(missing, ", "))}
 	}
 	return users, nil
 }
 
 // GetUsersFromIDs retourne les objets users correspondant aux usernames en une seule requête
-func (wekan *Wekan) GetUsersFromIDs(ctx context.Context, userIDs []UserID) ([]User, error) {
+func (wekan *Wekan) GetUsersFromIDs(ctx context.Context, userIDs []UserID) (_ []User, err error) {
+	ctx, end := wekan.observe(ctx, "GetUsersFromIDs", nil)
+	defer end(&err)
 	userIDSet := uniq(userIDs)
 	if len(userIDs) <= 0 {
 		return Users{}, nil
@@ -213,13 +223,15 @@ func (wekan *Wekan) GetUsersFromIDs(ctx context.Context, userIDs []UserID) ([]Us
 		userIDSetString := mapSlice(userIDSet, UserID.String)
 		_, missing, _ := intersect(userIDSetString, selectedUsernamesString)
 		sort.Strings(missing)
-		return Users{}, UserNotFoundError{key: fmt.Sprintf("ids in (%s)", strings.Join(missing, ", "))}
+		return Users{}, UserNotFoundError{Key: fmt.Sprintf("ids in (%s)", strings.Join(missing, ", "))}
 	}
 	return users, nil
 }
 
 // GetUsers retourne tous les utilisateurs
-func (wekan *Wekan) GetUsers(ctx context.Context) (Users, error) {
+func (wekan *Wekan) GetUsers(ctx context.Context) (_ Users, err error) {
+	ctx, end := wekan.observe(ctx, "GetUsers", nil)
+	defer end(&err)
 	var users Users
 	cursor, err := wekan.db.Collection("users").Find(ctx, bson.M{})
 	if err != nil {
@@ -235,15 +247,19 @@ func (wekan *Wekan) GetUsers(ctx context.Context) (Users, error) {
 	return users, nil
 }
 
-func (wekan *Wekan) UsernameExists(ctx context.Context, username Username) (bool, error) {
-	_, err := wekan.GetUserFromUsername(ctx, username)
+func (wekan *Wekan) UsernameExists(ctx context.Context, username Username) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "UsernameExists", Targets{"username": string(username)})
+	defer end(&err)
+	_, err = wekan.GetUserFromUsername(ctx, username)
 	if _, ok := err.(UserNotFoundError); ok {
 		return false, nil
 	}
 	return err == nil, err
 }
 
-func (wekan *Wekan) InsertUser(ctx context.Context, user User) error {
+func (wekan *Wekan) InsertUser(ctx context.Context, user User) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertUser", Targets{"userID": string(user.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -252,30 +268,34 @@ func (wekan *Wekan) InsertUser(ctx context.Context, user User) error {
 	if err != nil || userAlreadyExists {
 		return UserAlreadyExistsError{user}
 	}
-	if err = wekan.InsertTemplates(ctx, user.BuildTemplates()); err != nil {
+	templates := user.BuildTemplates()
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := wekan.InsertTemplates(ctx, templates); err != nil {
+			return err
+		}
+		if _, err := wekan.db.Collection("users").InsertOne(ctx, user); err != nil {
+			return UnexpectedMongoError{err}
+		}
+		_, err := wekan.EnsureUserIsActiveBoardMember(ctx, user.Profile.TemplatesBoardId, user.ID)
 		return err
-	}
-	if _, err = wekan.db.Collection("users").InsertOne(ctx, user); err != nil {
-		return UnexpectedMongoError{err}
-	}
-	_, err = wekan.EnsureUserIsActiveBoardMember(ctx, user.Profile.TemplatesBoardId, user.ID)
-	return err
+	})
 }
 
-func (wekan *Wekan) InsertTemplates(ctx context.Context, templates UserTemplates) error {
-	if err := wekan.InsertSwimlane(ctx, templates.CardTemplateSwimlane); err != nil {
-		return err
-	}
-	if err := wekan.InsertSwimlane(ctx, templates.ListTemplateSwimlane); err != nil {
-		return err
-	}
-	if err := wekan.InsertSwimlane(ctx, templates.BoardTemplateSwimlane); err != nil {
-		return err
-	}
-	if err := wekan.InsertBoard(ctx, templates.TemplateBoard); err != nil {
-		return err
-	}
-	return nil
+func (wekan *Wekan) InsertTemplates(ctx context.Context, templates UserTemplates) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertTemplates", Targets{"boardID": string(templates.TemplateBoard.ID)})
+	defer end(&err)
+	return wekan.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := wekan.InsertSwimlane(ctx, templates.CardTemplateSwimlane); err != nil {
+			return err
+		}
+		if err := wekan.InsertSwimlane(ctx, templates.ListTemplateSwimlane); err != nil {
+			return err
+		}
+		if err := wekan.InsertSwimlane(ctx, templates.BoardTemplateSwimlane); err != nil {
+			return err
+		}
+		return wekan.InsertBoard(ctx, templates.TemplateBoard)
+	})
 }
 
 func (user *User) BuildTemplates() UserTemplates {
@@ -329,8 +349,8 @@ func BuildUser(email, initials, fullname string) User {
 			Fullname:             fullname,
 			BoardView:            "board-view-swimlanes",
 			ListSortBy:           "-modifiedAt",
-			InvitedBoards:        []string{},
-			StarredBoards:        []string{},
+			InvitedBoards:        []BoardID{},
+			StarredBoards:        []BoardID{},
 			EmailBuffer:          []string{},
 			HiddenSystemMessages: true,
 			Language:             "fr",
@@ -352,13 +372,15 @@ func (user User) Admin(admin bool) User {
 }
 
 // EnableUser active un utilisateur dans la base `users` et active la participation à son tableau templates
-func (wekan *Wekan) EnableUser(ctx context.Context, user User) error {
+func (wekan *Wekan) EnableUser(ctx context.Context, user User) (err error) {
+	ctx, end := wekan.observe(ctx, "EnableUser", Targets{"userID": string(user.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
 
 	// enable BoardMember on template board
-	_, err := wekan.db.Collection("boards").UpdateOne(ctx, bson.M{"_id": user.Profile.TemplatesBoardId},
+	_, err = wekan.db.Collection("boards").UpdateOne(ctx, bson.M{"_id": user.Profile.TemplatesBoardId},
 		bson.M{
 			"$set": bson.M{"members.$[member].isActive": true},
 		},
@@ -394,7 +416,9 @@ func (wekan *Wekan) EnableUser(ctx context.Context, user User) error {
 	return nil
 }
 
-func (wekan *Wekan) InsertUsers(ctx context.Context, users Users) error {
+func (wekan *Wekan) InsertUsers(ctx context.Context, users Users) (err error) {
+	ctx, end := wekan.observe(ctx, "InsertUsers", nil)
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -408,7 +432,9 @@ func (wekan *Wekan) InsertUsers(ctx context.Context, users Users) error {
 	return nil
 }
 
-func (wekan *Wekan) EnableUsers(ctx context.Context, users Users) error {
+func (wekan *Wekan) EnableUsers(ctx context.Context, users Users) (err error) {
+	ctx, end := wekan.observe(ctx, "EnableUsers", nil)
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -423,7 +449,9 @@ func (wekan *Wekan) EnableUsers(ctx context.Context, users Users) error {
 }
 
 // DisableUser désactive l'utilisateur dans la base `users` et désactive la participation à tous les tableaux
-func (wekan *Wekan) DisableUser(ctx context.Context, user User) error {
+func (wekan *Wekan) DisableUser(ctx context.Context, user User) (err error) {
+	ctx, end := wekan.observe(ctx, "DisableUser", Targets{"userID": string(user.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -456,7 +484,9 @@ func (wekan *Wekan) DisableUser(ctx context.Context, user User) error {
 	return nil
 }
 
-func (wekan *Wekan) DisableUsers(ctx context.Context, users Users) error {
+func (wekan *Wekan) DisableUsers(ctx context.Context, users Users) (err error) {
+	ctx, end := wekan.observe(ctx, "DisableUsers", nil)
+	defer end(&err)
 	for _, user := range users {
 		err := wekan.DisableUser(ctx, user)
 		if err != nil {
@@ -466,11 +496,15 @@ func (wekan *Wekan) DisableUsers(ctx context.Context, users Users) error {
 	return nil
 }
 
-func (wekan *Wekan) RemoveSelfMemberFromCard(ctx context.Context, card Card, member User) error {
+func (wekan *Wekan) RemoveSelfMemberFromCard(ctx context.Context, card Card, member User) (err error) {
+	ctx, end := wekan.observe(ctx, "RemoveSelfMemberFromCard", Targets{"cardID": string(card.ID), "memberID": string(member.ID)})
+	defer end(&err)
 	return wekan.RemoveMemberFromCard(ctx, card, member, member)
 }
 
-func (wekan *Wekan) RemoveMemberFromCard(ctx context.Context, card Card, user User, member User) error {
+func (wekan *Wekan) RemoveMemberFromCard(ctx context.Context, card Card, user User, member User) (err error) {
+	ctx, end := wekan.observe(ctx, "RemoveMemberFromCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "memberID": string(member.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -501,7 +535,9 @@ func (wekan *Wekan) RemoveMemberFromCard(ctx context.Context, card Card, user Us
 	return nil
 }
 
-func (wekan *Wekan) RemoveAssigneeFromCard(ctx context.Context, card Card, user User, assignee User) error {
+func (wekan *Wekan) RemoveAssigneeFromCard(ctx context.Context, card Card, user User, assignee User) (err error) {
+	ctx, end := wekan.observe(ctx, "RemoveAssigneeFromCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "assigneeID": string(assignee.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -533,7 +569,15 @@ func (wekan *Wekan) RemoveAssigneeFromCard(ctx context.Context, card Card, user
 	return nil
 }
 
-func (wekan *Wekan) EnsureAssigneeOutOfCard(ctx context.Context, card Card, user User, assignee User) (bool, error) {
+func (wekan *Wekan) EnsureAssigneeOutOfCard(ctx context.Context, card Card, user User, assignee User) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureAssigneeOutOfCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "assigneeID": string(assignee.ID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureAssigneeOutOfCard(ctx, card, user, assignee)
+	})
+}
+
+func (wekan *Wekan) ensureAssigneeOutOfCard(ctx context.Context, card Card, user User, assignee User) (bool, error) {
 	err := wekan.RemoveAssigneeFromCard(ctx, card, user, assignee)
 	if _, ok := err.(NothingDoneError); ok {
 		return false, nil
@@ -541,7 +585,15 @@ func (wekan *Wekan) EnsureAssigneeOutOfCard(ctx context.Context, card Card, user
 	return err == nil, err
 }
 
-func (wekan *Wekan) EnsureMemberOutOfCard(ctx context.Context, card Card, user User, member User) (bool, error) {
+func (wekan *Wekan) EnsureMemberOutOfCard(ctx context.Context, card Card, user User, member User) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureMemberOutOfCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "memberID": string(member.ID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureMemberOutOfCard(ctx, card, user, member)
+	})
+}
+
+func (wekan *Wekan) ensureMemberOutOfCard(ctx context.Context, card Card, user User, member User) (bool, error) {
 	err := wekan.RemoveMemberFromCard(ctx, card, user, member)
 	if _, ok := err.(NothingDoneError); ok {
 		return false, nil
@@ -549,11 +601,15 @@ func (wekan *Wekan) EnsureMemberOutOfCard(ctx context.Context, card Card, user U
 	return err == nil, err
 }
 
-func (wekan *Wekan) AddSelfMemberToCard(ctx context.Context, card Card, member User) error {
+func (wekan *Wekan) AddSelfMemberToCard(ctx context.Context, card Card, member User) (err error) {
+	ctx, end := wekan.observe(ctx, "AddSelfMemberToCard", Targets{"cardID": string(card.ID), "memberID": string(member.ID)})
+	defer end(&err)
 	return wekan.AddMemberToCard(ctx, card, member, member)
 }
 
-func (wekan *Wekan) AddAssigneeToCard(ctx context.Context, card Card, user User, assignee User) error {
+func (wekan *Wekan) AddAssigneeToCard(ctx context.Context, card Card, user User, assignee User) (err error) {
+	ctx, end := wekan.observe(ctx, "AddAssigneeToCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "assigneeID": string(assignee.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -609,7 +665,9 @@ func (wekan *Wekan) AddAssigneeToCard(ctx context.Context, card Card, user User,
 	return nil
 }
 
-func (wekan *Wekan) AddMemberToCard(ctx context.Context, card Card, user User, member User) error {
+func (wekan *Wekan) AddMemberToCard(ctx context.Context, card Card, user User, member User) (err error) {
+	ctx, end := wekan.observe(ctx, "AddMemberToCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "memberID": string(member.ID)})
+	defer end(&err)
 	if err := wekan.AssertPrivileged(ctx); err != nil {
 		return err
 	}
@@ -654,7 +712,15 @@ func (wekan *Wekan) AddMemberToCard(ctx context.Context, card Card, user User, m
 	return nil
 }
 
-func (wekan *Wekan) EnsureMemberInCard(ctx context.Context, card Card, user User, member User) (bool, error) {
+func (wekan *Wekan) EnsureMemberInCard(ctx context.Context, card Card, user User, member User) (_ bool, err error) {
+	ctx, end := wekan.observe(ctx, "EnsureMemberInCard", Targets{"cardID": string(card.ID), "userID": string(user.ID), "memberID": string(member.ID)})
+	defer end(&err)
+	return retryEnsure(ctx, wekan, func(ctx context.Context) (bool, error) {
+		return wekan.ensureMemberInCard(ctx, card, user, member)
+	})
+}
+
+func (wekan *Wekan) ensureMemberInCard(ctx context.Context, card Card, user User, member User) (bool, error) {
 	err := wekan.AddMemberToCard(ctx, card, user, member)
 	if _, ok := err.(NothingDoneError); ok {
 		return false, nil